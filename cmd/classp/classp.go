@@ -116,12 +116,12 @@ func isClassComment(s string) bool {
 	return strings.Index(s, "class") != -1 || strings.Index(s, "super") != -1
 }
 
-func fnSigStr(fileimps map[string]string, expr ast.Expr) string {
+func fnSigStr(fileimps map[string]string, pkgpath string, expr ast.Expr) string {
 	switch p := expr.(type) {
 	// case nil:
 	// 	return ""
 	case *ast.StarExpr:
-		return "*" + fnSigStr(fileimps, p.X)
+		return "*" + fnSigStr(fileimps, pkgpath, p.X)
 	case *ast.SelectorExpr:
 		if ident, ok := p.X.(*ast.Ident); ok {
 			ppath, ok := fileimps[ident.Name]
@@ -129,22 +129,29 @@ func fnSigStr(fileimps map[string]string, expr ast.Expr) string {
 				return fullName(ppath, p.Sel.Name)
 			}
 		}
-		return fnSigStr(fileimps, p.X) + "." + p.Sel.Name
+		return fnSigStr(fileimps, pkgpath, p.X) + "." + p.Sel.Name
 	case *ast.Ident:
+		if unicode.IsUpper(rune(p.Name[0])) {
+			// exported type of the package itself, qualify it so overrides
+			// in other packages have the same signature.
+			return fullName(pkgpath, p.Name)
+		}
 		return p.Name
 	case *ast.ArrayType:
 		if p.Len == nil {
-			return fmt.Sprintf("[]%s", fnSigStr(fileimps, p.Elt))
+			return fmt.Sprintf("[]%s", fnSigStr(fileimps, pkgpath, p.Elt))
 		}
-		return fmt.Sprintf("[%s]%s", p.Len, fnSigStr(fileimps, p.Elt))
+		return fmt.Sprintf("[%s]%s", p.Len, fnSigStr(fileimps, pkgpath, p.Elt))
 	case *ast.InterfaceType:
 		// orders matter
 		var list []string
 		for _, m := range p.Methods.List {
-			list = append(list, m.Names[0].Name+fnSigStr(fileimps, m.Type))
+			list = append(list, m.Names[0].Name+fnSigStr(fileimps, pkgpath, m.Type))
 		}
 		sort.Strings(list)
 		return "interface{" + strings.Join(list, ";") + "}"
+	case *ast.Ellipsis:
+		return "..." + fnSigStr(fileimps, pkgpath, p.Elt)
 	case *ast.FuncType:
 		s := "("
 		for i, arg := range p.Params.List {
@@ -155,7 +162,7 @@ func fnSigStr(fileimps map[string]string, expr ast.Expr) string {
 				if j > 0 {
 					s += ", "
 				}
-				s += fnSigStr(fileimps, arg.Type)
+				s += fnSigStr(fileimps, pkgpath, arg.Type)
 			}
 		}
 		s += ")"
@@ -163,7 +170,7 @@ func fnSigStr(fileimps map[string]string, expr ast.Expr) string {
 			// no return
 		} else if p.Results.NumFields() == 1 {
 			ret := p.Results.List[0]
-			s += fnSigStr(fileimps, ret.Type)
+			s += fnSigStr(fileimps, pkgpath, ret.Type)
 		} else if p.Results.NumFields() > 1 {
 			s += "("
 			for i, ret := range p.Results.List {
@@ -174,7 +181,7 @@ func fnSigStr(fileimps map[string]string, expr ast.Expr) string {
 					if j > 0 {
 						s += ", "
 					}
-					s += fnSigStr(fileimps, ret.Type)
+					s += fnSigStr(fileimps, pkgpath, ret.Type)
 				}
 			}
 			s += ")"
@@ -215,6 +222,8 @@ func fnOutStr(fileimps map[string]string, expr ast.Expr) string {
 		}
 		sort.Strings(list)
 		return "interface{" + strings.Join(list, ";") + "}"
+	case *ast.Ellipsis:
+		return "..." + fnOutStr(fileimps, p.Elt)
 	case *ast.FuncType:
 		s := "("
 		for i, arg := range p.Params.List {
@@ -230,7 +239,7 @@ func fnOutStr(fileimps map[string]string, expr ast.Expr) string {
 			if len(arg.Names) > 0 {
 				s += " "
 			}
-			s += fnOutStr(fileimps, arg.Type)
+			s += fnTypeOutStr(fileimps, arg.Type)
 		}
 		s += ")"
 		//fmt.Printf("%#v\n", p)
@@ -238,7 +247,7 @@ func fnOutStr(fileimps map[string]string, expr ast.Expr) string {
 			// no return
 		} else if p.Results.NumFields() == 1 {
 			ret := p.Results.List[0]
-			s += " " + fnOutStr(fileimps, ret.Type)
+			s += " " + fnTypeOutStr(fileimps, ret.Type)
 		} else if p.Results.NumFields() > 1 {
 			s += " ("
 			for i, ret := range p.Results.List {
@@ -254,7 +263,7 @@ func fnOutStr(fileimps map[string]string, expr ast.Expr) string {
 				if len(ret.Names) > 0 {
 					s += " "
 				}
-				s += fnOutStr(fileimps, ret.Type)
+				s += fnTypeOutStr(fileimps, ret.Type)
 			}
 			s += ")"
 		}
@@ -264,6 +273,14 @@ func fnOutStr(fileimps map[string]string, expr ast.Expr) string {
 	}
 }
 
+// fnTypeOutStr is fnOutStr for type of param or result, func type needs the keyword
+func fnTypeOutStr(fileimps map[string]string, expr ast.Expr) string {
+	if _, ok := expr.(*ast.FuncType); ok {
+		return "func" + fnOutStr(fileimps, expr)
+	}
+	return fnOutStr(fileimps, expr)
+}
+
 func dirToPkg(dir string) string {
 	s, err := filepath.Rel(gopath+"/src", dir)
 	if err != nil {
@@ -334,7 +351,11 @@ func loadPkg(dir string) (pkgname string) {
 		log.Println("parse", pkgpath)
 	}
 	fset := new(token.FileSet)
-	pm, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	// types declared in tests are not classes of the package
+	noTest := func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}
+	pm, err := parser.ParseDir(fset, dir, noTest, parser.ParseComments)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -517,7 +538,7 @@ func loadPkg(dir string) (pkgname string) {
 				if fdecl.Doc != nil {
 					m.doc = strings.TrimSpace(fdecl.Doc.Text())
 				}
-				m.sig = fnSigStr(fileimps, fdecl.Type)
+				m.sig = fnSigStr(fileimps, pkgpath, fdecl.Type)
 				m.out = fnOutStr(fileimps, fdecl.Type)
 				// if fdecl.Name.Name == "Size" {
 				// 	fmt.Printf("%s\n", m.sig)
@@ -16,10 +16,10 @@ func main() {
 		w1.SetObjID("window1")
 		w1.SetHints(gui.HintResizable)
 		w1.Create(0, 0)
+		w1.SetMinSize(320, 240)
 		w1.SetTitle("w1 resizable")
 		w1.Show()
 		// w1.ToggleFullScreen()
-		w1.Destroy()
 		w2 := NewWindow()
 		w2.SetObjID("window2")
 		w2.Create(200, 200)
@@ -4,7 +4,7 @@
 // darwin: CGL
 // linux freebsd: GLX
 // Use of EGL instead of the platform's default (listed above) is made possible
-// via the "egl" build tag.
+// via the "egl" build tag, it is also used by "headless" build on linux.
 // It is also possible to install your own function outside this package for
 // retrieving OpenGL function pointers, to do this see InitWithProcAddrFunc.
 package gl
@@ -16,8 +16,8 @@ package gl
 #cgo darwin LDFLAGS: -framework OpenGL
 #cgo linux freebsd CFLAGS: -DTAG_POSIX
 #cgo linux freebsd LDFLAGS: -lGL
-#cgo egl CFLAGS: -DTAG_EGL
-#cgo egl LDFLAGS: -lEGL
+#cgo egl linux,headless CFLAGS: -DTAG_EGL
+#cgo egl linux,headless LDFLAGS: -lEGL
 // Check the EGL tag first as it takes priority over the platform's default
 // configuration of WGL/GLX/CGL.
 #if defined(TAG_EGL)
@@ -0,0 +1,43 @@
+package winl
+
+// #include <stdlib.h>
+// #include "winl-c.h"
+import "C"
+
+import "unsafe"
+
+// SetClipboardText put text to system clipboard
+func SetClipboardText(text string) {
+	setClipboardText(0, text)
+}
+
+// ClipboardText returns text in system clipboard, empty if none
+func ClipboardText() string {
+	return clipboardText(0)
+}
+
+// SetPrimarySelection set the X11 PRIMARY selection, which is pasted by middle button.
+// on other systems it is kept inside the process.
+func SetPrimarySelection(text string) {
+	setClipboardText(1, text)
+}
+
+// PrimarySelection returns text of the X11 PRIMARY selection
+func PrimarySelection() string {
+	return clipboardText(1)
+}
+
+func setClipboardText(primary C.int, text string) {
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.winl_set_clipboard_text(primary, ctext)
+}
+
+func clipboardText(primary C.int) string {
+	ctext := C.winl_get_clipboard_text(primary)
+	if ctext == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(ctext))
+	return C.GoString(ctext)
+}
@@ -0,0 +1,60 @@
+package winl
+
+// #include "winl-c.h"
+import "C"
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+)
+
+// Cursor is shape of mouse cursor
+type Cursor int
+
+// Standard cursors
+const (
+	CursorArrow     Cursor = C.WINL_CURSOR_ARROW
+	CursorIBeam     Cursor = C.WINL_CURSOR_IBEAM
+	CursorHResize   Cursor = C.WINL_CURSOR_HRESIZE
+	CursorVResize   Cursor = C.WINL_CURSOR_VRESIZE
+	CursorHand      Cursor = C.WINL_CURSOR_HAND
+	CursorCrosshair Cursor = C.WINL_CURSOR_CROSSHAIR
+	CursorHidden    Cursor = C.WINL_CURSOR_HIDDEN
+
+	cursorCustom Cursor = 1000 // first id of custom cursor
+)
+
+// ErrCreateCursor is returned when system failed to create custom cursor
+var ErrCreateCursor = errors.New("Failed to create cursor")
+
+var (
+	customCursors = make(map[Cursor]C.WinlCursor) // opaque handles, not pointers to Go or C memory
+	nextCursor    = cursorCustom
+)
+
+// NewCursor create custom cursor from image, (hotX, hotY) is the click point relative to top-left.
+func NewCursor(img image.Image, hotX, hotY int) (Cursor, error) {
+	b := img.Bounds()
+	if b.Empty() {
+		return CursorArrow, ErrCreateCursor
+	}
+	rgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	p := C.winl_create_cursor((*C.uchar)(&rgba.Pix[0]), C.int(b.Dx()), C.int(b.Dy()), C.int(hotX), C.int(hotY))
+	if p == 0 {
+		return CursorArrow, ErrCreateCursor
+	}
+	c := nextCursor
+	nextCursor++
+	customCursors[c] = p
+	return c, nil
+}
+
+// Destroy release custom cursor, windows using it should set another cursor before.
+func (c Cursor) Destroy() {
+	if p, ok := customCursors[c]; ok {
+		C.winl_destroy_cursor(p)
+		delete(customCursors, c)
+	}
+}
@@ -0,0 +1,36 @@
+//go:build linux && headless
+// +build linux,headless
+
+package winl
+
+// #include "winl-c.h"
+// void winl_headless_button(NativeWnd win, unsigned int button, int press, float x, float y);
+// void winl_headless_text_input_rect(NativeWnd win, float* rect);
+// int winl_headless_grabbed(NativeWnd win);
+import "C"
+
+// Headless is true if windows are offscreen pbuffers, selected by build tag "headless" on linux.
+// there is no display and no user input, it is used to run tests on CI machines.
+const Headless = true
+
+// InjectNativeButton sends X11 button event to w through the native backend, as if it is from display server.
+// button is X11 button number, 1 ~ 3 for left, middle and right, 4 ~ 7 for wheel up, down, left and right.
+func InjectNativeButton(w *Window, button int, press bool, x, y float32) {
+	p := 0
+	if press {
+		p = 1
+	}
+	C.winl_headless_button(w.native, C.uint(button), C.int(p), C.float(x), C.float(y))
+}
+
+// TextInputRect reports the rect set by SetTextInputRect, where candidate window of input method is placed
+func TextInputRect(w *Window) (x, y, width, height float32) {
+	var rc [4]C.float
+	C.winl_headless_text_input_rect(w.native, &rc[0])
+	return float32(rc[0]), float32(rc[1]), float32(rc[2]), float32(rc[3])
+}
+
+// MouseGrabbed reports whether mouse is grabbed by GrabMouse
+func MouseGrabbed(w *Window) bool {
+	return C.winl_headless_grabbed(w.native) != 0
+}
@@ -0,0 +1,7 @@
+//go:build !linux || !headless
+// +build !linux !headless
+
+package winl
+
+// Headless is true if windows are offscreen pbuffers, selected by build tag "headless" on linux.
+const Headless = false
@@ -0,0 +1,43 @@
+package winl
+
+// #include "winl-c.h"
+import "C"
+
+import (
+	"image"
+	"image/draw"
+)
+
+// SetIcon set icon of the window, pass images of different sizes to let the system choose
+func (w *Window) SetIcon(images ...image.Image) {
+	var sizes []C.int
+	var pix []byte
+	for _, img := range images {
+		b := img.Bounds()
+		if b.Empty() {
+			continue
+		}
+		rgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+		sizes = append(sizes, C.int(b.Dx()), C.int(b.Dy()))
+		pix = append(pix, rgba.Pix...)
+	}
+	if len(sizes) == 0 {
+		C.winl_set_icon(w.native, 0, nil, nil)
+		return
+	}
+	C.winl_set_icon(w.native, C.int(len(sizes)/2), &sizes[0], (*C.uchar)(&pix[0]))
+}
+
+// RequestAttention flashes taskbar entry or bounces dock icon until the window is focused
+func (w *Window) RequestAttention() {
+	C.winl_request_attention(w.native)
+}
+
+// SetProgress shows progress of long task in taskbar or dock, fraction in [0, 1], negative to hide, it's a no-op on X11
+func (w *Window) SetProgress(fraction float32) {
+	if fraction > 1 {
+		fraction = 1
+	}
+	C.winl_set_progress(w.native, C.float(fraction))
+}
@@ -0,0 +1,234 @@
+package winl
+
+// #include "winl-c.h"
+import "C"
+
+import "fmt"
+
+// Key is portable key code, same value on all operating systems.
+// printable keys use the ASCII value of the unshifted character, i.e. KeyA is 'A'.
+type Key int
+
+// Key codes
+const (
+	KeyUnknown Key = C.WINL_KEY_UNKNOWN
+
+	KeySpace        Key = C.WINL_KEY_SPACE
+	KeyApostrophe   Key = C.WINL_KEY_APOSTROPHE
+	KeyComma        Key = C.WINL_KEY_COMMA
+	KeyMinus        Key = C.WINL_KEY_MINUS
+	KeyPeriod       Key = C.WINL_KEY_PERIOD
+	KeySlash        Key = C.WINL_KEY_SLASH
+	Key0            Key = C.WINL_KEY_0
+	Key1            Key = Key0 + 1
+	Key2            Key = Key0 + 2
+	Key3            Key = Key0 + 3
+	Key4            Key = Key0 + 4
+	Key5            Key = Key0 + 5
+	Key6            Key = Key0 + 6
+	Key7            Key = Key0 + 7
+	Key8            Key = Key0 + 8
+	Key9            Key = C.WINL_KEY_9
+	KeySemicolon    Key = C.WINL_KEY_SEMICOLON
+	KeyEqual        Key = C.WINL_KEY_EQUAL
+	KeyA            Key = C.WINL_KEY_A
+	KeyB            Key = KeyA + 1
+	KeyC            Key = KeyA + 2
+	KeyD            Key = KeyA + 3
+	KeyE            Key = KeyA + 4
+	KeyF            Key = KeyA + 5
+	KeyG            Key = KeyA + 6
+	KeyH            Key = KeyA + 7
+	KeyI            Key = KeyA + 8
+	KeyJ            Key = KeyA + 9
+	KeyK            Key = KeyA + 10
+	KeyL            Key = KeyA + 11
+	KeyM            Key = KeyA + 12
+	KeyN            Key = KeyA + 13
+	KeyO            Key = KeyA + 14
+	KeyP            Key = KeyA + 15
+	KeyQ            Key = KeyA + 16
+	KeyR            Key = KeyA + 17
+	KeyS            Key = KeyA + 18
+	KeyT            Key = KeyA + 19
+	KeyU            Key = KeyA + 20
+	KeyV            Key = KeyA + 21
+	KeyW            Key = KeyA + 22
+	KeyX            Key = KeyA + 23
+	KeyY            Key = KeyA + 24
+	KeyZ            Key = C.WINL_KEY_Z
+	KeyLeftBracket  Key = C.WINL_KEY_LEFT_BRACKET
+	KeyBackslash    Key = C.WINL_KEY_BACKSLASH
+	KeyRightBracket Key = C.WINL_KEY_RIGHT_BRACKET
+	KeyGrave        Key = C.WINL_KEY_GRAVE
+
+	KeyEscape      Key = C.WINL_KEY_ESCAPE
+	KeyEnter       Key = C.WINL_KEY_ENTER
+	KeyTab         Key = C.WINL_KEY_TAB
+	KeyBackspace   Key = C.WINL_KEY_BACKSPACE
+	KeyInsert      Key = C.WINL_KEY_INSERT
+	KeyDelete      Key = C.WINL_KEY_DELETE
+	KeyRight       Key = C.WINL_KEY_RIGHT
+	KeyLeft        Key = C.WINL_KEY_LEFT
+	KeyDown        Key = C.WINL_KEY_DOWN
+	KeyUp          Key = C.WINL_KEY_UP
+	KeyPageUp      Key = C.WINL_KEY_PAGE_UP
+	KeyPageDown    Key = C.WINL_KEY_PAGE_DOWN
+	KeyHome        Key = C.WINL_KEY_HOME
+	KeyEnd         Key = C.WINL_KEY_END
+	KeyCapsLock    Key = C.WINL_KEY_CAPS_LOCK
+	KeyScrollLock  Key = C.WINL_KEY_SCROLL_LOCK
+	KeyNumLock     Key = C.WINL_KEY_NUM_LOCK
+	KeyPrintScreen Key = C.WINL_KEY_PRINT_SCREEN
+	KeyPause       Key = C.WINL_KEY_PAUSE
+	KeyMenu        Key = C.WINL_KEY_MENU
+
+	KeyF1  Key = C.WINL_KEY_F1
+	KeyF2  Key = KeyF1 + 1
+	KeyF3  Key = KeyF1 + 2
+	KeyF4  Key = KeyF1 + 3
+	KeyF5  Key = KeyF1 + 4
+	KeyF6  Key = KeyF1 + 5
+	KeyF7  Key = KeyF1 + 6
+	KeyF8  Key = KeyF1 + 7
+	KeyF9  Key = KeyF1 + 8
+	KeyF10 Key = KeyF1 + 9
+	KeyF11 Key = KeyF1 + 10
+	KeyF12 Key = C.WINL_KEY_F12
+
+	KeyKP0        Key = C.WINL_KEY_KP_0
+	KeyKP1        Key = KeyKP0 + 1
+	KeyKP2        Key = KeyKP0 + 2
+	KeyKP3        Key = KeyKP0 + 3
+	KeyKP4        Key = KeyKP0 + 4
+	KeyKP5        Key = KeyKP0 + 5
+	KeyKP6        Key = KeyKP0 + 6
+	KeyKP7        Key = KeyKP0 + 7
+	KeyKP8        Key = KeyKP0 + 8
+	KeyKP9        Key = C.WINL_KEY_KP_9
+	KeyKPDecimal  Key = C.WINL_KEY_KP_DECIMAL
+	KeyKPDivide   Key = C.WINL_KEY_KP_DIVIDE
+	KeyKPMultiply Key = C.WINL_KEY_KP_MULTIPLY
+	KeyKPSubtract Key = C.WINL_KEY_KP_SUBTRACT
+	KeyKPAdd      Key = C.WINL_KEY_KP_ADD
+	KeyKPEnter    Key = C.WINL_KEY_KP_ENTER
+	KeyKPEqual    Key = C.WINL_KEY_KP_EQUAL
+
+	KeyLeftShift    Key = C.WINL_KEY_LEFT_SHIFT
+	KeyLeftControl  Key = C.WINL_KEY_LEFT_CONTROL
+	KeyLeftAlt      Key = C.WINL_KEY_LEFT_ALT
+	KeyLeftSuper    Key = C.WINL_KEY_LEFT_SUPER
+	KeyRightShift   Key = C.WINL_KEY_RIGHT_SHIFT
+	KeyRightControl Key = C.WINL_KEY_RIGHT_CONTROL
+	KeyRightAlt     Key = C.WINL_KEY_RIGHT_ALT
+	KeyRightSuper   Key = C.WINL_KEY_RIGHT_SUPER
+)
+
+var keyNames = map[Key]string{
+	KeyUnknown:      "Unknown",
+	KeySpace:        "Space",
+	KeyApostrophe:   "'",
+	KeyComma:        ",",
+	KeyMinus:        "-",
+	KeyPeriod:       ".",
+	KeySlash:        "/",
+	KeySemicolon:    ";",
+	KeyEqual:        "=",
+	KeyLeftBracket:  "[",
+	KeyBackslash:    "\\",
+	KeyRightBracket: "]",
+	KeyGrave:        "`",
+	KeyEscape:       "Escape",
+	KeyEnter:        "Enter",
+	KeyTab:          "Tab",
+	KeyBackspace:    "Backspace",
+	KeyInsert:       "Insert",
+	KeyDelete:       "Delete",
+	KeyRight:        "Right",
+	KeyLeft:         "Left",
+	KeyDown:         "Down",
+	KeyUp:           "Up",
+	KeyPageUp:       "PageUp",
+	KeyPageDown:     "PageDown",
+	KeyHome:         "Home",
+	KeyEnd:          "End",
+	KeyCapsLock:     "CapsLock",
+	KeyScrollLock:   "ScrollLock",
+	KeyNumLock:      "NumLock",
+	KeyPrintScreen:  "PrintScreen",
+	KeyPause:        "Pause",
+	KeyMenu:         "Menu",
+	KeyKPDecimal:    "KP.",
+	KeyKPDivide:     "KP/",
+	KeyKPMultiply:   "KP*",
+	KeyKPSubtract:   "KP-",
+	KeyKPAdd:        "KP+",
+	KeyKPEnter:      "KPEnter",
+	KeyKPEqual:      "KP=",
+	KeyLeftShift:    "LeftShift",
+	KeyLeftControl:  "LeftControl",
+	KeyLeftAlt:      "LeftAlt",
+	KeyLeftSuper:    "LeftSuper",
+	KeyRightShift:   "RightShift",
+	KeyRightControl: "RightControl",
+	KeyRightAlt:     "RightAlt",
+	KeyRightSuper:   "RightSuper",
+}
+
+// String returns name of the key, i.e. "A", "F5", "PageUp"
+func (k Key) String() string {
+	switch {
+	case k >= Key0 && k <= Key9, k >= KeyA && k <= KeyZ:
+		return string(rune(k))
+	case k >= KeyF1 && k <= KeyF12:
+		return fmt.Sprintf("F%d", k-KeyF1+1)
+	case k >= KeyKP0 && k <= KeyKP9:
+		return fmt.Sprintf("KP%d", k-KeyKP0)
+	}
+	if s, ok := keyNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Key(%d)", int(k))
+}
+
+// Mod is flags of modifier keys
+type Mod int
+
+// Modifier key flags
+const (
+	ModShift    Mod = C.WINL_MOD_SHIFT
+	ModControl  Mod = C.WINL_MOD_CONTROL
+	ModAlt      Mod = C.WINL_MOD_ALT
+	ModSuper    Mod = C.WINL_MOD_SUPER
+	ModCapsLock Mod = C.WINL_MOD_CAPS_LOCK
+	ModNumLock  Mod = C.WINL_MOD_NUM_LOCK
+)
+
+// Has reports whether all of the flags in x are set
+func (m Mod) Has(x Mod) bool {
+	return m&x == x
+}
+
+// String returns the modifiers joined by '+', i.e. "Ctrl+Shift"
+func (m Mod) String() (s string) {
+	names := [...]struct {
+		m Mod
+		s string
+	}{
+		{ModControl, "Ctrl"},
+		{ModAlt, "Alt"},
+		{ModShift, "Shift"},
+		{ModSuper, "Super"},
+		{ModCapsLock, "CapsLock"},
+		{ModNumLock, "NumLock"},
+	}
+	for _, x := range names {
+		if m&x.m != 0 {
+			if s != "" {
+				s += "+"
+			}
+			s += x.s
+		}
+	}
+	return
+}
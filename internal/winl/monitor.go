@@ -0,0 +1,36 @@
+package winl
+
+// #include "winl-c.h"
+import "C"
+
+const maxMonitors = 32
+
+// Monitor describes a display device
+type Monitor struct {
+	X, Y          int     // position in virtual screen
+	Width, Height int     // size in same unit as window
+	WidthMM       int     // physical width in millimeters, 0 if unknown
+	HeightMM      int     // physical height in millimeters, 0 if unknown
+	Scale         float32 // content scale, 1 for 96 DPI
+	Primary       bool
+}
+
+// Monitors returns all monitors connected
+func Monitors() (ret []Monitor) {
+	var ms [maxMonitors]C.WinlMonitor
+	n := int(C.winl_get_monitors(&ms[0], maxMonitors))
+	for i := 0; i < n; i++ {
+		m := &ms[i]
+		ret = append(ret, Monitor{
+			X:        int(m.x),
+			Y:        int(m.y),
+			Width:    int(m.width),
+			Height:   int(m.height),
+			WidthMM:  int(m.widthMM),
+			HeightMM: int(m.heightMM),
+			Scale:    float32(m.scale),
+			Primary:  m.primary != 0,
+		})
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package winl
+
+// #include "winl-c.h"
+import "C"
+
+import "sync"
+
+var (
+	postMutex sync.Mutex
+	posted    []func()
+)
+
+// IsUIThread reports whether current goroutine is running on UI thread (the event loop)
+func IsUIThread() bool {
+	return C.winl_is_ui_thread() != 0
+}
+
+// Post schedules f to run on UI thread, it can be called from any goroutine.
+func Post(f func()) {
+	postMutex.Lock()
+	posted = append(posted, f)
+	postMutex.Unlock()
+	C.winl_wakeup()
+}
+
+// Call runs f on UI thread and waits for it returns, it can be called from any goroutine.
+// f is called directly if it is already on UI thread.
+// it blocks forever if the event loop is not running.
+func Call(f func()) {
+	if IsUIThread() {
+		f()
+		return
+	}
+	done := make(chan struct{})
+	Post(func() {
+		defer close(done)
+		f()
+	})
+	<-done
+}
+
+// runPosted calls functions posted before, in order
+func runPosted() {
+	postMutex.Lock()
+	fs := posted
+	posted = nil
+	postMutex.Unlock()
+	for _, f := range fs {
+		f()
+	}
+}
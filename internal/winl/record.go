@@ -0,0 +1,237 @@
+package winl
+
+import (
+	"strings"
+	"time"
+
+	"tetra/lib/store"
+)
+
+// kinds of Event
+const (
+	EventMouseMove    = "mouse-move"
+	EventMousePress   = "mouse-press"
+	EventMouseRelease = "mouse-release"
+	EventMouseWheel   = "mouse-wheel"
+	EventKeyPress     = "key-press"
+	EventKeyRelease   = "key-release"
+	EventTextInput    = "text"
+	EventResize       = "resize"
+	EventMouseDelta   = "mouse-delta"
+	EventMouseEnter   = "mouse-enter"
+	EventMouseLeave   = "mouse-leave"
+	EventFocus        = "focus"
+	EventState        = "state"
+	EventMove         = "move"
+	EventScale        = "scale"
+	EventExpose       = "expose"
+	EventDragEnter    = "drag-enter"
+	EventDragOver     = "drag-over"
+	EventDragLeave    = "drag-leave"
+	EventDrop         = "drop"
+)
+
+// Event is an input event, from operating system, injected or recorded
+type Event struct {
+	T      float64 `json:"t"` // seconds since recording started
+	Kind   string  `json:"kind"`
+	X      float32 `json:"x,omitempty"`      // position of mouse or window, motion for EventMouseDelta, width for EventResize
+	Y      float32 `json:"y,omitempty"`      // position of mouse or window, motion for EventMouseDelta, height for EventResize
+	Width  float32 `json:"width,omitempty"`  // size of exposed area
+	Height float32 `json:"height,omitempty"` // size of exposed area
+	Btn    int     `json:"btn,omitempty"`
+	Vert   bool    `json:"vert,omitempty"`  // vertical wheel
+	Delta  float32 `json:"delta,omitempty"` // notches of wheel, positive for up or left, or scale for EventScale
+	Key    Key     `json:"key,omitempty"`
+	Mods   Mod     `json:"mods,omitempty"`
+	Repeat bool    `json:"repeat,omitempty"`
+	Text   string  `json:"text,omitempty"`  // input text, or dropped paths separated by '\n'
+	Focus  bool    `json:"focus,omitempty"` // window becomes active for EventFocus
+	State  int     `json:"state,omitempty"` // normal, minimized or maximized, see winl-c.h
+}
+
+// Recording is the event stream of a window session
+type Recording struct {
+	Width  float32 `json:"width"` // size of window when recording started
+	Height float32 `json:"height"`
+	Events []Event `json:"events"`
+}
+
+// Recorder records events dispatched to a window
+type Recorder struct {
+	w     *Window
+	start time.Time
+	rec   Recording
+}
+
+// dispatch deliver event to handlers, events from OS and injected events are the same.
+// it returns the result of OnDragOver for EventDragOver, true for other events
+func (w *Window) dispatch(ev Event) bool {
+	if w.recorder != nil {
+		w.recorder.add(ev)
+	}
+	switch ev.Kind {
+	case EventMouseMove:
+		w.Self.OnMouseMove(ev.X, ev.Y)
+	case EventMousePress:
+		w.Self.OnMousePress(ev.Btn, ev.X, ev.Y)
+	case EventMouseRelease:
+		w.Self.OnMouseRelease(ev.Btn, ev.X, ev.Y)
+	case EventMouseWheel:
+		w.Self.OnMouseWheel(ev.Vert, ev.Delta)
+	case EventKeyPress:
+		w.Self.OnKeyPress(ev.Key, ev.Mods, ev.Repeat)
+	case EventKeyRelease:
+		w.Self.OnKeyRelease(ev.Key, ev.Mods)
+	case EventTextInput:
+		w.Self.OnTextInput(ev.Text)
+	case EventResize:
+		w.width, w.height = ev.X, ev.Y
+		w.Self.OnResize(ev.X, ev.Y)
+	case EventMouseDelta:
+		w.Self.OnMouseDelta(ev.X, ev.Y)
+	case EventMouseEnter:
+		w.Self.OnMouseEnter(ev.X, ev.Y)
+	case EventMouseLeave:
+		w.Self.OnMouseLeave(ev.X, ev.Y)
+	case EventFocus:
+		w.focused = ev.Focus
+		w.Self.OnFocus(ev.Focus)
+	case EventState:
+		w.changeState(ev.State)
+	case EventMove:
+		w.x, w.y = ev.X, ev.Y
+		w.Self.OnMove(ev.X, ev.Y)
+	case EventScale:
+		if w.scale != ev.Delta {
+			w.scale = ev.Delta
+			w.Self.OnScaleChanged(ev.Delta)
+		}
+	case EventExpose:
+		w.Self.OnExpose(ev.X, ev.Y, ev.Width, ev.Height)
+	case EventDragEnter:
+		w.Self.OnDragEnter(ev.X, ev.Y)
+	case EventDragOver:
+		return w.Self.OnDragOver(ev.X, ev.Y)
+	case EventDragLeave:
+		w.Self.OnDragLeave()
+	case EventDrop:
+		w.Self.OnDrop(strings.Split(ev.Text, "\n"), ev.X, ev.Y)
+	}
+	return true
+}
+
+// InjectMouseMove dispatch a synthetic mouse move event
+func (w *Window) InjectMouseMove(x, y float32) {
+	w.dispatch(Event{Kind: EventMouseMove, X: x, Y: y})
+}
+
+// InjectMousePress dispatch a synthetic mouse press event
+func (w *Window) InjectMousePress(btn int, x, y float32) {
+	w.dispatch(Event{Kind: EventMousePress, Btn: btn, X: x, Y: y})
+}
+
+// InjectMouseRelease dispatch a synthetic mouse release event
+func (w *Window) InjectMouseRelease(btn int, x, y float32) {
+	w.dispatch(Event{Kind: EventMouseRelease, Btn: btn, X: x, Y: y})
+}
+
+// InjectMouseDelta dispatch a synthetic mouse motion event of relative mouse mode
+func (w *Window) InjectMouseDelta(dx, dy float32) {
+	w.dispatch(Event{Kind: EventMouseDelta, X: dx, Y: dy})
+}
+
+// InjectMouseWheel dispatch a synthetic mouse wheel event
+func (w *Window) InjectMouseWheel(vert bool, dz float32) {
+	w.dispatch(Event{Kind: EventMouseWheel, Vert: vert, Delta: dz})
+}
+
+// InjectKey dispatch a synthetic key press or release event
+func (w *Window) InjectKey(key Key, mods Mod, press bool) {
+	if press {
+		w.dispatch(Event{Kind: EventKeyPress, Key: key, Mods: mods})
+	} else {
+		w.dispatch(Event{Kind: EventKeyRelease, Key: key, Mods: mods})
+	}
+}
+
+// InjectTextInput dispatch a synthetic text input event
+func (w *Window) InjectTextInput(text string) {
+	w.dispatch(Event{Kind: EventTextInput, Text: text})
+}
+
+// InjectResize dispatch a synthetic resize event, the native window is not resized
+func (w *Window) InjectResize(width, height float32) {
+	w.dispatch(Event{Kind: EventResize, X: width, Y: height})
+}
+
+// InjectMouseEnter dispatch a synthetic event of mouse entering the window
+func (w *Window) InjectMouseEnter(x, y float32) {
+	w.dispatch(Event{Kind: EventMouseEnter, X: x, Y: y})
+}
+
+// InjectMouseLeave dispatch a synthetic event of mouse leaving the window
+func (w *Window) InjectMouseLeave(x, y float32) {
+	w.dispatch(Event{Kind: EventMouseLeave, X: x, Y: y})
+}
+
+// InjectFocus dispatch a synthetic event of window becoming active or inactive
+func (w *Window) InjectFocus(focused bool) {
+	w.dispatch(Event{Kind: EventFocus, Focus: focused})
+}
+
+// InjectDrop dispatch a synthetic event of files dropped at x, y
+func (w *Window) InjectDrop(paths []string, x, y float32) {
+	w.dispatch(Event{Kind: EventDrop, Text: strings.Join(paths, "\n"), X: x, Y: y})
+}
+
+// NewRecorder start recording events of the window, it replaces previous recorder
+func NewRecorder(w *Window) *Recorder {
+	r := &Recorder{w: w, start: time.Now()}
+	r.rec.Width, r.rec.Height = w.Size()
+	w.recorder = r
+	return r
+}
+
+func (r *Recorder) add(ev Event) {
+	ev.T = time.Since(r.start).Seconds()
+	r.rec.Events = append(r.rec.Events, ev)
+}
+
+// Stop recording, the recorded events are kept
+func (r *Recorder) Stop() {
+	if r.w.recorder == r {
+		r.w.recorder = nil
+	}
+}
+
+// Recording returns the events recorded
+func (r *Recorder) Recording() *Recording {
+	return &r.rec
+}
+
+// Save the recording to {store}/record/name.json
+func (r *Recorder) Save(name string) error {
+	return store.SaveState("record", name, &r.rec)
+}
+
+// LoadRecording load a recording saved by Recorder.Save
+func LoadRecording(name string) (*Recording, error) {
+	rec := new(Recording)
+	if err := store.LoadState("record", name, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Play dispatch all events to the window in order, without waiting between events.
+// the window is resized to the recorded size first, so that layout is the same.
+func (rec *Recording) Play(w *Window) {
+	if rec.Width > 0 && rec.Height > 0 {
+		w.InjectResize(rec.Width, rec.Height)
+	}
+	for _, ev := range rec.Events {
+		ev.T = 0
+		w.dispatch(ev)
+	}
+}
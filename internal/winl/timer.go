@@ -0,0 +1,170 @@
+package winl
+
+// #include "winl-c.h"
+import "C"
+
+import (
+	"container/heap"
+	"time"
+)
+
+const (
+	// interval of OnFrame if vsync is not available
+	frameInterval = time.Second / 60
+)
+
+// Timer calls a function on UI thread after a duration
+type Timer struct {
+	when   time.Time
+	period time.Duration // repeat if > 0
+	f      func()
+	index  int // in timerQueue, -1 if not pending
+}
+
+// timerQueue is min-heap of timers order by when
+type timerQueue []*Timer
+
+var timers timerQueue
+
+func (q timerQueue) Len() int           { return len(q) }
+func (q timerQueue) Less(i, j int) bool { return q[i].when.Before(q[j].when) }
+func (q timerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *timerQueue) Push(x interface{}) {
+	t := x.(*Timer)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *timerQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*q = old[:n-1]
+	return t
+}
+
+// AfterFunc waits for the duration to elapse and then calls f on UI thread.
+// it must be called on UI thread.
+func AfterFunc(d time.Duration, f func()) *Timer {
+	t := &Timer{f: f, index: -1}
+	t.Reset(d)
+	return t
+}
+
+// Stop prevents the Timer from firing, returns false if it already fired or been stopped
+func (t *Timer) Stop() bool {
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&timers, t.index)
+	return true
+}
+
+// Reset changes the timer to expire after duration d, returns true if it had been active
+func (t *Timer) Reset(d time.Duration) bool {
+	active := t.Stop()
+	t.when = time.Now().Add(d)
+	heap.Push(&timers, t)
+	return active
+}
+
+// Ticker calls a function on UI thread repeatedly
+type Ticker struct {
+	t *Timer
+}
+
+// NewTicker returns a Ticker calls f every period d on UI thread.
+// it must be called on UI thread.
+func NewTicker(d time.Duration, f func()) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for winl.NewTicker")
+	}
+	t := &Timer{f: f, period: d, index: -1}
+	t.Reset(d)
+	return &Ticker{t: t}
+}
+
+// Stop turns off the ticker
+func (tk *Ticker) Stop() {
+	tk.t.Stop()
+}
+
+// Reset stops the ticker and resets its period to d
+func (tk *Ticker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for winl.Ticker.Reset")
+	}
+	tk.t.period = d
+	tk.t.Reset(d)
+}
+
+// runTimers calls expired timers, returns time of next timer or zero time
+func runTimers(now time.Time) time.Time {
+	for len(timers) > 0 {
+		t := timers[0]
+		if t.when.After(now) {
+			return t.when
+		}
+		heap.Pop(&timers)
+		if t.period > 0 {
+			// skip missed ticks like time.Ticker
+			t.when = t.when.Add(t.period)
+			if t.when.Before(now) {
+				t.when = now.Add(t.period)
+			}
+			heap.Push(&timers, t)
+		}
+		t.f()
+	}
+	return time.Time{}
+}
+
+// runFrames calls OnFrame of animated windows, returns time of next frame or zero time
+func runFrames(now time.Time) (next time.Time) {
+	for _, w := range List() {
+		if w.hints&HintVideo == 0 || w.native == nilwin || w.state == C.WINL_STATE_MINIMIZED || !w.IsVisible() {
+			w.lastFrame = time.Time{}
+			continue
+		}
+		due := w.lastFrame.Add(frameInterval)
+		if w.lastFrame.IsZero() || !due.After(now) {
+			var dt float32
+			if !w.lastFrame.IsZero() {
+				dt = float32(now.Sub(w.lastFrame).Seconds())
+			}
+			w.lastFrame = now
+			w.Self.OnFrame(dt)
+			due = now.Add(frameInterval)
+		}
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return
+}
+
+//export winl_on_idle
+func winl_on_idle() C.int {
+	runPosted()
+	now := time.Now()
+	next := runTimers(now)
+	if f := runFrames(now); !f.IsZero() && (next.IsZero() || f.Before(next)) {
+		next = f
+	}
+	if next.IsZero() {
+		return -1
+	}
+	// round up, or the loop wakes up too early and spins
+	ms := (next.Sub(time.Now()) + time.Millisecond - 1) / time.Millisecond
+	if ms < 0 {
+		ms = 0
+	}
+	return C.int(ms)
+}
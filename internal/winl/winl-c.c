@@ -24,3 +24,22 @@ int winl_panicf(const char *fmt, ...) {
   winl_report(buf, 1);
   return ret;
 }
+
+#ifdef __linux__
+// winl_on_x_button dispatch press or release of X11 button number,
+// 1 ~ 3 are left, middle and right, 4 ~ 7 are wheel up, down, left and right.
+// it is shared by X11 and headless backends.
+void winl_on_x_button(NativeWnd win, unsigned int button, int press, float x, float y) {
+  static const int btns[] = {0, WINL_MOUSE_BTN_LEFT, WINL_MOUSE_BTN_MIDDLE, WINL_MOUSE_BTN_RIGHT};
+  if (button >= 1 && button <= 3) {
+    if (press) {
+      winl_on_mouse_press(win, btns[button], x, y);
+    } else {
+      winl_on_mouse_release(win, btns[button], x, y);
+    }
+  } else if (button >= 4 && button <= 7 && press) {
+    // a notch of wheel is a press and release, the release is ignored
+    winl_on_mouse_wheel(win, button <= 5, (button % 2 == 0) ? 1 : -1);
+  }
+}
+#endif
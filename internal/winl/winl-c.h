@@ -17,6 +17,9 @@
   typedef void* NativeWnd;
 #endif
 
+// opaque handle of custom cursor, an XID on X11 which is not a pointer, 0 for none
+typedef uintptr_t WinlCursor;
+
 enum {
   WINL_HINT_RESIZABLE = 0x0002,
   WINL_HINT_ANIMATE   = 0x0004,
@@ -30,35 +33,178 @@ enum {
   WINL_MOUSE_BTN_MIDDLE = 4,
 };
 
+// portable key codes, printable keys use the ASCII value of unshifted character
+enum {
+  WINL_KEY_UNKNOWN = 0,
+
+  WINL_KEY_SPACE = 32,
+  WINL_KEY_APOSTROPHE = 39,
+  WINL_KEY_COMMA = 44,
+  WINL_KEY_MINUS = 45,
+  WINL_KEY_PERIOD = 46,
+  WINL_KEY_SLASH = 47,
+  WINL_KEY_0 = 48, // '0' ~ '9' is 48 ~ 57
+  WINL_KEY_9 = 57,
+  WINL_KEY_SEMICOLON = 59,
+  WINL_KEY_EQUAL = 61,
+  WINL_KEY_A = 65, // 'A' ~ 'Z' is 65 ~ 90
+  WINL_KEY_Z = 90,
+  WINL_KEY_LEFT_BRACKET = 91,
+  WINL_KEY_BACKSLASH = 92,
+  WINL_KEY_RIGHT_BRACKET = 93,
+  WINL_KEY_GRAVE = 96,
+
+  WINL_KEY_ESCAPE = 256,
+  WINL_KEY_ENTER,
+  WINL_KEY_TAB,
+  WINL_KEY_BACKSPACE,
+  WINL_KEY_INSERT,
+  WINL_KEY_DELETE,
+  WINL_KEY_RIGHT,
+  WINL_KEY_LEFT,
+  WINL_KEY_DOWN,
+  WINL_KEY_UP,
+  WINL_KEY_PAGE_UP,
+  WINL_KEY_PAGE_DOWN,
+  WINL_KEY_HOME,
+  WINL_KEY_END,
+  WINL_KEY_CAPS_LOCK,
+  WINL_KEY_SCROLL_LOCK,
+  WINL_KEY_NUM_LOCK,
+  WINL_KEY_PRINT_SCREEN,
+  WINL_KEY_PAUSE,
+  WINL_KEY_MENU,
+
+  WINL_KEY_F1 = 290, // F1 ~ F12 is 290 ~ 301
+  WINL_KEY_F12 = 301,
+
+  WINL_KEY_KP_0 = 320, // keypad 0 ~ 9 is 320 ~ 329
+  WINL_KEY_KP_9 = 329,
+  WINL_KEY_KP_DECIMAL,
+  WINL_KEY_KP_DIVIDE,
+  WINL_KEY_KP_MULTIPLY,
+  WINL_KEY_KP_SUBTRACT,
+  WINL_KEY_KP_ADD,
+  WINL_KEY_KP_ENTER,
+  WINL_KEY_KP_EQUAL,
+
+  WINL_KEY_LEFT_SHIFT = 340,
+  WINL_KEY_LEFT_CONTROL,
+  WINL_KEY_LEFT_ALT,
+  WINL_KEY_LEFT_SUPER,
+  WINL_KEY_RIGHT_SHIFT,
+  WINL_KEY_RIGHT_CONTROL,
+  WINL_KEY_RIGHT_ALT,
+  WINL_KEY_RIGHT_SUPER,
+};
+
+// modifier key flags
+enum {
+  WINL_MOD_SHIFT     = 0x0001,
+  WINL_MOD_CONTROL   = 0x0002,
+  WINL_MOD_ALT       = 0x0004,
+  WINL_MOD_SUPER     = 0x0008,
+  WINL_MOD_CAPS_LOCK = 0x0010,
+  WINL_MOD_NUM_LOCK  = 0x0020,
+};
+
+// cursor shapes
+enum {
+  WINL_CURSOR_ARROW     = 0,
+  WINL_CURSOR_IBEAM     = 1,
+  WINL_CURSOR_HRESIZE   = 2,
+  WINL_CURSOR_VRESIZE   = 3,
+  WINL_CURSOR_HAND      = 4,
+  WINL_CURSOR_CROSSHAIR = 5,
+  WINL_CURSOR_HIDDEN    = 6,
+};
+
+// window state
+enum {
+  WINL_STATE_NORMAL    = 0,
+  WINL_STATE_MINIMIZED = 1,
+  WINL_STATE_MAXIMIZED = 2,
+};
+
+// display device
+typedef struct WinlMonitor {
+  int x, y, width, height; // in virtual screen
+  int widthMM, heightMM;   // physical size, 0 if unknown
+  float scale;             // content scale, 1 for 96 DPI
+  int primary;
+} WinlMonitor;
+
 void winl_get_screen_size(int *width, int *height);
+int winl_get_monitors(WinlMonitor* monitors, int max); // returns count
+float winl_get_content_scale(NativeWnd win);
 
 NativeWnd winl_create(int ws, int width, int height);
 void winl_show(NativeWnd win);
 int winl_is_visible(NativeWnd win);
 void winl_destroy(NativeWnd win);
 void winl_set_title(NativeWnd win, const char * title);
+void winl_set_icon(NativeWnd win, int count, const int* sizes, const unsigned char* rgba); // sizes is width, height pairs, rgba of images are concatenated, not premultiplied
+void winl_request_attention(NativeWnd win); // until the window is focused
+void winl_set_progress(NativeWnd win, float fraction); // in taskbar or dock, negative to hide, no-op on X11
+void winl_set_state(NativeWnd win, int state); // WINL_STATE_*
+void winl_focus(NativeWnd win);
 void winl_get_size(NativeWnd win, float *width, float *height);
+void winl_get_position(NativeWnd win, float *x, float *y); // of client area in screen
+void winl_move(NativeWnd win, float x, float y);
+void winl_resize(NativeWnd win, float width, float height);
+void winl_set_size_limits(NativeWnd win, float minWidth, float minHeight, float maxWidth, float maxHeight); // 0 for no limit
 int winl_is_full_screen(NativeWnd win);
 void winl_toggle_full_screen(NativeWnd win);
 int winl_make_current(NativeWnd win); // pass 0 to release current context
+void* winl_get_context(NativeWnd win); // OpenGL context of window, contexts of all windows are in one share group
+void* winl_current_context(); // NULL if none
 void winl_swap_buffers(NativeWnd win);
+void winl_set_swap_interval(NativeWnd win, int interval); // 1 to sync with vertical blank
 int winl_event_loop();
 void winl_exit_loop(int code);
+void winl_wakeup(); // wake up event loop from other thread, it calls winl_on_idle
+int winl_is_ui_thread(); // is the thread of event loop
 char* winl_os_version(); // use free to release memory
 void winl_expose(NativeWnd win, float x, float y, float width, float height);
+int winl_grab_mouse(NativeWnd win, int grab); // returns 0 if failed
+int winl_set_relative_mouse(NativeWnd win, int enable); // returns 0 if failed
+void winl_set_cursor(NativeWnd win, int shape); // WINL_CURSOR_*
+WinlCursor winl_create_cursor(const unsigned char* rgba, int width, int height, int hotX, int hotY); // rgba is not premultiplied, returns 0 if failed
+void winl_set_custom_cursor(NativeWnd win, WinlCursor cursor);
+void winl_destroy_cursor(WinlCursor cursor);
+void winl_set_clipboard_text(int primary, const char* text); // text is UTF-8
+char* winl_get_clipboard_text(int primary); // use free to release memory, NULL if empty
+void winl_set_text_input_rect(NativeWnd win, float x, float y, float width, float height); // where input method shows candidates
+#ifdef __linux__
+void winl_on_x_button(NativeWnd win, unsigned int button, int press, float x, float y); // X11 button number, 4 ~ 7 for wheel
+#endif
 
 // event handlers is implement in winl.go
 extern void winl_on_start();
 extern void winl_on_exit(int code);
+extern int winl_on_idle(); // run due timers, frames and posted work, after every event and before waiting, returns milliseconds to wait, -1 for infinite
 extern void winl_on_destroy(NativeWnd win);
 extern void winl_on_resize(NativeWnd win, float width, float height);
+extern void winl_on_move(NativeWnd win, float x, float y);
+extern void winl_on_scale_changed(NativeWnd win, float scale); // win is 0 for all windows
 extern void winl_on_mouse_move(NativeWnd win, float x, float y);
 extern void winl_on_mouse_press(NativeWnd win, int btn, float x, float y);
 extern void winl_on_mouse_release(NativeWnd win, int btn, float x, float y);
-extern void winl_on_mouse_wheel(NativeWnd win, int vertical, float dz);
+extern void winl_on_mouse_delta(NativeWnd win, float dx, float dy);
+extern void winl_on_mouse_wheel(NativeWnd win, int vertical, float dz); // dz is in notches, positive for up or left
 extern void winl_on_mouse_enter(NativeWnd win, float x, float y);
 extern void winl_on_mouse_leave(NativeWnd win, float x, float y);
 extern void winl_on_expose(NativeWnd win, float x, float y, float width, float height);
+// mods are modifier keys held after the event, e.g. Shift is set by press of the first Shift key and cleared by release of the last one
+extern void winl_on_key_press(NativeWnd win, int key, int mods, int repeat);
+extern void winl_on_key_release(NativeWnd win, int key, int mods);
+extern void winl_on_text_input(NativeWnd win, char* text); // text is UTF-8
+extern void winl_on_focus(NativeWnd win, int focused);
+extern void winl_on_state_change(NativeWnd win, int state);
+extern void winl_on_drag_enter(NativeWnd win, float x, float y);
+extern int winl_on_drag_over(NativeWnd win, float x, float y); // returns non-zero if drop is accepted
+extern void winl_on_drag_leave(NativeWnd win);
+extern void winl_on_drop(NativeWnd win, char* paths, float x, float y); // UTF-8 paths separated by '\n'
 
 extern void winl_report(char* msg, int panic);
 
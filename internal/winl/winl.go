@@ -1,8 +1,9 @@
 package winl
 
 // #cgo darwin LDFLAGS: -framework Cocoa
-// #cgo windows LDFLAGS: -lgdi32 -lopengl32 -lglu32
-// #cgo linux LDFLAGS: -lX11 -lGL -lGLU
+// #cgo windows LDFLAGS: -lgdi32 -lopengl32 -lglu32 -limm32 -lole32 -luuid -lshell32
+// #cgo linux,!headless LDFLAGS: -lX11 -lXrender -lGL -lGLU -ldl
+// #cgo linux,headless LDFLAGS: -lEGL
 // #include <stdlib.h>
 // #include "winl-c.h"
 import "C"
@@ -10,10 +11,10 @@ import "C"
 import (
 	"fmt"
 	"log"
-	"os/exec"
 	"runtime"
 	"strings"
 	"tetra/lib/dbg"
+	"time"
 	"unsafe"
 
 	"tetra/internal/gl"
@@ -42,6 +43,13 @@ const (
 	Hint3D hints = C.WINL_HINT_3D
 )
 
+// mouse buttons
+const (
+	MouseLeft   = C.WINL_MOUSE_BTN_LEFT
+	MouseRight  = C.WINL_MOUSE_BTN_RIGHT
+	MouseMiddle = C.WINL_MOUSE_BTN_MIDDLE
+)
+
 var (
 	nilwin   C.NativeWnd // value for not a window
 	started  bool
@@ -56,9 +64,22 @@ type Window struct {
 	Self IWindow
 
 	native C.NativeWnd
+	x      float32
+	y      float32
 	width  float32
 	height float32
+	scale  float32
 	hints  hints
+
+	focused  bool
+	state    int // C.WINL_STATE_*
+	relative bool
+
+	minWidth, minHeight float32
+	maxWidth, maxHeight float32
+
+	lastFrame time.Time // of OnFrame
+	recorder  *Recorder
 }
 
 // Init the object
@@ -80,6 +101,41 @@ func (w *Window) OnResize(width, height float32) {
 	// dbg.Logf("OnResize(%f, %f)\n", width, height)
 }
 
+// OnMove event handler, x and y is position of client area in screen
+func (w *Window) OnMove(x, y float32) {
+	// dbg.Logf("OnMove(%f, %f)\n", x, y)
+}
+
+// OnFrame event handler, called every frame for window with HintVideo, dt is seconds since last frame
+func (w *Window) OnFrame(dt float32) {
+	// dbg.Logf("OnFrame(%f)\n", dt)
+}
+
+// OnDragEnter event handler, files are dragged into the window
+func (w *Window) OnDragEnter(x, y float32) {
+	// dbg.Logf("OnDragEnter(%f, %f)\n", x, y)
+}
+
+// OnDragOver event handler, returns true if files can be dropped at x, y
+func (w *Window) OnDragOver(x, y float32) bool {
+	return false
+}
+
+// OnDragLeave event handler, files are dragged out or the drop is canceled
+func (w *Window) OnDragLeave() {
+	// dbg.Logf("OnDragLeave()\n")
+}
+
+// OnDrop event handler, paths are local files dropped at x, y
+func (w *Window) OnDrop(paths []string, x, y float32) {
+	// dbg.Logf("OnDrop(%v, %f, %f)\n", paths, x, y)
+}
+
+// OnScaleChanged event handler, i.e. moved to monitor with different DPI
+func (w *Window) OnScaleChanged(scale float32) {
+	// dbg.Logf("OnScaleChanged(%f)\n", scale)
+}
+
 // OnMouseEnter event handler
 func (w *Window) OnMouseEnter(x, y float32) {
 	dbg.Logf("OnMouseEnter(%f, %f)\n", x, y)
@@ -100,12 +156,17 @@ func (w *Window) OnMousePress(btn int, x, y float32) {
 	// dbg.Logf("OnMousePress(%d, %f, %f)\n", btn, x, y)
 }
 
+// OnMouseDelta event handler, called instead of OnMouseMove in relative mouse mode
+func (w *Window) OnMouseDelta(dx, dy float32) {
+	// dbg.Logf("OnMouseDelta(%f, %f)\n", dx, dy)
+}
+
 // OnMouseRelease event handler
 func (w *Window) OnMouseRelease(btn int, x, y float32) {
 	// dbg.Logf("OnMouseRelease(%d, %f, %f)\n", btn, x, y)
 }
 
-// OnMouseWheel event handler
+// OnMouseWheel event handler, dz is in notches of wheel, positive if it's rotated up or tilted left, fractions from precise devices
 func (w *Window) OnMouseWheel(vert bool, dz float32) {
 	// if vert {
 	// 	dbg.Logf("OnMouseWheel(vert, %f)\n", dz)
@@ -119,6 +180,41 @@ func (w *Window) OnExpose(x, y, width, height float32) {
 	// dbg.Logf("OnExpose(%g, %g, %g, %g)\n", x, y, width, height)
 }
 
+// OnKeyPress event handler, mods are held after the event, repeat is true if generated by auto-repeat
+func (w *Window) OnKeyPress(key Key, mods Mod, repeat bool) {
+	// dbg.Logf("OnKeyPress(%v, %v, %v)\n", key, mods, repeat)
+}
+
+// OnKeyRelease event handler, mods are held after the event
+func (w *Window) OnKeyRelease(key Key, mods Mod) {
+	// dbg.Logf("OnKeyRelease(%v, %v)\n", key, mods)
+}
+
+// OnTextInput event handler, text is composed by keyboard layout or input method
+func (w *Window) OnTextInput(text string) {
+	// dbg.Logf("OnTextInput(%q)\n", text)
+}
+
+// OnFocus event handler, focused is false when window becomes inactive
+func (w *Window) OnFocus(focused bool) {
+	// dbg.Logf("OnFocus(%v)\n", focused)
+}
+
+// OnMinimize event handler
+func (w *Window) OnMinimize() {
+	// dbg.Logf("OnMinimize()\n")
+}
+
+// OnMaximize event handler
+func (w *Window) OnMaximize() {
+	// dbg.Logf("OnMaximize()\n")
+}
+
+// OnRestore event handler, window is restored from minimized or maximized state
+func (w *Window) OnRestore() {
+	// dbg.Logf("OnRestore()\n")
+}
+
 // SetHints set hints for window style
 func (w *Window) SetHints(hints hints) {
 	w.hints |= hints
@@ -144,6 +240,12 @@ func (w *Window) Create(width, height int) error {
 		panic("failed to crate native window.")
 	}
 	winMap[w.native] = w
+	var x, y, cx, cy C.float
+	C.winl_get_position(w.native, &x, &y)
+	w.x, w.y = float32(x), float32(y)
+	C.winl_get_size(w.native, &cx, &cy)
+	w.width, w.height = float32(cx), float32(cy)
+	w.scale = float32(C.winl_get_content_scale(w.native))
 	C.winl_make_current(w.native) // important
 	if !glinited {
 		if err := gl.Init(); err != nil {
@@ -153,6 +255,10 @@ func (w *Window) Create(width, height int) error {
 		version := gl.GoStr(gl.GetString(gl.VERSION))
 		log.Println("OpenGL version:", version)
 	}
+	if w.hints&HintVideo != 0 {
+		// Present waits for vertical blank, this paces OnFrame
+		C.winl_set_swap_interval(w.native, 1)
+	}
 	w.Self.OnCreate()
 
 	return nil
@@ -211,12 +317,69 @@ func (w *Window) Show() {
 	C.winl_show(w.native)
 }
 
+// HasFocus determine if window is active and receives keyboard input
+func (w *Window) HasFocus() bool {
+	return w.focused
+}
+
+// IsMinimized determine if window is minimized
+func (w *Window) IsMinimized() bool {
+	return w.state == C.WINL_STATE_MINIMIZED
+}
+
+// IsMaximized determine if window is maximized
+func (w *Window) IsMaximized() bool {
+	return w.state == C.WINL_STATE_MAXIMIZED
+}
+
+// Minimize the window
+func (w *Window) Minimize() {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_set_state(w.native, C.WINL_STATE_MINIMIZED)
+}
+
+// Maximize the window
+func (w *Window) Maximize() {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_set_state(w.native, C.WINL_STATE_MAXIMIZED)
+}
+
+// Restore the window from minimized or maximized state
+func (w *Window) Restore() {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_set_state(w.native, C.WINL_STATE_NORMAL)
+}
+
+// Focus bring window to front and make it active
+func (w *Window) Focus() {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_focus(w.native)
+}
+
 // MakeCurrent set current OpenGL to this window
 func (w *Window) MakeCurrent() bool {
 	dbg.Logln("(w *Window) MakeCurrent() bool")
 	return C.winl_make_current(w.native) != 0
 }
 
+// Context returns the OpenGL context of window, contexts of all windows are in one share group
+func (w *Window) Context() uintptr {
+	return uintptr(C.winl_get_context(w.native))
+}
+
+// CurrentContext returns the current OpenGL context, 0 if none
+func CurrentContext() uintptr {
+	return uintptr(C.winl_current_context())
+}
+
 // Present copy OpenGL content from back buffer to front buffer, make it visible
 func (w *Window) Present() {
 	C.winl_swap_buffers(w.native)
@@ -227,12 +390,114 @@ func (w *Window) Size() (width, height float32) {
 	return w.width, w.height
 }
 
+// ContentScale reports how many pixels is an unit of UI, it is 1 for 96 DPI screen
+func (w *Window) ContentScale() float32 {
+	if w.scale <= 0 {
+		return 1
+	}
+	return w.scale
+}
+
+// Position reports position of window's client area in screen
+func (w *Window) Position() (x, y float32) {
+	return w.x, w.y
+}
+
+// Move the window's client area to x, y of screen
+func (w *Window) Move(x, y float32) {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_move(w.native, C.float(x), C.float(y))
+}
+
+// Resize the window's client area
+func (w *Window) Resize(width, height float32) {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_resize(w.native, C.float(width), C.float(height))
+}
+
+// SetMinSize limit the minimum size of client area, 0 for no limit
+func (w *Window) SetMinSize(width, height float32) {
+	if w.native == nilwin {
+		return
+	}
+	w.minWidth, w.minHeight = width, height
+	C.winl_set_size_limits(w.native, C.float(w.minWidth), C.float(w.minHeight), C.float(w.maxWidth), C.float(w.maxHeight))
+}
+
+// SetMaxSize limit the maximum size of client area, 0 for no limit
+func (w *Window) SetMaxSize(width, height float32) {
+	if w.native == nilwin {
+		return
+	}
+	w.maxWidth, w.maxHeight = width, height
+	C.winl_set_size_limits(w.native, C.float(w.minWidth), C.float(w.minHeight), C.float(w.maxWidth), C.float(w.maxHeight))
+}
+
+// CenterOnScreen move the window to center of main screen
+func (w *Window) CenterOnScreen() {
+	sw, sh := ScreenSize()
+	w.Move((float32(sw)-w.width)/2, (float32(sh)-w.height)/2)
+}
+
 // func (w *Window) MousePos() (x, y float32) {
 // 	var cx, cy C.float
 // 	C.winl_get_mouse_pos(w.native, &cx, &cy)
 // 	return float32(cx), float32(cy)
 // }
 
+// GrabMouse capture the mouse, mouse events are delivered even if pointer is outside of window
+func (w *Window) GrabMouse() bool {
+	if w.native == nilwin {
+		return false
+	}
+	return C.winl_grab_mouse(w.native, 1) != 0
+}
+
+// UngrabMouse release the mouse captured by GrabMouse
+func (w *Window) UngrabMouse() {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_grab_mouse(w.native, 0)
+}
+
+// SetRelativeMouse enable relative mouse mode, pointer is hidden and locked, motion is reported by OnMouseDelta
+func (w *Window) SetRelativeMouse(enable bool) bool {
+	if w.native == nilwin {
+		return false
+	}
+	var e C.int
+	if enable {
+		e = 1
+	}
+	if C.winl_set_relative_mouse(w.native, e) == 0 {
+		return false
+	}
+	w.relative = enable
+	return true
+}
+
+// IsRelativeMouse reports whether relative mouse mode is enabled
+func (w *Window) IsRelativeMouse() bool {
+	return w.relative
+}
+
+// SetCursor set shape of mouse cursor when it is over the window
+func (w *Window) SetCursor(c Cursor) {
+	if w.native == nilwin {
+		return
+	}
+	if p, ok := customCursors[c]; ok {
+		C.winl_set_custom_cursor(w.native, p)
+		return
+	}
+	C.winl_set_cursor(w.native, C.int(c))
+}
+
 // SetTitle set the window title
 func (w *Window) SetTitle(title string) {
 	ctitle := C.CString(title)
@@ -245,6 +510,14 @@ func (w *Window) Expose(x, y, width, height float32) {
 	C.winl_expose(w.native, C.float(x), C.float(y), C.float(width), C.float(height))
 }
 
+// SetTextInputRect tells input method where text is edited, candidate window is placed near it
+func (w *Window) SetTextInputRect(x, y, width, height float32) {
+	if w.native == nilwin {
+		return
+	}
+	C.winl_set_text_input_rect(w.native, C.float(x), C.float(y), C.float(width), C.float(height))
+}
+
 func goWin(win C.NativeWnd) *Window {
 	return winMap[win]
 }
@@ -258,8 +531,31 @@ func winl_on_resize(win C.NativeWnd, width, height float32) {
 	if w == nil {
 		return
 	}
-	w.width, w.height = width, height
-	w.Self.OnResize(width, height)
+	w.dispatch(Event{Kind: EventResize, X: width, Y: height})
+}
+
+//export winl_on_move
+func winl_on_move(win C.NativeWnd, x, y float32) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventMove, X: x, Y: y})
+}
+
+//export winl_on_scale_changed
+func winl_on_scale_changed(win C.NativeWnd, scale float32) {
+	var ws []*Window
+	if win == nilwin {
+		for _, w := range winMap {
+			ws = append(ws, w)
+		}
+	} else if w := goWin(win); w != nil {
+		ws = append(ws, w)
+	}
+	for _, w := range ws {
+		w.dispatch(Event{Kind: EventScale, Delta: scale})
+	}
 }
 
 //export winl_on_destroy
@@ -278,7 +574,7 @@ func winl_on_mouse_move(win C.NativeWnd, x, y float32) {
 	if w == nil {
 		return
 	}
-	w.Self.OnMouseMove(x, y)
+	w.dispatch(Event{Kind: EventMouseMove, X: x, Y: y})
 }
 
 //export winl_on_mouse_press
@@ -287,7 +583,16 @@ func winl_on_mouse_press(win C.NativeWnd, btn C.int, x, y float32) {
 	if w == nil {
 		return
 	}
-	w.Self.OnMousePress(int(btn), x, y)
+	w.dispatch(Event{Kind: EventMousePress, Btn: int(btn), X: x, Y: y})
+}
+
+//export winl_on_mouse_delta
+func winl_on_mouse_delta(win C.NativeWnd, dx, dy float32) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventMouseDelta, X: dx, Y: dy})
 }
 
 //export winl_on_mouse_release
@@ -296,8 +601,7 @@ func winl_on_mouse_release(win C.NativeWnd, btn C.int, x, y float32) {
 	if w == nil {
 		return
 	}
-
-	w.Self.OnMouseRelease(int(btn), x, y)
+	w.dispatch(Event{Kind: EventMouseRelease, Btn: int(btn), X: x, Y: y})
 }
 
 //export winl_on_mouse_wheel
@@ -306,11 +610,7 @@ func winl_on_mouse_wheel(win C.NativeWnd, vertical C.int, dz float32) {
 	if w == nil {
 		return
 	}
-	var vert bool
-	if vertical != 0 {
-		vert = true
-	}
-	w.Self.OnMouseWheel(vert, dz)
+	w.dispatch(Event{Kind: EventMouseWheel, Vert: vertical != 0, Delta: dz})
 }
 
 //export winl_on_mouse_enter
@@ -319,7 +619,7 @@ func winl_on_mouse_enter(win C.NativeWnd, x, y float32) {
 	if w == nil {
 		return
 	}
-	w.Self.OnMouseEnter(x, y)
+	w.dispatch(Event{Kind: EventMouseEnter, X: x, Y: y})
 }
 
 //export winl_on_mouse_leave
@@ -328,7 +628,7 @@ func winl_on_mouse_leave(win C.NativeWnd, x, y float32) {
 	if w == nil {
 		return
 	}
-	w.Self.OnMouseLeave(x, y)
+	w.dispatch(Event{Kind: EventMouseLeave, X: x, Y: y})
 }
 
 //export winl_on_expose
@@ -337,7 +637,117 @@ func winl_on_expose(win C.NativeWnd, x, y, width, height float32) {
 	if w == nil {
 		return
 	}
-	w.Self.OnExpose(x, y, width, height)
+	w.dispatch(Event{Kind: EventExpose, X: x, Y: y, Width: width, Height: height})
+}
+
+//export winl_on_key_press
+func winl_on_key_press(win C.NativeWnd, key, mods, repeat C.int) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventKeyPress, Key: Key(key), Mods: Mod(mods), Repeat: repeat != 0})
+}
+
+//export winl_on_key_release
+func winl_on_key_release(win C.NativeWnd, key, mods C.int) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventKeyRelease, Key: Key(key), Mods: Mod(mods)})
+}
+
+//export winl_on_text_input
+func winl_on_text_input(win C.NativeWnd, text *C.char) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	// control characters are delivered by OnKeyPress
+	s := strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, C.GoString(text))
+	if s == "" {
+		return
+	}
+	w.dispatch(Event{Kind: EventTextInput, Text: s})
+}
+
+//export winl_on_focus
+func winl_on_focus(win C.NativeWnd, focused C.int) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventFocus, Focus: focused != 0})
+}
+
+//export winl_on_state_change
+func winl_on_state_change(win C.NativeWnd, state C.int) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventState, State: int(state)})
+}
+
+// changeState set the state and calls OnMinimize, OnMaximize or OnRestore
+func (w *Window) changeState(state int) {
+	old := w.state
+	w.state = state
+	switch state {
+	case C.WINL_STATE_MINIMIZED:
+		w.Self.OnMinimize()
+	case C.WINL_STATE_MAXIMIZED:
+		if old == C.WINL_STATE_MINIMIZED {
+			w.Self.OnRestore()
+		}
+		w.Self.OnMaximize()
+	default:
+		if old != C.WINL_STATE_NORMAL {
+			w.Self.OnRestore()
+		}
+	}
+}
+
+//export winl_on_drag_enter
+func winl_on_drag_enter(win C.NativeWnd, x, y float32) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventDragEnter, X: x, Y: y})
+}
+
+//export winl_on_drag_over
+func winl_on_drag_over(win C.NativeWnd, x, y float32) C.int {
+	w := goWin(win)
+	if w == nil || !w.dispatch(Event{Kind: EventDragOver, X: x, Y: y}) {
+		return 0
+	}
+	return 1
+}
+
+//export winl_on_drag_leave
+func winl_on_drag_leave(win C.NativeWnd) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventDragLeave})
+}
+
+//export winl_on_drop
+func winl_on_drop(win C.NativeWnd, paths *C.char, x, y float32) {
+	w := goWin(win)
+	if w == nil {
+		return
+	}
+	w.dispatch(Event{Kind: EventDrop, Text: C.GoString(paths), X: x, Y: y})
 }
 
 // ScreenSize return size of main screen
@@ -428,6 +838,9 @@ func List() (ret []*Window) {
 }
 
 func init() {
+	// package init is on main thread, keep the event loop on it
+	runtime.LockOSThread()
+
 	// create then destroy a window, force opengl init
 	w := new(Window)
 	w.Create(100, 100)
@@ -437,76 +850,47 @@ func init() {
 	}
 }
 
-func messageBoxLinux(w *Window, msg, title string) {
-	if !started {
-		panic("can't popup window before func Run()")
-	}
-	path, err := exec.LookPath("gxmessage")
-	if err == nil {
-		cmd := exec.Command(path, "-center", "-geometry", "600x200",
-			"-buttons", "GTK_STOCK_OK:0",
-			"-title", title, msg)
-		cmd.Run()
-		return
-	}
+// Dialogs shows message boxes in place of native ones, i.e. rendered by gui package
+type Dialogs interface {
+	MessageBox(w *Window, msg, title string, done func())
+	ConfirmBox(w *Window, msg, title string, done func(ok bool))
+}
 
-	path, err = exec.LookPath("xmessage")
-	if err == nil {
-		cmd := exec.Command(path, "-center", "-geometry", "600x200",
-			"-buttons", "OK:0",
-			"-title", title, msg)
-		cmd.Run()
-		return
-	}
-	log.Println("warning: failed to locate gxmessage or xmessage utility, unable to show message box.")
-	path, err = exec.LookPath("notify-send")
-	if err == nil {
-		cmd := exec.Command(path, "Please install gxmessage or xmessage utility.")
-		cmd.Run()
-	}
+var (
+	dialogs     Dialogs
+	autoConfirm bool
+)
+
+// SetDialogs install dialogs used by MessageBox and ConfirmBox, nil to use native ones
+func SetDialogs(d Dialogs) {
+	dialogs = d
 }
 
-func confirmBoxLinux(w *Window, msg, title string) bool {
-	if !started {
-		panic("can't popup window before func Run()")
-	}
-	path, err := exec.LookPath("gxmessage")
-	if err == nil {
-		cmd := exec.Command(path, "-center", "-geometry", "600x200",
-			"-buttons", "GTK_STOCK_CANCEL:1,GTK_STOCK_OK:0",
-			"-title", title, msg)
-		if err = cmd.Run(); err == nil {
-			return true
-		}
-		return false
-	}
+// SetAutoConfirm makes dialogs close immediately as if user pressed OK, i.e. the -force flag
+func SetAutoConfirm(b bool) {
+	autoConfirm = b
+}
 
-	path, err = exec.LookPath("xmessage")
-	if err == nil {
-		cmd := exec.Command(path, "-center", "-geometry", "600x200",
-			"-buttons", "Cancel:1,OK:0",
-			"-title", title, msg)
-		if err = cmd.Run(); err == nil {
-			return true
-		}
-		return false
-	}
-	log.Println("wraning: failed to locate gxmessage or xmessage utility, unable to show message box.")
-	path, err = exec.LookPath("notify-send")
-	if err == nil {
-		cmd := exec.Command(path, "Please install gxmessage or xmessage utility.")
-		cmd.Run()
+// AutoConfirm reports whether dialogs are confirmed without prompting, the dialog is logged if so.
+// every dialog, native or installed by SetDialogs, asks it once before prompting.
+func AutoConfirm(title, msg string) bool {
+	if autoConfirm {
+		log.Printf("%s: %s (confirmed by -force)\n", title, msg)
 	}
-	return false
+	return autoConfirm
 }
 
-// MessageBox show a simple message box
-func MessageBox(w *Window, msg, title string) {
-	//if !started {
-	//	panic("can't popup window before func Run()")
-	//}
-	if runtime.GOOS == "linux" {
-		messageBoxLinux(w, msg, title)
+// MessageBox show a simple message box, done is called after it is closed and can be nil
+func MessageBox(w *Window, msg, title string, done func()) {
+	if done == nil {
+		done = func() {}
+	}
+	if dialogs != nil {
+		dialogs.MessageBox(w, msg, title, done)
+		return
+	}
+	if AutoConfirm(title, msg) {
+		done()
 		return
 	}
 	cmsg := C.CString(msg)
@@ -518,15 +902,21 @@ func MessageBox(w *Window, msg, title string) {
 		nw = w.native
 	}
 	C.winl_message_box(nw, cmsg, ctitle)
+	done()
 }
 
-// ConfirmBox show a simple box ask user to confirm.
-func ConfirmBox(w *Window, msg, title string) bool {
-	//if !started {
-	//	panic("can't popup window before func Run()")
-	//}
-	if runtime.GOOS == "linux" {
-		return confirmBoxLinux(w, msg, title)
+// ConfirmBox show a simple box ask user to confirm, done receives the answer and can be nil
+func ConfirmBox(w *Window, msg, title string, done func(ok bool)) {
+	if done == nil {
+		done = func(bool) {}
+	}
+	if dialogs != nil {
+		dialogs.ConfirmBox(w, msg, title, done)
+		return
+	}
+	if AutoConfirm(title, msg) {
+		done(true)
+		return
 	}
 	cmsg := C.CString(msg)
 	defer C.free(unsafe.Pointer(cmsg))
@@ -536,8 +926,5 @@ func ConfirmBox(w *Window, msg, title string) bool {
 	if w != nil {
 		nw = w.native
 	}
-	if C.winl_confirm_box(nw, cmsg, ctitle) != 0 {
-		return true
-	}
-	return false
+	done(C.winl_confirm_box(nw, cmsg, ctitle) != 0)
 }
@@ -19,12 +19,16 @@ static AppDelegate* getAppDelegate() {
   return NSApplication.sharedApplication.delegate;
 }
 
-@interface OpenGLView : NSOpenGLView
+@interface OpenGLView : NSOpenGLView<NSTextInputClient, NSDraggingDestination>
 {
   @public
   WindowController* _wc;
+  NSRect _textInputRect; // in view coordinates, top-left origin
+  NSCursor* _cursor;
+  BOOL _relative; // relative mouse mode
 //  NSTrackingArea* _ta;
 }
+- (void)handleMotion:(NSEvent *)theEvent;
 @end
 
 @interface ViewController : NSViewController
@@ -42,7 +46,9 @@ static AppDelegate* getAppDelegate() {
 @public
   OpenGLView* glview;
   BOOL _bFirstResize;
+  int _state; // WINL_STATE_*
 }
+- (void)updateState;
 @end
 
 /*
@@ -53,8 +59,21 @@ static AppDelegate* getAppDelegate() {
 ██ ██      ██ ██      ███████ ███████ ██      ██ ███████ ██   ████    ██
 */
 
+// fires when next winl timer or frame is due, the run loop then calls idleObserver
+static NSTimer* _idleTimer;
+
+static void idleObserver(CFRunLoopObserverRef observer, CFRunLoopActivity activity, void *info) {
+  int timeout = winl_on_idle();
+  _idleTimer.fireDate = timeout < 0 ? [NSDate distantFuture] : [NSDate dateWithTimeIntervalSinceNow: timeout / 1000.0];
+}
+
 @implementation AppDelegate
 - (void)applicationDidFinishLaunching:(NSNotification *)aNotification {
+  _idleTimer = [NSTimer timerWithTimeInterval:1e9 repeats:YES block:^(NSTimer *timer) {}];
+  _idleTimer.fireDate = [NSDate distantFuture];
+  [[NSRunLoop currentRunLoop] addTimer:_idleTimer forMode:NSRunLoopCommonModes];
+  CFRunLoopObserverRef observer = CFRunLoopObserverCreate(NULL, kCFRunLoopBeforeWaiting, true, 0, idleObserver, NULL);
+  CFRunLoopAddObserver(CFRunLoopGetCurrent(), observer, kCFRunLoopCommonModes);
   winl_on_start();
 }
 
@@ -68,6 +87,80 @@ static AppDelegate* getAppDelegate() {
 @end // AppDelegate
 
 
+// virtual key codes of Apple keyboards (kVK_* in Carbon's Events.h) to winl keys
+static const int keyTable[128] = {
+  [0x00] = WINL_KEY_A, [0x0B] = WINL_KEY_A + 1, [0x08] = WINL_KEY_A + 2, [0x02] = WINL_KEY_A + 3,
+  [0x0E] = WINL_KEY_A + 4, [0x03] = WINL_KEY_A + 5, [0x05] = WINL_KEY_A + 6, [0x04] = WINL_KEY_A + 7,
+  [0x22] = WINL_KEY_A + 8, [0x26] = WINL_KEY_A + 9, [0x28] = WINL_KEY_A + 10, [0x25] = WINL_KEY_A + 11,
+  [0x2E] = WINL_KEY_A + 12, [0x2D] = WINL_KEY_A + 13, [0x1F] = WINL_KEY_A + 14, [0x23] = WINL_KEY_A + 15,
+  [0x0C] = WINL_KEY_A + 16, [0x0F] = WINL_KEY_A + 17, [0x01] = WINL_KEY_A + 18, [0x11] = WINL_KEY_A + 19,
+  [0x20] = WINL_KEY_A + 20, [0x09] = WINL_KEY_A + 21, [0x0D] = WINL_KEY_A + 22, [0x07] = WINL_KEY_A + 23,
+  [0x10] = WINL_KEY_A + 24, [0x06] = WINL_KEY_Z,
+  [0x1D] = WINL_KEY_0, [0x12] = WINL_KEY_0 + 1, [0x13] = WINL_KEY_0 + 2, [0x14] = WINL_KEY_0 + 3,
+  [0x15] = WINL_KEY_0 + 4, [0x17] = WINL_KEY_0 + 5, [0x16] = WINL_KEY_0 + 6, [0x1A] = WINL_KEY_0 + 7,
+  [0x1C] = WINL_KEY_0 + 8, [0x19] = WINL_KEY_9,
+  [0x31] = WINL_KEY_SPACE, [0x27] = WINL_KEY_APOSTROPHE, [0x2B] = WINL_KEY_COMMA,
+  [0x1B] = WINL_KEY_MINUS, [0x2F] = WINL_KEY_PERIOD, [0x2C] = WINL_KEY_SLASH,
+  [0x29] = WINL_KEY_SEMICOLON, [0x18] = WINL_KEY_EQUAL, [0x21] = WINL_KEY_LEFT_BRACKET,
+  [0x2A] = WINL_KEY_BACKSLASH, [0x1E] = WINL_KEY_RIGHT_BRACKET, [0x32] = WINL_KEY_GRAVE,
+  [0x35] = WINL_KEY_ESCAPE, [0x24] = WINL_KEY_ENTER, [0x30] = WINL_KEY_TAB,
+  [0x33] = WINL_KEY_BACKSPACE, [0x72] = WINL_KEY_INSERT, [0x75] = WINL_KEY_DELETE,
+  [0x7C] = WINL_KEY_RIGHT, [0x7B] = WINL_KEY_LEFT, [0x7D] = WINL_KEY_DOWN, [0x7E] = WINL_KEY_UP,
+  [0x74] = WINL_KEY_PAGE_UP, [0x79] = WINL_KEY_PAGE_DOWN, [0x73] = WINL_KEY_HOME, [0x77] = WINL_KEY_END,
+  [0x39] = WINL_KEY_CAPS_LOCK, [0x47] = WINL_KEY_NUM_LOCK, [0x6E] = WINL_KEY_MENU,
+  [0x7A] = WINL_KEY_F1, [0x78] = WINL_KEY_F1 + 1, [0x63] = WINL_KEY_F1 + 2, [0x76] = WINL_KEY_F1 + 3,
+  [0x60] = WINL_KEY_F1 + 4, [0x61] = WINL_KEY_F1 + 5, [0x62] = WINL_KEY_F1 + 6, [0x64] = WINL_KEY_F1 + 7,
+  [0x65] = WINL_KEY_F1 + 8, [0x6D] = WINL_KEY_F1 + 9, [0x67] = WINL_KEY_F1 + 10, [0x6F] = WINL_KEY_F12,
+  [0x52] = WINL_KEY_KP_0, [0x53] = WINL_KEY_KP_0 + 1, [0x54] = WINL_KEY_KP_0 + 2, [0x55] = WINL_KEY_KP_0 + 3,
+  [0x56] = WINL_KEY_KP_0 + 4, [0x57] = WINL_KEY_KP_0 + 5, [0x58] = WINL_KEY_KP_0 + 6, [0x59] = WINL_KEY_KP_0 + 7,
+  [0x5B] = WINL_KEY_KP_0 + 8, [0x5C] = WINL_KEY_KP_9,
+  [0x41] = WINL_KEY_KP_DECIMAL, [0x4B] = WINL_KEY_KP_DIVIDE, [0x43] = WINL_KEY_KP_MULTIPLY,
+  [0x4E] = WINL_KEY_KP_SUBTRACT, [0x45] = WINL_KEY_KP_ADD, [0x4C] = WINL_KEY_KP_ENTER,
+  [0x51] = WINL_KEY_KP_EQUAL,
+  [0x38] = WINL_KEY_LEFT_SHIFT, [0x3B] = WINL_KEY_LEFT_CONTROL, [0x3A] = WINL_KEY_LEFT_ALT,
+  [0x37] = WINL_KEY_LEFT_SUPER, [0x3C] = WINL_KEY_RIGHT_SHIFT, [0x3E] = WINL_KEY_RIGHT_CONTROL,
+  [0x3D] = WINL_KEY_RIGHT_ALT, [0x36] = WINL_KEY_RIGHT_SUPER,
+};
+
+static int translateKey(unsigned short keyCode) {
+  if (keyCode >= 128) {
+    return WINL_KEY_UNKNOWN;
+  }
+  return keyTable[keyCode];
+}
+
+static int translateMods(NSEventModifierFlags flags) {
+  int mods = 0;
+  if (flags & NSEventModifierFlagShift) {
+    mods |= WINL_MOD_SHIFT;
+  }
+  if (flags & NSEventModifierFlagControl) {
+    mods |= WINL_MOD_CONTROL;
+  }
+  if (flags & NSEventModifierFlagOption) {
+    mods |= WINL_MOD_ALT;
+  }
+  if (flags & NSEventModifierFlagCommand) {
+    mods |= WINL_MOD_SUPER;
+  }
+  if (flags & NSEventModifierFlagCapsLock) {
+    mods |= WINL_MOD_CAPS_LOCK;
+  }
+  return mods;
+}
+
+// the device independent flag of a modifier key, 0 for other keys
+static NSEventModifierFlags modFlagOfKey(int key) {
+  switch (key) {
+  case WINL_KEY_LEFT_SHIFT: case WINL_KEY_RIGHT_SHIFT: return NSEventModifierFlagShift;
+  case WINL_KEY_LEFT_CONTROL: case WINL_KEY_RIGHT_CONTROL: return NSEventModifierFlagControl;
+  case WINL_KEY_LEFT_ALT: case WINL_KEY_RIGHT_ALT: return NSEventModifierFlagOption;
+  case WINL_KEY_LEFT_SUPER: case WINL_KEY_RIGHT_SUPER: return NSEventModifierFlagCommand;
+  case WINL_KEY_CAPS_LOCK: return NSEventModifierFlagCapsLock;
+  }
+  return 0;
+}
+
 @implementation OpenGLView
 
 - (instancetype)initWithFrame:(NSRect)frameRect pixelFormat:(NSOpenGLPixelFormat *)format {
@@ -81,6 +174,7 @@ static AppDelegate* getAppDelegate() {
         userInfo: nil];
       [self addTrackingArea: ta];
       [ta release];
+      [self registerForDraggedTypes: @[NSPasteboardTypeFileURL]];
     }
     return self;
 }
@@ -92,6 +186,11 @@ static AppDelegate* getAppDelegate() {
 //   [super release];
 // }
 
+- (void)resetCursorRects {
+  [super resetCursorRects];
+  [self addCursorRect:self.bounds cursor:(self->_cursor ? self->_cursor : [NSCursor arrowCursor])];
+}
+
 - (BOOL) acceptsFirstResponder {
   return YES;
 }
@@ -106,7 +205,8 @@ static AppDelegate* getAppDelegate() {
     float y = dirtyRect.origin.y;
     float w = dirtyRect.size.width;
     float h = dirtyRect.size.height;
-    y = self.bounds.size.height - y;
+    // origin of Cocoa is bottom-left
+    y = self.bounds.size.height - y - h;
     winl_on_expose(self->_wc, x, y, w, h);
 }
 
@@ -116,6 +216,52 @@ static AppDelegate* getAppDelegate() {
 //   winl_on_resize(_wc, sz.width, sz.height);
 // }
 
+- (NSPoint)dragLocation:(id<NSDraggingInfo>)sender {
+  NSPoint pt = [self convertPoint:[sender draggingLocation] fromView:nil];
+  pt.y = self.bounds.size.height - pt.y;
+  return pt;
+}
+
+- (NSDragOperation)dragOperation:(id<NSDraggingInfo>)sender {
+  NSPoint pt = [self dragLocation: sender];
+  if (([sender draggingSourceOperationMask] & NSDragOperationCopy) &&
+      winl_on_drag_over(self->_wc, pt.x, pt.y)) {
+    return NSDragOperationCopy;
+  }
+  return NSDragOperationNone;
+}
+
+- (NSDragOperation)draggingEntered:(id<NSDraggingInfo>)sender {
+  NSPoint pt = [self dragLocation: sender];
+  winl_on_drag_enter(self->_wc, pt.x, pt.y);
+  return [self dragOperation: sender];
+}
+
+- (NSDragOperation)draggingUpdated:(id<NSDraggingInfo>)sender {
+  return [self dragOperation: sender];
+}
+
+- (void)draggingExited:(id<NSDraggingInfo>)sender {
+  winl_on_drag_leave(self->_wc);
+}
+
+- (BOOL)performDragOperation:(id<NSDraggingInfo>)sender {
+  NSArray* urls = [[sender draggingPasteboard]
+    readObjectsForClasses: @[[NSURL class]]
+    options: @{NSPasteboardURLReadingFileURLsOnlyKey: @YES}];
+  if (urls.count == 0) {
+    winl_on_drag_leave(self->_wc);
+    return NO;
+  }
+  NSMutableArray* paths = [NSMutableArray arrayWithCapacity: urls.count];
+  for (NSURL* url in urls) {
+    [paths addObject: url.path];
+  }
+  NSPoint pt = [self dragLocation: sender];
+  winl_on_drop(self->_wc, (char*)[[paths componentsJoinedByString: @"\n"] UTF8String], pt.x, pt.y);
+  return YES;
+}
+
 - (void)mouseEntered:(NSEvent *)theEvent {
   NSPoint pt = [self convertPoint:[theEvent locationInWindow] fromView:nil];
   pt.y = self.bounds.size.height - pt.y;
@@ -124,6 +270,24 @@ static AppDelegate* getAppDelegate() {
 
 - (void)mouseMoved:(NSEvent *)theEvent {
   [super mouseMoved: theEvent];
+  [self handleMotion: theEvent];
+}
+
+// Cocoa sends drag instead of move when button is down
+- (void)mouseDragged:(NSEvent *)theEvent {
+  [self handleMotion: theEvent];
+}
+
+- (void)rightMouseDragged:(NSEvent *)theEvent {
+  [self handleMotion: theEvent];
+}
+
+- (void)handleMotion:(NSEvent *)theEvent {
+  if (self->_relative) {
+    // cursor is dissociated from mouse, only delta is meaningful
+    winl_on_mouse_delta(self->_wc, theEvent.deltaX, theEvent.deltaY);
+    return;
+  }
   NSPoint pt = [self convertPoint:[theEvent locationInWindow] fromView:nil];
   pt.y = self.bounds.size.height - pt.y;
   winl_on_mouse_move(self->_wc, pt.x, pt.y);
@@ -159,6 +323,116 @@ static AppDelegate* getAppDelegate() {
   winl_on_mouse_release(self->_wc, WINL_MOUSE_BTN_RIGHT, pt.x, pt.y);
 }
 
+- (void)otherMouseDown:(NSEvent *)theEvent {
+  if (theEvent.buttonNumber == 2) {
+    NSPoint pt = [self convertPoint:[theEvent locationInWindow] fromView:nil];
+    pt.y = self.bounds.size.height - pt.y;
+    winl_on_mouse_press(self->_wc, WINL_MOUSE_BTN_MIDDLE, pt.x, pt.y);
+  }
+}
+
+- (void)otherMouseUp:(NSEvent *)theEvent {
+  if (theEvent.buttonNumber == 2) {
+    NSPoint pt = [self convertPoint:[theEvent locationInWindow] fromView:nil];
+    pt.y = self.bounds.size.height - pt.y;
+    winl_on_mouse_release(self->_wc, WINL_MOUSE_BTN_MIDDLE, pt.x, pt.y);
+  }
+}
+
+- (void)scrollWheel:(NSEvent *)theEvent {
+  float dx = theEvent.scrollingDeltaX, dy = theEvent.scrollingDeltaY;
+  if (theEvent.hasPreciseScrollingDeltas) {
+    // trackpad reports points, about 40 points a notch of wheel
+    dx /= 40;
+    dy /= 40;
+  }
+  if (dy != 0) {
+    winl_on_mouse_wheel(self->_wc, 1, dy);
+  }
+  if (dx != 0) {
+    winl_on_mouse_wheel(self->_wc, 0, dx);
+  }
+}
+
+- (void)keyDown:(NSEvent *)theEvent {
+  int key = translateKey(theEvent.keyCode);
+  if (key != WINL_KEY_UNKNOWN) {
+    winl_on_key_press(self->_wc, key, translateMods(theEvent.modifierFlags), theEvent.isARepeat);
+  }
+  // let input method generate insertText:replacementRange:
+  [self interpretKeyEvents:@[theEvent]];
+}
+
+- (void)keyUp:(NSEvent *)theEvent {
+  int key = translateKey(theEvent.keyCode);
+  if (key != WINL_KEY_UNKNOWN) {
+    winl_on_key_release(self->_wc, key, translateMods(theEvent.modifierFlags));
+  }
+}
+
+// modifier keys don't generate keyDown/keyUp
+- (void)flagsChanged:(NSEvent *)theEvent {
+  int key = translateKey(theEvent.keyCode);
+  NSEventModifierFlags flag = modFlagOfKey(key);
+  if (flag == 0) {
+    return;
+  }
+  int mods = translateMods(theEvent.modifierFlags);
+  if (theEvent.modifierFlags & flag) {
+    winl_on_key_press(self->_wc, key, mods, 0);
+  } else {
+    winl_on_key_release(self->_wc, key, mods);
+  }
+}
+
+// NSTextInputClient
+
+- (void)insertText:(id)string replacementRange:(NSRange)replacementRange {
+  NSString* s = [string isKindOfClass:[NSAttributedString class]] ? [string string] : string;
+  winl_on_text_input(self->_wc, (char*)[s UTF8String]);
+}
+
+- (void)doCommandBySelector:(SEL)selector {
+  // keys like Enter and arrows are delivered by keyDown:
+}
+
+- (void)setMarkedText:(id)string selectedRange:(NSRange)selectedRange replacementRange:(NSRange)replacementRange {
+}
+
+- (void)unmarkText {
+}
+
+- (NSRange)selectedRange {
+  return NSMakeRange(NSNotFound, 0);
+}
+
+- (NSRange)markedRange {
+  return NSMakeRange(NSNotFound, 0);
+}
+
+- (BOOL)hasMarkedText {
+  return NO;
+}
+
+- (NSAttributedString *)attributedSubstringForProposedRange:(NSRange)range actualRange:(NSRangePointer)actualRange {
+  return nil;
+}
+
+- (NSArray<NSAttributedStringKey> *)validAttributesForMarkedText {
+  return @[];
+}
+
+- (NSRect)firstRectForCharacterRange:(NSRange)range actualRange:(NSRangePointer)actualRange {
+  NSRect rc = _textInputRect;
+  rc.origin.y = self.bounds.size.height - rc.origin.y - rc.size.height;
+  rc = [self convertRect:rc toView:nil];
+  return [self.window convertRectToScreen:rc];
+}
+
+- (NSUInteger)characterIndexForPoint:(NSPoint)point {
+  return 0;
+}
+
 @end // OpenGLView
 
 @implementation ViewController
@@ -201,6 +475,25 @@ static AppDelegate* getAppDelegate() {
     [self.window makeFirstResponder: self.window.contentView];
     self.window.acceptsMouseMovedEvents = YES;
     //printf("%s\n", "windowDidBecomeKey");
+    winl_on_focus(self, 1);
+}
+
+- (void)windowDidResignKey:(NSNotification *)notification {
+    winl_on_focus(self, 0);
+}
+
+// zoomed window is treated as maximized
+- (void)updateState {
+  int state = WINL_STATE_NORMAL;
+  if (self.window.isMiniaturized) {
+    state = WINL_STATE_MINIMIZED;
+  } else if (self.window.isZoomed) {
+    state = WINL_STATE_MAXIMIZED;
+  }
+  if (state != self->_state) {
+    self->_state = state;
+    winl_on_state_change(self, state);
+  }
 }
 
 - (void)windowDidChangeOcclusionState:(NSNotification *)notification {
@@ -215,14 +508,21 @@ static AppDelegate* getAppDelegate() {
   self->_bFirstResize = 1;
   CGSize sz = glview.frame.size;
   winl_on_resize(self, sz.width, sz.height);
+  [self updateState];
 }
 
-- (void)windowDidMiniaturize:(NSNotification *)notification {
+- (void)windowDidMove:(NSNotification *)notification {
+  float x, y;
+  winl_get_position(self, &x, &y);
+  winl_on_move(self, x, y);
+}
 
+- (void)windowDidMiniaturize:(NSNotification *)notification {
+  [self updateState];
 }
 
 - (void)windowDidDeminiaturize:(NSNotification *)notification {
-
+  [self updateState];
 }
 
 - (void)windowDidEnterFullScreen:(NSNotification *)notification {
@@ -254,30 +554,25 @@ int winl_event_loop() {
   return EXIT_SUCCESS;
 }
 
-void winl_exit_loop(int code) {
-  [NSApplication.sharedApplication terminate:nil];
+void winl_wakeup() {
+  // run loop calls idleObserver again before next wait
+  CFRunLoopRef loop = CFRunLoopGetMain();
+  CFRunLoopPerformBlock(loop, kCFRunLoopCommonModes, ^{});
+  CFRunLoopWakeUp(loop);
 }
 
-NSOpenGLContext* findOpenGLContex() {
-  NSArray<NSWindow*>* windows = NSApplication.sharedApplication.windows;
-  OpenGLView *glview = nil;
-  for(NSUInteger i=0; i < windows.count; i++) {
-    id view = windows[i].contentView;
-    if ([view isKindOfClass:[OpenGLView class]]) {
-      glview = view;
-      break;
-    }
-  }
-  if (glview == nil) {
-    return nil;
-  }
-  return glview.openGLContext;
+int winl_is_ui_thread() {
+  return [NSThread isMainThread];
 }
 
-NativeWnd winl_create(int ws, int width, int height) { @autoreleasepool{
+void winl_exit_loop(int code) {
+  [NSApplication.sharedApplication terminate:nil];
+}
 
+// root of share group, never current, so that objects live as long as the application
+NSOpenGLContext* _sharedContext;
 
-  NSOpenGLContext* sharedOpenGLContex = findOpenGLContex();
+NativeWnd winl_create(int ws, int width, int height) { @autoreleasepool{
 
   int sw, sh;
   winl_get_screen_size(&sw, &sh);
@@ -315,14 +610,10 @@ NativeWnd winl_create(int ws, int width, int height) { @autoreleasepool{
 
   OpenGLView* view = [[OpenGLView alloc] initWithFrame: [window frame] pixelFormat: pf];
   // [pf release];
-  if (sharedOpenGLContex != nil) {
-    NSOpenGLContext *ctx = [[NSOpenGLContext alloc]
-                    initWithFormat: pf
-                    shareContext:sharedOpenGLContex];
-    view.openGLContext = ctx;
-  } else {
-    sharedOpenGLContex = view.openGLContext;
+  if (_sharedContext == nil) {
+    _sharedContext = [[NSOpenGLContext alloc] initWithFormat: pf shareContext: nil];
   }
+  view.openGLContext = [[NSOpenGLContext alloc] initWithFormat: pf shareContext: _sharedContext];
 
   ViewController* vc = [[ViewController alloc] initWithNibName: nil bundle: nil];
   vc.view = view;
@@ -338,6 +629,37 @@ NativeWnd winl_create(int ws, int width, int height) { @autoreleasepool{
   return (NativeWnd)wc;
 }}
 
+// Cocoa coordinates are in points, system scales them to pixels of the
+// screen by itself, so content scale is always 1.
+int winl_get_monitors(WinlMonitor* monitors, int max) {
+  NSArray<NSScreen*>* screens = [NSScreen screens];
+  CGFloat primaryHeight = screens.count > 0 ? screens[0].frame.size.height : 0;
+  int n = 0;
+  for (NSScreen* screen in screens) {
+    if (n >= max) {
+      break;
+    }
+    NSRect rc = screen.frame;
+    WinlMonitor* m = monitors + n;
+    m->x = rc.origin.x;
+    m->y = primaryHeight - rc.origin.y - rc.size.height;
+    m->width = rc.size.width;
+    m->height = rc.size.height;
+    CGDirectDisplayID did = [screen.deviceDescription[@"NSScreenNumber"] unsignedIntValue];
+    CGSize mm = CGDisplayScreenSize(did);
+    m->widthMM = mm.width;
+    m->heightMM = mm.height;
+    m->scale = 1;
+    m->primary = n == 0;
+    n++;
+  }
+  return n;
+}
+
+float winl_get_content_scale(NativeWnd win) {
+  return 1;
+}
+
 void winl_get_screen_size(int *width, int *height) {
   NSScreen *mainScreen = [NSScreen mainScreen];
   NSRect screenRect = [mainScreen visibleFrame];
@@ -375,10 +697,30 @@ int winl_make_current(NativeWnd win) {
   return 1;
 }
 
+void* winl_get_context(NativeWnd win) {
+  WindowController* wc = (WindowController*)win;
+  if(!wc) {
+    return NULL;
+  }
+  return (void*)wc->glview.openGLContext;
+}
+
+void* winl_current_context() {
+  return (void*)[NSOpenGLContext currentContext];
+}
+
 void winl_swap_buffers() {
   [[NSOpenGLContext currentContext] flushBuffer];
 }
 
+void winl_set_swap_interval(NativeWnd win, int interval) {
+  WindowController* wc = (WindowController*)win;
+  if (wc) {
+    GLint v = interval;
+    [wc->glview.openGLContext setValues:&v forParameter:NSOpenGLContextParameterSwapInterval];
+  }
+}
+
 
 int winl_is_full_screen(NativeWnd win) {
   WindowController* wc = (WindowController*)win;
@@ -407,6 +749,96 @@ void winl_toggle_full_screen(NativeWnd win) {
 //
 // }
 
+// Cocoa screen coordinates are bottom-left origin of the primary screen
+static CGFloat primaryScreenHeight() {
+    return NSScreen.screens[0].frame.size.height;
+}
+
+void winl_get_position(NativeWnd win, float *x, float *y) {
+    WindowController* wc = (WindowController*)win;
+    NSRect rc = NSZeroRect;
+    if (wc) {
+      rc = [wc.window contentRectForFrameRect: wc.window.frame];
+      rc.origin.y = primaryScreenHeight() - rc.origin.y - rc.size.height;
+    }
+    if (x) {
+      *x = rc.origin.x;
+    }
+    if (y) {
+      *y = rc.origin.y;
+    }
+}
+
+// keep top-left corner of content fixed
+static void setContentRect(NSWindow* w, float x, float y, float width, float height) {
+    NSRect rc = NSMakeRect(x, primaryScreenHeight() - y - height, width, height);
+    [w setFrame: [w frameRectForContentRect: rc] display: YES];
+}
+
+void winl_move(NativeWnd win, float x, float y) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return;
+    }
+    NSRect rc = [wc.window contentRectForFrameRect: wc.window.frame];
+    setContentRect(wc.window, x, y, rc.size.width, rc.size.height);
+}
+
+void winl_resize(NativeWnd win, float width, float height) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return;
+    }
+    float x, y;
+    winl_get_position(win, &x, &y);
+    setContentRect(wc.window, x, y, width, height);
+}
+
+void winl_set_size_limits(NativeWnd win, float minWidth, float minHeight, float maxWidth, float maxHeight) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return;
+    }
+    wc.window.contentMinSize = NSMakeSize(minWidth, minHeight);
+    wc.window.contentMaxSize = NSMakeSize(maxWidth > 0 ? maxWidth : FLT_MAX, maxHeight > 0 ? maxHeight : FLT_MAX);
+}
+
+void winl_set_state(NativeWnd win, int state) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return;
+    }
+    NSWindow* w = wc.window;
+    switch (state) {
+    case WINL_STATE_MINIMIZED:
+      [w miniaturize: nil];
+      break;
+    case WINL_STATE_MAXIMIZED:
+      if (w.isMiniaturized) {
+        [w deminiaturize: nil];
+      }
+      if (!w.isZoomed) {
+        [w zoom: nil];
+      }
+      break;
+    default:
+      if (w.isMiniaturized) {
+        [w deminiaturize: nil];
+      } else if (w.isZoomed) {
+        [w zoom: nil];
+      }
+    }
+}
+
+void winl_focus(NativeWnd win) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return;
+    }
+    [NSApp activateIgnoringOtherApps: YES];
+    [wc.window makeKeyAndOrderFront: nil];
+}
+
 void winl_set_title(NativeWnd win, const char * title) {
     WindowController* wc = (WindowController*)win;
     if (wc) {
@@ -416,16 +848,192 @@ void winl_set_title(NativeWnd win, const char * title) {
     }
 }
 
+// macOS has no icon per window, it sets the dock icon of application
+void winl_set_icon(NativeWnd win, int count, const int* sizes, const unsigned char* rgba) {
+    if (count == 0) {
+      [NSApp setApplicationIconImage: nil];
+      return;
+    }
+    NSImage* image = [[NSImage alloc] initWithSize: NSMakeSize(sizes[0], sizes[1])];
+    for (int i = 0; i < count; i++) {
+      int w = sizes[i*2], h = sizes[i*2+1];
+      NSBitmapImageRep* rep = [[NSBitmapImageRep alloc]
+        initWithBitmapDataPlanes: NULL
+        pixelsWide: w
+        pixelsHigh: h
+        bitsPerSample: 8
+        samplesPerPixel: 4
+        hasAlpha: YES
+        isPlanar: NO
+        colorSpaceName: NSDeviceRGBColorSpace
+        bitmapFormat: NSBitmapFormatAlphaNonpremultiplied
+        bytesPerRow: w * 4
+        bitsPerPixel: 32];
+      memcpy([rep bitmapData], rgba, w * h * 4);
+      rgba += w * h * 4;
+      [image addRepresentation: rep];
+      [rep release];
+    }
+    [NSApp setApplicationIconImage: image];
+    [image release];
+}
+
+// bounces the dock icon once, until application is activated
+void winl_request_attention(NativeWnd win) {
+    if (![NSApp isActive]) {
+      [NSApp requestUserAttention: NSInformationalRequest];
+    }
+}
+
+// percentage in badge of dock icon
+void winl_set_progress(NativeWnd win, float fraction) {
+    NSDockTile* tile = [NSApp dockTile];
+    if (fraction < 0) {
+      [tile setBadgeLabel: nil];
+    } else {
+      [tile setBadgeLabel: [NSString stringWithFormat: @"%d%%", (int)(fraction * 100)]];
+    }
+}
+
 void winl_expose(NativeWnd win, float x, float y, float width, float height) {
     WindowController* wc = (WindowController*)win;
     if (!wc) {
       return;
     }
-    y =  wc->glview.bounds.size.height - y;
+    y = wc->glview.bounds.size.height - y - height;
     NSRect invalidRect = NSMakeRect(x, y, width, height);
     [wc->glview setNeedsDisplayInRect: invalidRect];
 }
 
+// Cocoa keeps sending drag events to the view during a drag, nothing to capture
+int winl_grab_mouse(NativeWnd win, int grab) {
+    return win != NULL;
+}
+
+int winl_set_relative_mouse(NativeWnd win, int enable) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return 0;
+    }
+    OpenGLView* view = wc->glview;
+    if (view->_relative == (enable != 0)) {
+      return 1;
+    }
+    view->_relative = enable != 0;
+    CGAssociateMouseAndMouseCursorPosition(enable ? false : true);
+    if (enable) {
+      [NSCursor hide];
+    } else {
+      [NSCursor unhide];
+    }
+    return 1;
+}
+
+static NSCursor* _hiddenCursor;
+
+static void applyCursor(WindowController* wc, NSCursor* cursor) {
+    OpenGLView* view = wc->glview;
+    [cursor retain];
+    [view->_cursor release];
+    view->_cursor = cursor;
+    [wc.window invalidateCursorRectsForView: view];
+}
+
+void winl_set_cursor(NativeWnd win, int shape) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return;
+    }
+    NSCursor* cursor = [NSCursor arrowCursor];
+    switch (shape) {
+    case WINL_CURSOR_IBEAM: cursor = [NSCursor IBeamCursor]; break;
+    case WINL_CURSOR_HRESIZE: cursor = [NSCursor resizeLeftRightCursor]; break;
+    case WINL_CURSOR_VRESIZE: cursor = [NSCursor resizeUpDownCursor]; break;
+    case WINL_CURSOR_HAND: cursor = [NSCursor pointingHandCursor]; break;
+    case WINL_CURSOR_CROSSHAIR: cursor = [NSCursor crosshairCursor]; break;
+    case WINL_CURSOR_HIDDEN:
+      if (!_hiddenCursor) {
+        // transparent image
+        NSImage* img = [[NSImage alloc] initWithSize: NSMakeSize(16, 16)];
+        _hiddenCursor = [[NSCursor alloc] initWithImage:img hotSpot:NSZeroPoint];
+        [img release];
+      }
+      cursor = _hiddenCursor;
+      break;
+    }
+    applyCursor(wc, cursor);
+}
+
+WinlCursor winl_create_cursor(const unsigned char* rgba, int width, int height, int hotX, int hotY) {
+    NSBitmapImageRep* rep = [[NSBitmapImageRep alloc]
+      initWithBitmapDataPlanes: NULL
+      pixelsWide: width
+      pixelsHigh: height
+      bitsPerSample: 8
+      samplesPerPixel: 4
+      hasAlpha: YES
+      isPlanar: NO
+      colorSpaceName: NSCalibratedRGBColorSpace
+      bitmapFormat: NSBitmapFormatAlphaNonpremultiplied
+      bytesPerRow: width * 4
+      bitsPerPixel: 32];
+    if (!rep) {
+      return 0;
+    }
+    memcpy(rep.bitmapData, rgba, width * height * 4);
+    NSImage* img = [[NSImage alloc] initWithSize: NSMakeSize(width, height)];
+    [img addRepresentation: rep];
+    NSCursor* cursor = [[NSCursor alloc] initWithImage:img hotSpot:NSMakePoint(hotX, hotY)];
+    [img release];
+    [rep release];
+    return (WinlCursor)cursor;
+}
+
+void winl_set_custom_cursor(NativeWnd win, WinlCursor cursor) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc || !cursor) {
+      return;
+    }
+    applyCursor(wc, (NSCursor*)cursor);
+}
+
+void winl_destroy_cursor(WinlCursor cursor) {
+    [(NSCursor*)cursor release];
+}
+
+static char* _primaryText; // no PRIMARY selection on macOS, keep in process
+
+void winl_set_clipboard_text(int primary, const char* text) {
+    if (primary) {
+      free(_primaryText);
+      _primaryText = strdup(text);
+      return;
+    }
+    NSPasteboard* pb = [NSPasteboard generalPasteboard];
+    NSString* s = [[NSString alloc] initWithUTF8String: text];
+    [pb clearContents];
+    [pb setString:s forType:NSPasteboardTypeString];
+    [s release];
+}
+
+char* winl_get_clipboard_text(int primary) {
+    if (primary) {
+      return _primaryText ? strdup(_primaryText) : NULL;
+    }
+    NSString* s = [[NSPasteboard generalPasteboard] stringForType:NSPasteboardTypeString];
+    if (!s) {
+      return NULL;
+    }
+    return strdup([s UTF8String]);
+}
+
+void winl_set_text_input_rect(NativeWnd win, float x, float y, float width, float height) {
+    WindowController* wc = (WindowController*)win;
+    if (!wc) {
+      return;
+    }
+    wc->glview->_textInputRect = NSMakeRect(x, y, width, height);
+}
 
 static int messasge_box(WindowController* wc, const char* msg, const char* title, int confirm) {
 	NSString* ms = [[NSString alloc] initWithUTF8String: msg];
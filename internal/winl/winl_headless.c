@@ -0,0 +1,537 @@
+// +build linux,headless
+
+// headless backend, windows are EGL pbuffers without display server.
+// it is for running tests on CI machines, i.e. Mesa llvmpipe.
+// there is no input from user, events are generated by the API calls.
+
+#include <stdlib.h>
+#include <stdio.h>
+#include <unistd.h>
+#include <string.h>
+#include <fcntl.h>
+#include <poll.h>
+#include <pthread.h>
+#include <sys/utsname.h>
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+#include "winl-c.h"
+
+#ifndef EGL_PLATFORM_SURFACELESS_MESA
+#define EGL_PLATFORM_SURFACELESS_MESA 0x31DD
+#endif
+
+#define MIN(a, b) ((a) < (b) ? (a) : (b))
+#define MAX(a, b) ((a) > (b) ? (a) : (b))
+
+// size of the virtual screen
+#define SCREEN_WIDTH  1920
+#define SCREEN_HEIGHT 1080
+
+typedef struct NativeWndData {
+  EGLSurface surface;
+  EGLContext context; // shares objects with _eglContext
+  int x, y;
+  int width, height;
+  int minWidth, minHeight;
+  int maxWidth, maxHeight;
+  int visible;
+  int fullScreen;
+  int state; // WINL_STATE_*
+  struct {
+    float l, t, r, b;
+  } dirty;
+  float inputRect[4]; // set by winl_set_text_input_rect
+  int grabbed; // set by winl_grab_mouse
+} NativeWndData;
+
+EGLDisplay _eglDisplay = EGL_NO_DISPLAY;
+EGLConfig _eglConfig;
+EGLContext _eglContext = EGL_NO_CONTEXT; // root of share group, never current
+
+// NativeWnd is index + 1
+NativeWndData** _windows;
+int _windowCap;
+int _windowCount;
+NativeWnd _focused;
+NativeWnd _current;
+
+int _toExit;
+int _exitCode;
+pthread_t _uiThread;
+int _wakePipe[2] = {-1, -1}; // winl_wakeup writes to [1], event loop polls [0]
+char* _selText[2]; // clipboard and primary selection, in process only
+
+static void initEGL() {
+  if (_eglDisplay != EGL_NO_DISPLAY) {
+    return;
+  }
+  PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay =
+    (PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+  if (getPlatformDisplay) {
+    _eglDisplay = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+  }
+  if (_eglDisplay == EGL_NO_DISPLAY) {
+    _eglDisplay = eglGetDisplay(EGL_DEFAULT_DISPLAY);
+  }
+  EGLint major, minor;
+  if (_eglDisplay == EGL_NO_DISPLAY || !eglInitialize(_eglDisplay, &major, &minor)) {
+    winl_printf("%s\n", "fatal error: failed to initialize EGL.");
+    exit(1);
+  }
+  EGLint attrs[] = {
+    EGL_SURFACE_TYPE, EGL_PBUFFER_BIT,
+    EGL_RENDERABLE_TYPE, EGL_OPENGL_BIT,
+    EGL_RED_SIZE, 8,
+    EGL_GREEN_SIZE, 8,
+    EGL_BLUE_SIZE, 8,
+    EGL_ALPHA_SIZE, 8,
+    EGL_NONE
+  };
+  EGLint n = 0;
+  if (!eglChooseConfig(_eglDisplay, attrs, &_eglConfig, 1, &n) || n == 0) {
+    winl_printf("%s\n", "fatal error: no EGL config for OpenGL pbuffer.");
+    exit(1);
+  }
+  eglBindAPI(EGL_OPENGL_API);
+  _eglContext = eglCreateContext(_eglDisplay, _eglConfig, EGL_NO_CONTEXT, NULL);
+  if (_eglContext == EGL_NO_CONTEXT) {
+    winl_printf("%s\n", "fatal error: failed to create OpenGL context.");
+    exit(1);
+  }
+
+  _uiThread = pthread_self();
+  if (pipe(_wakePipe) == 0) {
+    for (int i = 0; i < 2; i++) {
+      fcntl(_wakePipe[i], F_SETFL, fcntl(_wakePipe[i], F_GETFL) | O_NONBLOCK);
+      fcntl(_wakePipe[i], F_SETFD, FD_CLOEXEC);
+    }
+  } else {
+    _wakePipe[0] = _wakePipe[1] = -1;
+  }
+}
+
+static NativeWndData* getWndData(NativeWnd win) {
+  if (win <= 0 || win > _windowCap) {
+    return NULL;
+  }
+  return _windows[win - 1];
+}
+
+static EGLSurface createSurface(int width, int height) {
+  EGLint attrs[] = {EGL_WIDTH, MAX(width, 1), EGL_HEIGHT, MAX(height, 1), EGL_NONE};
+  return eglCreatePbufferSurface(_eglDisplay, _eglConfig, attrs);
+}
+
+// pbuffer can't be resized, replace it with a new one
+static void setSize(NativeWnd win, NativeWndData* wd, int width, int height) {
+  if (wd->minWidth > 0) {
+    width = MAX(width, wd->minWidth);
+  }
+  if (wd->minHeight > 0) {
+    height = MAX(height, wd->minHeight);
+  }
+  if (wd->maxWidth > 0) {
+    width = MIN(width, wd->maxWidth);
+  }
+  if (wd->maxHeight > 0) {
+    height = MIN(height, wd->maxHeight);
+  }
+  if (width == wd->width && height == wd->height) {
+    return;
+  }
+  EGLSurface old = wd->surface;
+  wd->surface = createSurface(width, height);
+  wd->width = width;
+  wd->height = height;
+  if (_current == win) {
+    eglMakeCurrent(_eglDisplay, wd->surface, wd->surface, wd->context);
+  }
+  eglDestroySurface(_eglDisplay, old);
+  winl_on_resize(win, width, height);
+}
+
+static void dispatchExpose() {
+  for (int i = 0; i < _windowCap; i++) {
+    NativeWndData* wd = _windows[i];
+    if (wd && wd->visible && (wd->dirty.l != wd->dirty.r || wd->dirty.t != wd->dirty.b)) {
+      float l = wd->dirty.l, t = wd->dirty.t, r = wd->dirty.r, b = wd->dirty.b;
+      wd->dirty.r = wd->dirty.l = wd->dirty.b = wd->dirty.t = 0;
+      winl_on_expose(i + 1, l, t, r - l, b - t);
+    }
+  }
+}
+
+void winl_get_screen_size(int *width, int *height) {
+  if (width) {
+    *width = SCREEN_WIDTH;
+  }
+  if (height) {
+    *height = SCREEN_HEIGHT;
+  }
+}
+
+int winl_get_monitors(WinlMonitor* monitors, int max) {
+  if (max <= 0) {
+    return 0;
+  }
+  // 96 DPI
+  monitors[0].x = 0;
+  monitors[0].y = 0;
+  monitors[0].width = SCREEN_WIDTH;
+  monitors[0].height = SCREEN_HEIGHT;
+  monitors[0].widthMM = SCREEN_WIDTH * 254 / 960;
+  monitors[0].heightMM = SCREEN_HEIGHT * 254 / 960;
+  monitors[0].scale = 1;
+  monitors[0].primary = 1;
+  return 1;
+}
+
+float winl_get_content_scale(NativeWnd win) {
+  return 1;
+}
+
+NativeWnd winl_create(int ws, int width, int height) {
+  initEGL();
+  if (width <= 0) {
+    width = SCREEN_WIDTH / 2;
+  }
+  if (height <= 0) {
+    height = SCREEN_HEIGHT / 2;
+  }
+  NativeWndData* wd = malloc(sizeof(NativeWndData));
+  memset(wd, 0, sizeof(NativeWndData));
+  wd->surface = createSurface(width, height);
+  if (wd->surface == EGL_NO_SURFACE) {
+    free(wd);
+    return 0;
+  }
+  wd->context = eglCreateContext(_eglDisplay, _eglConfig, _eglContext, NULL);
+  if (wd->context == EGL_NO_CONTEXT) {
+    eglDestroySurface(_eglDisplay, wd->surface);
+    free(wd);
+    return 0;
+  }
+  wd->x = (SCREEN_WIDTH - width) / 2;
+  wd->y = (SCREEN_HEIGHT - height) / 2;
+  wd->width = width;
+  wd->height = height;
+  if (!(ws & WINL_HINT_RESIZABLE)) {
+    wd->minWidth = wd->maxWidth = width;
+    wd->minHeight = wd->maxHeight = height;
+  }
+
+  _windows = realloc(_windows, sizeof(NativeWndData*) * (_windowCap + 1));
+  _windows[_windowCap++] = wd;
+  _windowCount++;
+  return _windowCap;
+}
+
+void winl_show(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  if (!wd || wd->visible) {
+    return;
+  }
+  // what a window manager does when the window is mapped
+  wd->visible = 1;
+  winl_on_resize(win, wd->width, wd->height);
+  winl_expose(win, 0, 0, wd->width, wd->height);
+  winl_focus(win);
+}
+
+void winl_destroy(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  if (!wd) {
+    return;
+  }
+  if (_focused == win) {
+    _focused = 0;
+  }
+  winl_on_destroy(win);
+  if (_current == win) {
+    winl_make_current(0);
+  }
+  eglDestroyContext(_eglDisplay, wd->context);
+  eglDestroySurface(_eglDisplay, wd->surface);
+  free(wd);
+  _windows[win - 1] = NULL;
+  _windowCount--;
+}
+
+int winl_make_current(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  if (wd) {
+    _current = win;
+    return eglMakeCurrent(_eglDisplay, wd->surface, wd->surface, wd->context);
+  }
+  _current = 0;
+  return eglMakeCurrent(_eglDisplay, EGL_NO_SURFACE, EGL_NO_SURFACE, EGL_NO_CONTEXT);
+}
+
+void* winl_get_context(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  return wd ? wd->context : NULL;
+}
+
+void* winl_current_context() {
+  EGLContext ctx = eglGetCurrentContext();
+  return ctx == EGL_NO_CONTEXT ? NULL : ctx;
+}
+
+void winl_swap_buffers(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  if (wd) {
+    eglSwapBuffers(_eglDisplay, wd->surface);
+  }
+}
+
+void winl_set_swap_interval(NativeWnd win, int interval) {
+  eglSwapInterval(_eglDisplay, interval);
+}
+
+void winl_get_size(NativeWnd win, float *width, float *height) {
+  NativeWndData* wd = getWndData(win);
+  if (width) {
+    *width = wd ? wd->width : 0;
+  }
+  if (height) {
+    *height = wd ? wd->height : 0;
+  }
+}
+
+void winl_get_position(NativeWnd win, float *x, float *y) {
+  NativeWndData* wd = getWndData(win);
+  if (x) {
+    *x = wd ? wd->x : 0;
+  }
+  if (y) {
+    *y = wd ? wd->y : 0;
+  }
+}
+
+void winl_move(NativeWnd win, float x, float y) {
+  NativeWndData* wd = getWndData(win);
+  if (!wd || (wd->x == (int)x && wd->y == (int)y)) {
+    return;
+  }
+  wd->x = (int)x;
+  wd->y = (int)y;
+  winl_on_move(win, wd->x, wd->y);
+}
+
+void winl_resize(NativeWnd win, float width, float height) {
+  NativeWndData* wd = getWndData(win);
+  if (wd) {
+    setSize(win, wd, (int)width, (int)height);
+  }
+}
+
+void winl_set_size_limits(NativeWnd win, float minWidth, float minHeight, float maxWidth, float maxHeight) {
+  NativeWndData* wd = getWndData(win);
+  if (!wd) {
+    return;
+  }
+  wd->minWidth = (int)minWidth;
+  wd->minHeight = (int)minHeight;
+  wd->maxWidth = (int)maxWidth;
+  wd->maxHeight = (int)maxHeight;
+  setSize(win, wd, wd->width, wd->height);
+}
+
+int winl_is_full_screen(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  return wd ? wd->fullScreen : 0;
+}
+
+void winl_toggle_full_screen(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  if (wd) {
+    wd->fullScreen = !wd->fullScreen;
+  }
+}
+
+int winl_is_visible(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  return wd ? wd->visible : 0;
+}
+
+void winl_set_state(NativeWnd win, int state) {
+  NativeWndData* wd = getWndData(win);
+  if (!wd || wd->state == state) {
+    return;
+  }
+  wd->state = state;
+  winl_on_state_change(win, state);
+}
+
+void winl_focus(NativeWnd win) {
+  if (!getWndData(win) || _focused == win) {
+    return;
+  }
+  NativeWnd old = _focused;
+  _focused = win;
+  if (old) {
+    winl_on_focus(old, 0);
+  }
+  winl_on_focus(win, 1);
+}
+
+void winl_set_title(NativeWnd win, const char * title) {
+}
+
+void winl_set_icon(NativeWnd win, int count, const int* sizes, const unsigned char* rgba) {
+}
+
+void winl_request_attention(NativeWnd win) {
+}
+
+void winl_set_progress(NativeWnd win, float fraction) {
+}
+
+void winl_expose(NativeWnd win, float x, float y, float width, float height) {
+  NativeWndData* wd = getWndData(win);
+  if (!wd) {
+    return;
+  }
+  if (wd->dirty.l == wd->dirty.r || wd->dirty.t == wd->dirty.b) {
+    wd->dirty.l = x;
+    wd->dirty.t = y;
+    wd->dirty.r = x + width;
+    wd->dirty.b = y + height;
+    return;
+  }
+  wd->dirty.l = MIN(x, wd->dirty.l);
+  wd->dirty.t = MIN(y, wd->dirty.t);
+  wd->dirty.r = MAX(x+width, wd->dirty.r);
+  wd->dirty.b = MAX(y+height, wd->dirty.b);
+}
+
+int winl_grab_mouse(NativeWnd win, int grab) {
+  NativeWndData* wd = getWndData(win);
+  if (!wd) {
+    return 0;
+  }
+  wd->grabbed = grab;
+  return 1;
+}
+
+// winl_headless_grabbed reports whether mouse is grabbed by winl_grab_mouse
+int winl_headless_grabbed(NativeWnd win) {
+  NativeWndData* wd = getWndData(win);
+  return wd && wd->grabbed;
+}
+
+int winl_set_relative_mouse(NativeWnd win, int enable) {
+  return getWndData(win) != NULL;
+}
+
+void winl_set_cursor(NativeWnd win, int shape) {
+}
+
+WinlCursor winl_create_cursor(const unsigned char* rgba, int width, int height, int hotX, int hotY) {
+  // no image is needed, but the handle must be unique
+  return (WinlCursor)malloc(1);
+}
+
+void winl_set_custom_cursor(NativeWnd win, WinlCursor cursor) {
+}
+
+void winl_destroy_cursor(WinlCursor cursor) {
+  free((void*)cursor);
+}
+
+void winl_set_clipboard_text(int primary, const char* text) {
+  int i = primary ? 1 : 0;
+  free(_selText[i]);
+  _selText[i] = (text && text[0]) ? strdup(text) : NULL;
+}
+
+char* winl_get_clipboard_text(int primary) {
+  int i = primary ? 1 : 0;
+  return _selText[i] ? strdup(_selText[i]) : NULL;
+}
+
+void winl_set_text_input_rect(NativeWnd win, float x, float y, float width, float height) {
+  NativeWndData* wd = getWndData(win);
+  if (wd) {
+    wd->inputRect[0] = x;
+    wd->inputRect[1] = y;
+    wd->inputRect[2] = width;
+    wd->inputRect[3] = height;
+  }
+}
+
+// winl_headless_text_input_rect gets the rect set by winl_set_text_input_rect
+void winl_headless_text_input_rect(NativeWnd win, float* rect) {
+  NativeWndData* wd = getWndData(win);
+  if (wd) {
+    memcpy(rect, wd->inputRect, sizeof(wd->inputRect));
+  }
+}
+
+// winl_headless_button dispatch X11 button event as if it is from display server
+void winl_headless_button(NativeWnd win, unsigned int button, int press, float x, float y) {
+  if (getWndData(win)) {
+    winl_on_x_button(win, button, press, x, y);
+  }
+}
+
+void winl_wakeup() {
+  if (_wakePipe[1] >= 0) {
+    char c = 0;
+    // pipe is full if failed, the loop will wake up anyway
+    if (write(_wakePipe[1], &c, 1) < 0) {
+    }
+  }
+}
+
+int winl_is_ui_thread() {
+  return pthread_equal(pthread_self(), _uiThread);
+}
+
+int winl_event_loop() {
+  initEGL();
+  winl_on_start();
+
+  // the window created by winl.init() is alive
+  while (!_toExit && _windowCount > 1) {
+    dispatchExpose();
+    int timeout = winl_on_idle();
+    if (_toExit) {
+      break;
+    }
+    struct pollfd pfd = {_wakePipe[0], POLLIN, 0};
+    if (poll(&pfd, _wakePipe[0] < 0 ? 0 : 1, timeout) > 0) {
+      char buf[64];
+      while (read(_wakePipe[0], buf, sizeof(buf)) > 0) {
+      }
+    }
+  }
+  return _exitCode;
+}
+
+void winl_exit_loop(int code) {
+  _toExit = 1;
+  _exitCode = code;
+  winl_wakeup();
+}
+
+void winl_message_box(NativeWnd win, const char* msg, const char* title) {
+  winl_printf("message box: %s: %s\n", title, msg);
+}
+
+int winl_confirm_box(NativeWnd win, const char* msg, const char* title) {
+  winl_printf("confirm box: %s: %s\n", title, msg);
+  return 0;
+}
+
+char* winl_os_version() {
+  char* buf = (char*)malloc(4096);
+  struct utsname x;
+  if (0 == uname(&x)) {
+    sprintf(buf, "%s %s %s (headless)", x.sysname, x.release, x.version);
+  } else {
+    buf[0] = 0;
+    strcat(buf, "Linux unkown (headless)");
+  }
+  return buf;
+}
@@ -1,11 +1,24 @@
+// +build !headless
+
 #include <stdlib.h>
 #include <stdio.h>
 #include <unistd.h>
 #include <errno.h>
 #include <string.h>
+#include <locale.h>
+#include <limits.h>
+#include <poll.h>
+#include <dlfcn.h>
+#include <fcntl.h>
+#include <pthread.h>
 #include <sys/utsname.h>
 #include <X11/Xatom.h>
 #include <X11/Xlib.h>
+#include <X11/Xresource.h>
+#include <X11/XKBlib.h>
+#include <X11/keysym.h>
+#include <X11/cursorfont.h>
+#include <X11/extensions/Xrender.h>
 #include <GL/gl.h>
 #include <GL/glu.h>
 #define GLX_GLXEXT_LEGACY
@@ -14,7 +27,8 @@
 
 
 static XIM _xim = 0;
-static XIC _xic = 0;
+static XIMStyle _ximStyle = 0;
+static XFontSet _ximFontSet = NULL;
 Display * _display = 0;
 int _screenNum = 0;
 Colormap _screenColormap;
@@ -27,6 +41,9 @@ Atom _atom_UTF8_STRING;
 Atom _atom_CLIPBOARD;
 //Atom _atom_E_SELECTION;
 Atom _atom_TARGETS;
+Atom _atom_TEXT;
+Atom _atom_INCR;
+Atom _atom_WINL_SELECTION;
 Atom _atom_NET_WM_NAME;
 Atom _atom_NET_WM_PING;
 Atom _atom_NET_WM_ICON;
@@ -44,13 +61,76 @@ Atom _atom_NET_WM_STATE_FOCUSED;
 Atom _atom_NET_WM_STATE_SHADED;
 Atom _atom_NET_WM_STATE_HIDDEN;
 Atom _atom_NET_WM_STATE_FULLSCREEN;
+Atom _atom_NET_ACTIVE_WINDOW;
+Atom _atom_NET_WM_STATE_DEMANDS_ATTENTION;
+Atom _atom_WM_STATE;
+Atom _atom_XdndAware;
+Atom _atom_XdndEnter;
+Atom _atom_XdndPosition;
+Atom _atom_XdndStatus;
+Atom _atom_XdndLeave;
+Atom _atom_XdndDrop;
+Atom _atom_XdndFinished;
+Atom _atom_XdndActionCopy;
+Atom _atom_XdndSelection;
+Atom _atom_XdndTypeList;
+Atom _atom_text_uri_list;
 
 XContext _wdContext;
 
-GLXContext hGL;
+GLXContext hGL; // root of share group, never current
+XVisualInfo* _visinfo;
 int _windowCount;
 int _toExit;
 int _exitCode;
+Bool _detectableAutoRepeat;
+float _contentScale = 1; // from Xft.dpi, X11 has no per-monitor scale
+pthread_t _uiThread;
+int _wakePipe[2] = {-1, -1}; // winl_wakeup writes to [1], event loop polls [0]
+
+// Xrandr is loaded at runtime, monitors fallback to the whole screen without it
+typedef struct {
+  Atom name;
+  Bool primary;
+  Bool automatic;
+  int noutput;
+  int x, y, width, height;
+  int mwidth, mheight;
+  XID *outputs;
+} _XRRMonitorInfo;
+
+_XRRMonitorInfo* (*_XRRGetMonitors)(Display *dpy, Window window, Bool get_active, int *nmonitors);
+void (*_XRRFreeMonitors)(_XRRMonitorInfo *monitors);
+
+// selections are owned by a hidden window, it also receives converted selections
+Window _clipWindow;
+char* _selText[2]; // owned text of PRIMARY and CLIPBOARD, UTF-8
+
+// pending INCR transfer to other client
+typedef struct IncrTransfer {
+  Window requestor;
+  Atom property;
+  Atom type;
+  char* data;
+  size_t size;
+  size_t offset;
+  struct IncrTransfer* next;
+} IncrTransfer;
+
+IncrTransfer* _incrList;
+
+// XDND session, only one drag at a time
+typedef struct DndState {
+  Window source; // 0 if not dragging
+  Window target;
+  int version;
+  Atom format; // text/uri-list, None if source doesn't offer it
+  int entered; // winl_on_drag_enter is called
+  int accepted;
+  float x, y; // last position in target
+} DndState;
+
+DndState _dnd;
 
 #ifndef MIN
 # define MIN(x, y)  ((x) < (y) ? (x) : (y))
@@ -69,6 +149,49 @@ static Atom newAtom(const char* name) {
   return XInternAtom(_display, name, False);
 }
 
+// Xft.dpi in RESOURCE_MANAGER of root window, set by desktop environment
+static float readContentScale() {
+  Atom type;
+  int format;
+  unsigned long count, remain;
+  unsigned char* data = NULL;
+  float dpi = 96;
+  if(XGetWindowProperty(_display, RootWindow(_display, _screenNum), XA_RESOURCE_MANAGER, 0, LONG_MAX/4, False,
+      XA_STRING, &type, &format, &count, &remain, &data) == Success && data) {
+    XrmDatabase db = XrmGetStringDatabase((char*)data);
+    if(db) {
+      char* rtype = NULL;
+      XrmValue value;
+      if(XrmGetResource(db, "Xft.dpi", "Xft.Dpi", &rtype, &value) && rtype && strcmp(rtype, "String") == 0) {
+        dpi = atof(value.addr);
+      }
+      XrmDestroyDatabase(db);
+    }
+    XFree(data);
+  }
+  if(dpi <= 0) {
+    dpi = 96;
+  }
+  return dpi / 96;
+}
+
+static void loadXrandr() {
+  void* h = dlopen("libXrandr.so.2", RTLD_LAZY | RTLD_LOCAL);
+  if(!h) {
+    h = dlopen("libXrandr.so", RTLD_LAZY | RTLD_LOCAL);
+  }
+  if(!h) {
+    winl_printf("%s\n", "warning: libXrandr not found, multiple monitors is not supported.");
+    return;
+  }
+  _XRRGetMonitors = dlsym(h, "XRRGetMonitors");
+  _XRRFreeMonitors = dlsym(h, "XRRFreeMonitors");
+  if(!_XRRGetMonitors || !_XRRFreeMonitors) {
+    _XRRGetMonitors = NULL;
+    _XRRFreeMonitors = NULL;
+  }
+}
+
 static void _InitXLib()
 {
   if(_display != 0) {
@@ -82,102 +205,57 @@ static void _InitXLib()
   _screenNum = DefaultScreen(_display);
   _screenColormap = DefaultColormap(_display, DefaultScreen(_display));
 
+  // input methods depends on the locale, which is "C" until we set it
+  if(setlocale(LC_CTYPE, "") == NULL || !XSupportsLocale()) {
+    winl_printf("%s\n", "warning: locale not supported by Xlib, input method disabled.");
+  }
   //XSetLocaleModifiers("@im=SCIM");
   XSetLocaleModifiers("");
   _xim = XOpenIM(_display, NULL, NULL, NULL);
 
   if(_xim)
   {
-    Bool fl_is_over_the_spot = False;
-    XFontSet fontSet = NULL;
-    XRectangle status_area;
-    {
-      char **missing_list;
-      int missing_count;
-      char *def_string;
-      fontSet = XCreateFontSet(_display, "-misc-fixed-medium-r-normal--14-*", &missing_list, &missing_count, &def_string);
-    }
-
     XIMStyles* xim_styles = NULL;
     if(XGetIMValues(_xim, XNQueryInputStyle, &xim_styles, NULL, NULL) || !xim_styles || !xim_styles->count_styles)
     {
-      //hx_warning("No XIM style found\n");
-      //assert(0);
       winl_printf("%s\n", "warning: XGetIMValues() failed.");
     }
     else
     {
-      //hx_trace("[e_ui] 1111111111111111.");
-      Bool predit = False;
-      Bool statusArea = False;
+      // prefer over-the-spot, the candidate window follows the caret
       for(int i = 0; i < xim_styles->count_styles; ++i)
       {
-        XIMStyle* style = xim_styles->supported_styles+i;
-        if(*style == (XIMPreeditPosition | XIMStatusArea))
+        XIMStyle style = xim_styles->supported_styles[i];
+        if(style == (XIMPreeditPosition | XIMStatusNothing))
         {
-          statusArea = True;
-          predit = True;
+          _ximStyle = style;
+          break;
         }
-        else if(*style == (XIMPreeditPosition | XIMStatusNothing))
+        if(style == (XIMPreeditNothing | XIMStatusNothing))
         {
-          predit = True;
+          _ximStyle = style;
         }
       }
-
       XFree(xim_styles);
-
-      if(predit)
-      {
-        //hx_trace("[e_ui] XCreateIC() predit == True.");
-        XPoint	spot;
-        spot.x = 0;
-        spot.y = 0;
-        XVaNestedList preedit_attr = XVaCreateNestedList(0, XNSpotLocation, &spot, XNFontSet, fontSet, NULL);
-
-        if(statusArea)
-        {
-          //hx_trace("[e_ui] XCreateIC() statusArea.");
-          fprintf(stdout, "%s\n", "XCreateIC() statusArea.");
-          XVaNestedList status_attr = XVaCreateNestedList(0,
-              XNAreaNeeded, &status_area,
-              XNFontSet, fontSet, NULL);
-          _xic = XCreateIC(_xim,
-              XNInputStyle, (XIMPreeditPosition | XIMStatusArea),
-              XNPreeditAttributes, preedit_attr,
-              XNStatusAttributes, status_attr,
-              NULL);
-          XFree(status_attr);
-        }
-        _xic = XCreateIC(_xim, XNInputStyle,XIMPreeditPosition | XIMStatusNothing, XNPreeditAttributes, preedit_attr, NULL);
-        XFree(preedit_attr);
-        if(_xic)
-        {
-          fprintf(stdout, "%s\n", "XCreateIC() predit.");
-          fl_is_over_the_spot = True;
-          XVaNestedList status_attr;
-          status_attr = XVaCreateNestedList(0, XNAreaNeeded, &status_area, NULL);
-          if(status_area.height != 0)
-          {
-            XGetICValues(_xic, XNStatusAttributes, status_attr, NULL);
-          }
-          XFree(status_attr);
-        }
-      }
     }
 
-    if(_xic == 0)
+    if(_ximStyle & XIMPreeditPosition)
     {
-      _xic = XCreateIC(_xim, XNInputStyle, XIMPreeditNothing | XIMStatusNothing, NULL);
+      char **missing_list;
+      int missing_count;
+      char *def_string;
+      _ximFontSet = XCreateFontSet(_display, "-misc-fixed-medium-r-normal--14-*", &missing_list, &missing_count, &def_string);
+      if(missing_list) {
+        XFreeStringList(missing_list);
+      }
     }
-    if(_xic == 0)
+    if(_ximStyle == 0)
     {
-      //assert(0);
-      winl_printf("%s\n", "error: XCreateIC() failed.");
+      _ximStyle = XIMPreeditNothing | XIMStatusNothing;
     }
   }
   else
   {
-    //assert(0);
     winl_printf("%s\n", "error: XOpenIM() failed.");
   }
 
@@ -189,6 +267,9 @@ static void _InitXLib()
   _atom_CLIPBOARD         = getAtom("CLIPBOARD");
 //  _atom_E_SELECTION       = getAtom("E_SELECTION");
   _atom_TARGETS           = getAtom("TARGETS");
+  _atom_TEXT              = newAtom("TEXT");
+  _atom_INCR              = newAtom("INCR");
+  _atom_WINL_SELECTION    = newAtom("WINL_SELECTION");
   _atom_NET_WM_NAME       = getAtom("_NET_WM_NAME");
   _atom_NET_WM_PING       = getAtom("_NET_WM_PING");
   _atom_NET_WM_ICON       = getAtom("_NET_WM_ICON");
@@ -206,15 +287,63 @@ static void _InitXLib()
   _atom_NET_WM_STATE_SHADED = getAtom("_NET_WM_STATE_SHADED");
   _atom_NET_WM_STATE_HIDDEN = getAtom("_NET_WM_STATE_HIDDEN");
   _atom_NET_WM_STATE_FULLSCREEN = getAtom("_NET_WM_STATE_FULLSCREEN");
+  _atom_NET_ACTIVE_WINDOW = newAtom("_NET_ACTIVE_WINDOW");
+  _atom_WM_STATE          = newAtom("WM_STATE");
+  _atom_NET_WM_STATE_DEMANDS_ATTENTION = newAtom("_NET_WM_STATE_DEMANDS_ATTENTION");
+  _atom_XdndAware         = newAtom("XdndAware");
+  _atom_XdndEnter         = newAtom("XdndEnter");
+  _atom_XdndPosition      = newAtom("XdndPosition");
+  _atom_XdndStatus        = newAtom("XdndStatus");
+  _atom_XdndLeave         = newAtom("XdndLeave");
+  _atom_XdndDrop          = newAtom("XdndDrop");
+  _atom_XdndFinished      = newAtom("XdndFinished");
+  _atom_XdndActionCopy    = newAtom("XdndActionCopy");
+  _atom_XdndSelection     = newAtom("XdndSelection");
+  _atom_XdndTypeList      = newAtom("XdndTypeList");
+  _atom_text_uri_list     = newAtom("text/uri-list");
   _wdContext = XUniqueContext();
+
+  // server will not send fake KeyRelease for auto-repeat, if it supports
+  XkbSetDetectableAutoRepeat(_display, True, &_detectableAutoRepeat);
+
+  _clipWindow = XCreateSimpleWindow(_display, RootWindow(_display, _screenNum), 0, 0, 1, 1, 0, 0, 0);
+  XSelectInput(_display, _clipWindow, PropertyChangeMask);
+
+  // watch RESOURCE_MANAGER for Xft.dpi
+  XrmInitialize();
+  XSelectInput(_display, RootWindow(_display, _screenNum), PropertyChangeMask);
+  _contentScale = readContentScale();
+  loadXrandr();
+
+  _uiThread = pthread_self();
+  if(pipe(_wakePipe) == 0) {
+    for(int i = 0; i < 2; i++) {
+      fcntl(_wakePipe[i], F_SETFL, fcntl(_wakePipe[i], F_GETFL) | O_NONBLOCK);
+      fcntl(_wakePipe[i], F_SETFD, FD_CLOEXEC);
+    }
+  } else {
+    _wakePipe[0] = _wakePipe[1] = -1;
+  }
 }
 
 static void _CloseXLib()
 {
-  if(_xic)
+  for(int i = 0; i < 2; i++)
+  {
+    free(_selText[i]);
+    _selText[i] = NULL;
+  }
+  while(_incrList)
+  {
+    IncrTransfer* t = _incrList;
+    _incrList = t->next;
+    free(t->data);
+    free(t);
+  }
+  if(_ximFontSet)
   {
-    XDestroyIC(_xic);
-    _xic = 0;
+    XFreeFontSet(_display, _ximFontSet);
+    _ximFontSet = NULL;
   }
   if(_xim)
   {
@@ -232,9 +361,22 @@ typedef struct NativeWndData {
   int fullScreen: 1;
 //  int trackMouse: 1;
   int visible: 1;
+  int focused: 1;
+  int resizable: 1;
+  int hasPosition: 1; // position is set by program
+  int grabbed: 1; // pointer is grabbed by winl_grab_mouse
+  int relative: 1; // relative mouse mode
+  int urgent: 1; // urgency hint is set by winl_request_attention
+  Cursor cursor; // defined cursor, None for parent's
+  int state; // WINL_STATE_*
+  int x, y; // client area in root window
+  int minWidth, minHeight, maxWidth, maxHeight; // 0 for no limit
   struct {
     float l, t, r, b;
   } dirty;
+  unsigned char keys[32]; // pressed state of key codes, one bit per key
+  XIC xic; // input context, 0 if no input method
+  GLXContext ctx; // shares objects with hGL
 
 } NativeWndData;
 
@@ -246,16 +388,944 @@ static NativeWndData* getWndData(NativeWnd w) {
   return 0;
 }
 
-static int btnNum(unsigned int btn) {
-  if (btn == Button1) {
-    return WINL_MOUSE_BTN_LEFT;
-  } else if(btn == Button3) {
-    return WINL_MOUSE_BTN_RIGHT;
+// addDirty merges area to dirty rect of window, returns True if it was empty
+static Bool addDirty(NativeWndData* wd, float x, float y, float width, float height) {
+  if (!wd) {
+    return False;
   }
-  return 0;
+  if (wd->dirty.l == wd->dirty.r || wd->dirty.t == wd->dirty.b) {
+    wd->dirty.l = x;
+    wd->dirty.t = y;
+    wd->dirty.r = x + width;
+    wd->dirty.b = y + height;
+    return True;
+  }
+  wd->dirty.l = MIN(x, wd->dirty.l);
+  wd->dirty.t = MIN(y, wd->dirty.t);
+  wd->dirty.r = MAX(x+width, wd->dirty.r);
+  wd->dirty.b = MAX(y+height, wd->dirty.b);
+  return False;
+}
+
+static int translateKeySym(KeySym ks) {
+  if (ks >= XK_a && ks <= XK_z) {
+    return WINL_KEY_A + (int)(ks - XK_a);
+  }
+  if (ks >= XK_A && ks <= XK_Z) {
+    return WINL_KEY_A + (int)(ks - XK_A);
+  }
+  if (ks >= XK_0 && ks <= XK_9) {
+    return WINL_KEY_0 + (int)(ks - XK_0);
+  }
+  if (ks >= XK_F1 && ks <= XK_F12) {
+    return WINL_KEY_F1 + (int)(ks - XK_F1);
+  }
+  if (ks >= XK_KP_0 && ks <= XK_KP_9) {
+    return WINL_KEY_KP_0 + (int)(ks - XK_KP_0);
+  }
+  switch (ks) {
+  case XK_space:        return WINL_KEY_SPACE;
+  case XK_apostrophe:   return WINL_KEY_APOSTROPHE;
+  case XK_comma:        return WINL_KEY_COMMA;
+  case XK_minus:        return WINL_KEY_MINUS;
+  case XK_period:       return WINL_KEY_PERIOD;
+  case XK_slash:        return WINL_KEY_SLASH;
+  case XK_semicolon:    return WINL_KEY_SEMICOLON;
+  case XK_equal:        return WINL_KEY_EQUAL;
+  case XK_bracketleft:  return WINL_KEY_LEFT_BRACKET;
+  case XK_backslash:    return WINL_KEY_BACKSLASH;
+  case XK_bracketright: return WINL_KEY_RIGHT_BRACKET;
+  case XK_grave:        return WINL_KEY_GRAVE;
+  case XK_Escape:       return WINL_KEY_ESCAPE;
+  case XK_Return:       return WINL_KEY_ENTER;
+  case XK_Tab:
+  case XK_ISO_Left_Tab: return WINL_KEY_TAB;
+  case XK_BackSpace:    return WINL_KEY_BACKSPACE;
+  case XK_Insert:       return WINL_KEY_INSERT;
+  case XK_Delete:       return WINL_KEY_DELETE;
+  case XK_Right:        return WINL_KEY_RIGHT;
+  case XK_Left:         return WINL_KEY_LEFT;
+  case XK_Down:         return WINL_KEY_DOWN;
+  case XK_Up:           return WINL_KEY_UP;
+  case XK_Page_Up:      return WINL_KEY_PAGE_UP;
+  case XK_Page_Down:    return WINL_KEY_PAGE_DOWN;
+  case XK_Home:         return WINL_KEY_HOME;
+  case XK_End:          return WINL_KEY_END;
+  case XK_Caps_Lock:    return WINL_KEY_CAPS_LOCK;
+  case XK_Scroll_Lock:  return WINL_KEY_SCROLL_LOCK;
+  case XK_Num_Lock:     return WINL_KEY_NUM_LOCK;
+  case XK_Print:        return WINL_KEY_PRINT_SCREEN;
+  case XK_Pause:        return WINL_KEY_PAUSE;
+  case XK_Menu:         return WINL_KEY_MENU;
+  case XK_KP_Decimal:
+  case XK_KP_Separator: return WINL_KEY_KP_DECIMAL;
+  case XK_KP_Divide:    return WINL_KEY_KP_DIVIDE;
+  case XK_KP_Multiply:  return WINL_KEY_KP_MULTIPLY;
+  case XK_KP_Subtract:  return WINL_KEY_KP_SUBTRACT;
+  case XK_KP_Add:       return WINL_KEY_KP_ADD;
+  case XK_KP_Enter:     return WINL_KEY_KP_ENTER;
+  case XK_KP_Equal:     return WINL_KEY_KP_EQUAL;
+  case XK_Shift_L:      return WINL_KEY_LEFT_SHIFT;
+  case XK_Control_L:    return WINL_KEY_LEFT_CONTROL;
+  case XK_Alt_L:
+  case XK_Meta_L:       return WINL_KEY_LEFT_ALT;
+  case XK_Super_L:      return WINL_KEY_LEFT_SUPER;
+  case XK_Shift_R:      return WINL_KEY_RIGHT_SHIFT;
+  case XK_Control_R:    return WINL_KEY_RIGHT_CONTROL;
+  case XK_Alt_R:
+  case XK_Meta_R:
+  case XK_ISO_Level3_Shift: return WINL_KEY_RIGHT_ALT;
+  case XK_Super_R:      return WINL_KEY_RIGHT_SUPER;
+  }
+  return WINL_KEY_UNKNOWN;
+}
+
+static int translateKey(unsigned int keycode) {
+  // keypad keys have digits in the second level, no matter the Num Lock state
+  KeySym ks = XkbKeycodeToKeysym(_display, keycode, 0, 1);
+  if ((ks >= XK_KP_0 && ks <= XK_KP_9) || ks == XK_KP_Decimal ||
+      ks == XK_KP_Separator || ks == XK_KP_Equal || ks == XK_KP_Enter) {
+    return translateKeySym(ks);
+  }
+  return translateKeySym(XkbKeycodeToKeysym(_display, keycode, 0, 0));
+}
+
+static int translateMods(unsigned int state) {
+  int mods = 0;
+  if (state & ShiftMask) {
+    mods |= WINL_MOD_SHIFT;
+  }
+  if (state & ControlMask) {
+    mods |= WINL_MOD_CONTROL;
+  }
+  if (state & Mod1Mask) {
+    mods |= WINL_MOD_ALT;
+  }
+  if (state & Mod4Mask) {
+    mods |= WINL_MOD_SUPER;
+  }
+  if (state & LockMask) {
+    mods |= WINL_MOD_CAPS_LOCK;
+  }
+  if (state & Mod2Mask) {
+    mods |= WINL_MOD_NUM_LOCK;
+  }
+  return mods;
+}
+
+// isKeyDown reports whether key of sym is pressed, by keys of wd
+static int isKeyDown(NativeWndData* wd, KeySym sym) {
+  KeyCode code = XKeysymToKeycode(_display, sym);
+  return code != 0 && (wd->keys[code/8] & (1 << (code%8))) != 0;
+}
+
+// keyMods returns modifiers held after key event, state of X11 is the one before it.
+// keys of wd must be updated by the event.
+static int keyMods(NativeWndData* wd, int key, unsigned int state) {
+  int mods = translateMods(state);
+  int mod = 0, down = 0;
+  switch (key) {
+  case WINL_KEY_LEFT_SHIFT: case WINL_KEY_RIGHT_SHIFT:
+    mod = WINL_MOD_SHIFT;
+    down = isKeyDown(wd, XK_Shift_L) || isKeyDown(wd, XK_Shift_R);
+    break;
+  case WINL_KEY_LEFT_CONTROL: case WINL_KEY_RIGHT_CONTROL:
+    mod = WINL_MOD_CONTROL;
+    down = isKeyDown(wd, XK_Control_L) || isKeyDown(wd, XK_Control_R);
+    break;
+  case WINL_KEY_LEFT_ALT: case WINL_KEY_RIGHT_ALT:
+    mod = WINL_MOD_ALT;
+    down = isKeyDown(wd, XK_Alt_L) || isKeyDown(wd, XK_Alt_R);
+    break;
+  case WINL_KEY_LEFT_SUPER: case WINL_KEY_RIGHT_SUPER:
+    mod = WINL_MOD_SUPER;
+    down = isKeyDown(wd, XK_Super_L) || isKeyDown(wd, XK_Super_R);
+    break;
+  }
+  if (mod) {
+    mods = down ? (mods | mod) : (mods & ~mod);
+  }
+  return mods;
+}
+
+// without detectable auto-repeat, server sends KeyRelease and KeyPress pair
+// with same time stamp for each repeat.
+static Bool isAutoRepeatRelease(XEvent* e) {
+  if (XEventsQueued(_display, QueuedAfterReading) == 0) {
+    return False;
+  }
+  XEvent next;
+  XPeekEvent(_display, &next);
+  return next.type == KeyPress &&
+    next.xkey.window == e->xkey.window &&
+    next.xkey.keycode == e->xkey.keycode &&
+    next.xkey.time == e->xkey.time;
+}
+
+// every window has its own input context, the client window of XIC can't be changed.
+static XIC createIC(Window win) {
+  XIC xic = 0;
+  if(!_xim) {
+    return 0;
+  }
+  if(_ximStyle & XIMPreeditPosition) {
+    XPoint spot = {0, 0};
+    XVaNestedList preedit_attr = XVaCreateNestedList(0, XNSpotLocation, &spot, XNFontSet, _ximFontSet, NULL);
+    xic = XCreateIC(_xim,
+      XNInputStyle, _ximStyle,
+      XNClientWindow, win,
+      XNFocusWindow, win,
+      XNPreeditAttributes, preedit_attr,
+      NULL);
+    XFree(preedit_attr);
+  }
+  if(!xic) {
+    xic = XCreateIC(_xim,
+      XNInputStyle, XIMPreeditNothing | XIMStatusNothing,
+      XNClientWindow, win,
+      XNFocusWindow, win,
+      NULL);
+  }
+  if(!xic) {
+    winl_printf("%s\n", "error: XCreateIC() failed.");
+  }
+  return xic;
+}
+
+// lookup the text typed by KeyPress, report to winl_on_text_input.
+static void lookupText(Window win, XIC xic, XKeyEvent* ke) {
+  char stackBuf[64];
+  char* buf = stackBuf;
+  int len = 0;
+  KeySym keysym;
+  if(xic) {
+    Status status;
+    len = Xutf8LookupString(xic, ke, buf, sizeof(stackBuf) - 1, &keysym, &status);
+    if(status == XBufferOverflow) {
+      buf = malloc(len + 1);
+      len = Xutf8LookupString(xic, ke, buf, len, &keysym, &status);
+    }
+    if(status != XLookupChars && status != XLookupBoth) {
+      len = 0;
+    }
+  } else {
+    // no input method, XLookupString gives Latin-1
+    char latin1[16];
+    int n = XLookupString(ke, latin1, sizeof(latin1), &keysym, NULL);
+    for(int i = 0; i < n; i++) {
+      unsigned char c = latin1[i];
+      if(c < 0x80) {
+        buf[len++] = c;
+      } else {
+        buf[len++] = 0xc0 | (c >> 6);
+        buf[len++] = 0x80 | (c & 0x3f);
+      }
+    }
+  }
+  buf[len] = 0;
+  if(len > 0) {
+    winl_on_text_input(win, buf);
+  }
+  if(buf != stackBuf) {
+    free(buf);
+  }
+}
+
+/*
+ clipboard, ICCCM selection protocol
+*/
+
+static Atom selectionAtom(int primary) {
+  return primary ? XA_PRIMARY : _atom_CLIPBOARD;
+}
+
+static int selectionIndex(Atom selection) {
+  if(selection == XA_PRIMARY) {
+    return 0;
+  }
+  if(selection == _atom_CLIPBOARD) {
+    return 1;
+  }
+  return -1;
+}
+
+// max bytes of one property change, larger data is sent by INCR
+static size_t selectionChunkSize() {
+  long n = XExtendedMaxRequestSize(_display);
+  if(n == 0) {
+    n = XMaxRequestSize(_display);
+  }
+  // n is in 4 bytes unit, use a quarter of max request size
+  return (size_t)MIN(n, 256 * 1024);
+}
+
+static char* latin1ToUtf8(const char* s, size_t len) {
+  char* ret = malloc(len * 2 + 1);
+  size_t n = 0;
+  for(size_t i = 0; i < len; i++) {
+    unsigned char c = s[i];
+    if(c < 0x80) {
+      ret[n++] = c;
+    } else {
+      ret[n++] = 0xc0 | (c >> 6);
+      ret[n++] = 0x80 | (c & 0x3f);
+    }
+  }
+  ret[n] = 0;
+  return ret;
 }
 
-static Bool grabPointer(Window win) {
+// characters not in Latin-1 are replaced by '?'
+static char* utf8ToLatin1(const char* s, size_t* len) {
+  char* ret = malloc(strlen(s) + 1);
+  size_t n = 0;
+  const unsigned char* p = (const unsigned char*)s;
+  while(*p) {
+    unsigned char c = *p++;
+    if(c < 0x80) {
+      ret[n++] = c;
+    } else if((c & 0xe0) == 0xc0 && (*p & 0xc0) == 0x80) {
+      unsigned int r = ((c & 0x1f) << 6) | (*p++ & 0x3f);
+      ret[n++] = r < 0x100 ? r : '?';
+    } else {
+      // skip continuation bytes of long sequence
+      while((*p & 0xc0) == 0x80) {
+        p++;
+      }
+      ret[n++] = '?';
+    }
+  }
+  ret[n] = 0;
+  *len = n;
+  return ret;
+}
+
+static void handleSelectionRequest(XSelectionRequestEvent* req) {
+  XSelectionEvent ev;
+  memset(&ev, 0, sizeof(ev));
+  ev.type = SelectionNotify;
+  ev.display = req->display;
+  ev.requestor = req->requestor;
+  ev.selection = req->selection;
+  ev.target = req->target;
+  ev.time = req->time;
+  ev.property = None;
+
+  // obsolete clients pass None as property
+  Atom property = req->property != None ? req->property : req->target;
+  int idx = selectionIndex(req->selection);
+  const char* text = idx >= 0 ? _selText[idx] : NULL;
+  if(text == NULL) {
+    // we lost the ownership
+  } else if(req->target == _atom_TARGETS) {
+    Atom targets[] = {_atom_TARGETS, _atom_UTF8_STRING, XA_STRING, _atom_TEXT};
+    XChangeProperty(_display, req->requestor, property, XA_ATOM, 32,
+      PropModeReplace, (unsigned char*)targets, sizeof(targets)/sizeof(targets[0]));
+    ev.property = property;
+  } else if(req->target == _atom_UTF8_STRING || req->target == XA_STRING || req->target == _atom_TEXT) {
+    Atom type = _atom_UTF8_STRING;
+    char* data;
+    size_t size;
+    if(req->target == XA_STRING) {
+      type = XA_STRING;
+      data = utf8ToLatin1(text, &size);
+    } else {
+      data = strdup(text);
+      size = strlen(data);
+    }
+    if(size > selectionChunkSize()) {
+      // too large for single request, send by pieces when requestor deletes the property
+      IncrTransfer* t = calloc(1, sizeof(IncrTransfer));
+      t->requestor = req->requestor;
+      t->property = property;
+      t->type = type;
+      t->data = data;
+      t->size = size;
+      t->next = _incrList;
+      _incrList = t;
+      XSelectInput(_display, req->requestor, PropertyChangeMask);
+      long len = (long)size;
+      XChangeProperty(_display, req->requestor, property, _atom_INCR, 32,
+        PropModeReplace, (unsigned char*)&len, 1);
+    } else {
+      XChangeProperty(_display, req->requestor, property, type, 8,
+        PropModeReplace, (unsigned char*)data, (int)size);
+      free(data);
+    }
+    ev.property = property;
+  }
+  XSendEvent(_display, req->requestor, False, NoEventMask, (XEvent*)&ev);
+}
+
+static void handleSelectionClear(XSelectionClearEvent* ce) {
+  int idx = selectionIndex(ce->selection);
+  if(idx >= 0) {
+    free(_selText[idx]);
+    _selText[idx] = NULL;
+  }
+}
+
+// requestor deleted the property, send next piece of INCR transfer
+static void handleIncrProperty(XPropertyEvent* pe) {
+  if(pe->state != PropertyDelete) {
+    return;
+  }
+  IncrTransfer** pt = &_incrList;
+  while(*pt && ((*pt)->requestor != pe->window || (*pt)->property != pe->atom)) {
+    pt = &(*pt)->next;
+  }
+  IncrTransfer* t = *pt;
+  if(t == NULL) {
+    return;
+  }
+  size_t n = MIN(selectionChunkSize(), t->size - t->offset);
+  XChangeProperty(_display, t->requestor, t->property, t->type, 8,
+    PropModeReplace, (unsigned char*)t->data + t->offset, (int)n);
+  t->offset += n;
+  if(n == 0) {
+    // zero length piece marks the end
+    XSelectInput(_display, t->requestor, NoEventMask);
+    *pt = t->next;
+    free(t->data);
+    free(t);
+  }
+}
+
+typedef struct EventMatch {
+  int type;
+  Atom property;
+} EventMatch;
+
+static Bool matchClipEvent(Display* display, XEvent* e, XPointer arg) {
+  EventMatch* m = (EventMatch*)arg;
+  if(e->xany.window != _clipWindow || e->type != m->type) {
+    return False;
+  }
+  if(e->type == PropertyNotify) {
+    return e->xproperty.atom == m->property && e->xproperty.state == PropertyNewValue;
+  }
+  return True;
+}
+
+// wait event from selection owner, give up after 2 seconds of silence
+static Bool waitClipEvent(int type, Atom property, XEvent* ev) {
+  EventMatch m = {type, property};
+  for(int waited = 0; waited < 2000; waited += 10) {
+    if(XCheckIfEvent(_display, ev, matchClipEvent, (XPointer)&m)) {
+      return True;
+    }
+    XFlush(_display);
+    struct pollfd pfd = {ConnectionNumber(_display), POLLIN, 0};
+    poll(&pfd, 1, 10);
+  }
+  winl_printf("%s\n", "warning: timeout on waiting selection owner.");
+  return False;
+}
+
+// read all pieces of INCR transfer
+static char* readIncr(size_t* len) {
+  char* buf = NULL;
+  size_t size = 0;
+  XEvent ev;
+  for(;;) {
+    if(!waitClipEvent(PropertyNotify, _atom_WINL_SELECTION, &ev)) {
+      free(buf);
+      return NULL;
+    }
+    Atom type;
+    int format;
+    unsigned long count, remain;
+    unsigned char* data = NULL;
+    if(XGetWindowProperty(_display, _clipWindow, _atom_WINL_SELECTION, 0, LONG_MAX/4, True,
+        AnyPropertyType, &type, &format, &count, &remain, &data) != Success) {
+      free(buf);
+      return NULL;
+    }
+    if(count == 0) {
+      XFree(data);
+      break;
+    }
+    buf = realloc(buf, size + count + 1);
+    memcpy(buf + size, data, count);
+    size += count;
+    XFree(data);
+  }
+  if(buf == NULL) {
+    buf = malloc(1);
+  }
+  buf[size] = 0;
+  *len = size;
+  return buf;
+}
+
+// ask owner to convert the selection to target, NULL if failed
+static char* convertSelection(Atom selection, Atom target, size_t* len) {
+  XEvent ev;
+  XDeleteProperty(_display, _clipWindow, _atom_WINL_SELECTION);
+  XConvertSelection(_display, selection, target, _atom_WINL_SELECTION, _clipWindow, CurrentTime);
+  if(!waitClipEvent(SelectionNotify, None, &ev) || ev.xselection.property == None) {
+    return NULL;
+  }
+
+  Atom type;
+  int format;
+  unsigned long count, remain;
+  unsigned char* data = NULL;
+  if(XGetWindowProperty(_display, _clipWindow, _atom_WINL_SELECTION, 0, LONG_MAX/4, True,
+      AnyPropertyType, &type, &format, &count, &remain, &data) != Success) {
+    return NULL;
+  }
+  if(type == _atom_INCR) {
+    // deleting the property starts the transfer
+    XFree(data);
+    return readIncr(len);
+  }
+  char* text = malloc(count + 1);
+  memcpy(text, data, count);
+  text[count] = 0;
+  *len = count;
+  XFree(data);
+  return text;
+}
+
+void winl_set_clipboard_text(int primary, const char* text) {
+  if(!_display) {
+    return;
+  }
+  int idx = primary ? 0 : 1;
+  Atom selection = selectionAtom(primary);
+  free(_selText[idx]);
+  _selText[idx] = strdup(text);
+  XSetSelectionOwner(_display, selection, _clipWindow, CurrentTime);
+  if(XGetSelectionOwner(_display, selection) != _clipWindow) {
+    winl_printf("%s\n", "warning: failed to own the selection.");
+    free(_selText[idx]);
+    _selText[idx] = NULL;
+  }
+}
+
+char* winl_get_clipboard_text(int primary) {
+  if(!_display) {
+    return NULL;
+  }
+  int idx = primary ? 0 : 1;
+  if(_selText[idx]) {
+    return strdup(_selText[idx]);
+  }
+  Atom selection = selectionAtom(primary);
+  if(XGetSelectionOwner(_display, selection) == None) {
+    return NULL;
+  }
+  size_t len;
+  char* text = convertSelection(selection, _atom_UTF8_STRING, &len);
+  if(text == NULL) {
+    // old clients only support Latin-1
+    char* latin1 = convertSelection(selection, XA_STRING, &len);
+    if(latin1) {
+      text = latin1ToUtf8(latin1, len);
+      free(latin1);
+    }
+  }
+  return text;
+}
+
+// tell window manager about position and size limits
+static void updateSizeHints(Window win, NativeWndData* wd) {
+  XSizeHints* hints = XAllocSizeHints();
+  hints->flags = PWinGravity;
+  // position is of client area, not the frame of window manager
+  hints->win_gravity = StaticGravity;
+  if (!wd->resizable) {
+    hints->flags |= PMinSize | PMaxSize;
+    hints->min_width = hints->max_width = wd->width;
+    hints->min_height = hints->max_height = wd->height;
+  } else {
+    if (wd->minWidth > 0 || wd->minHeight > 0) {
+      hints->flags |= PMinSize;
+      hints->min_width = wd->minWidth;
+      hints->min_height = wd->minHeight;
+    }
+    if (wd->maxWidth > 0 || wd->maxHeight > 0) {
+      hints->flags |= PMaxSize;
+      hints->max_width = wd->maxWidth > 0 ? wd->maxWidth : SHRT_MAX;
+      hints->max_height = wd->maxHeight > 0 ? wd->maxHeight : SHRT_MAX;
+    }
+  }
+  if (wd->hasPosition) {
+    // window manager takes position from the window itself
+    hints->flags |= PPosition | USPosition;
+  }
+  XSetWMNormalHints(_display, win, hints);
+  XFree(hints);
+}
+
+static Cursor _cursors[WINL_CURSOR_HIDDEN + 1]; // created on demand
+
+static Cursor standardCursor(int shape) {
+  if(shape < 0 || shape > WINL_CURSOR_HIDDEN) {
+    shape = WINL_CURSOR_ARROW;
+  }
+  if(_cursors[shape]) {
+    return _cursors[shape];
+  }
+  Cursor c = None;
+  switch(shape) {
+  case WINL_CURSOR_IBEAM: {
+    c = XCreateFontCursor(_display, XC_xterm);
+  } break; case WINL_CURSOR_HRESIZE: {
+    c = XCreateFontCursor(_display, XC_sb_h_double_arrow);
+  } break; case WINL_CURSOR_VRESIZE: {
+    c = XCreateFontCursor(_display, XC_sb_v_double_arrow);
+  } break; case WINL_CURSOR_HAND: {
+    c = XCreateFontCursor(_display, XC_hand2);
+  } break; case WINL_CURSOR_CROSSHAIR: {
+    c = XCreateFontCursor(_display, XC_crosshair);
+  } break; case WINL_CURSOR_HIDDEN: {
+    // cursor with empty mask
+    char data = 0;
+    XColor black;
+    memset(&black, 0, sizeof(black));
+    Pixmap blank = XCreateBitmapFromData(_display, RootWindow(_display, _screenNum), &data, 1, 1);
+    c = XCreatePixmapCursor(_display, blank, blank, &black, &black, 0, 0);
+    XFreePixmap(_display, blank);
+  } break; default: {
+    c = XCreateFontCursor(_display, XC_left_ptr);
+  }}
+  _cursors[shape] = c;
+  return c;
+}
+
+void winl_set_cursor(NativeWnd win, int shape) {
+  if(!win) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  wd->cursor = standardCursor(shape);
+  // keep hidden in relative mouse mode
+  if(!wd->relative) {
+    XDefineCursor(_display, win, wd->cursor);
+    XFlush(_display);
+  }
+}
+
+WinlCursor winl_create_cursor(const unsigned char* rgba, int width, int height, int hotX, int hotY) {
+  XRenderPictFormat* format = XRenderFindStandardFormat(_display, PictStandardARGB32);
+  if(!format) {
+    winl_printf("%s\n", "error: XRender ARGB32 format not supported.");
+    return 0;
+  }
+  // XImage takes the ownership of data, XDestroyImage will free it
+  unsigned int* data = malloc(width * height * 4);
+  for(int i = 0; i < width * height; i++) {
+    const unsigned char* p = rgba + i * 4;
+    unsigned int a = p[3];
+    // premultiplied ARGB
+    data[i] = (a << 24) | ((p[0] * a / 255) << 16) | ((p[1] * a / 255) << 8) | (p[2] * a / 255);
+  }
+  Window root = RootWindow(_display, _screenNum);
+  XImage* img = XCreateImage(_display, NULL, 32, ZPixmap, 0, (char*)data, width, height, 32, 0);
+  Pixmap pixmap = XCreatePixmap(_display, root, width, height, 32);
+  GC gc = XCreateGC(_display, pixmap, 0, NULL);
+  XPutImage(_display, pixmap, gc, img, 0, 0, 0, 0, width, height);
+  XFreeGC(_display, gc);
+  XDestroyImage(img);
+
+  Picture pic = XRenderCreatePicture(_display, pixmap, format, 0, NULL);
+  Cursor c = XRenderCreateCursor(_display, pic, hotX, hotY);
+  XRenderFreePicture(_display, pic);
+  XFreePixmap(_display, pixmap);
+  return (WinlCursor)c;
+}
+
+void winl_set_custom_cursor(NativeWnd win, WinlCursor cursor) {
+  if(!win || !cursor) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  wd->cursor = (Cursor)cursor;
+  if(!wd->relative) {
+    XDefineCursor(_display, win, wd->cursor);
+    XFlush(_display);
+  }
+}
+
+void winl_destroy_cursor(WinlCursor cursor) {
+  if(cursor) {
+    XFreeCursor(_display, (Cursor)cursor);
+  }
+}
+
+// read atom list property, returns count, use XFree to release *atoms
+static unsigned long getAtomList(Window win, Atom property, Atom** atoms) {
+  Atom type;
+  int format;
+  unsigned long count = 0, remain;
+  unsigned char* data = NULL;
+  if(XGetWindowProperty(_display, win, property, 0, 1024, False, XA_ATOM,
+      &type, &format, &count, &remain, &data) != Success || type != XA_ATOM) {
+    if(data) {
+      XFree(data);
+    }
+    *atoms = NULL;
+    return 0;
+  }
+  *atoms = (Atom*)data;
+  return count;
+}
+
+// state from _NET_WM_STATE, or WM_STATE if window manager doesn't support EWMH
+static int queryWindowState(Window win) {
+  Atom* atoms;
+  unsigned long count = getAtomList(win, _atom_NET_WM_STATE, &atoms);
+  int hidden = 0, maxVert = 0, maxHorz = 0;
+  for(unsigned long i = 0; i < count; i++) {
+    if(atoms[i] == _atom_NET_WM_STATE_HIDDEN) {
+      hidden = 1;
+    } else if(atoms[i] == _atom_NET_WM_STATE_MAXIMIZED_VERT) {
+      maxVert = 1;
+    } else if(atoms[i] == _atom_NET_WM_STATE_MAXIMIZED_HORZ) {
+      maxHorz = 1;
+    }
+  }
+  if(atoms) {
+    XFree(atoms);
+  }
+  if(hidden) {
+    return WINL_STATE_MINIMIZED;
+  }
+
+  Atom type;
+  int format;
+  unsigned long n, remain;
+  unsigned char* data = NULL;
+  if(XGetWindowProperty(_display, win, _atom_WM_STATE, 0, 2, False, _atom_WM_STATE,
+      &type, &format, &n, &remain, &data) == Success && data) {
+    long state = n > 0 ? ((long*)data)[0] : NormalState;
+    XFree(data);
+    if(state == IconicState) {
+      return WINL_STATE_MINIMIZED;
+    }
+  }
+  if(maxVert && maxHorz) {
+    return WINL_STATE_MAXIMIZED;
+  }
+  return WINL_STATE_NORMAL;
+}
+
+static void updateWindowState(Window win) {
+  NativeWndData* wd = getWndData(win);
+  int state = queryWindowState(win);
+  if(state != wd->state) {
+    wd->state = state;
+    winl_on_state_change(win, state);
+  }
+}
+
+// ask window manager to add or remove _NET_WM_STATE
+static void sendWMState(Window win, long action, Atom a1, Atom a2) {
+  XEvent e;
+  memset(&e, 0, sizeof(e));
+  e.xclient.type = ClientMessage;
+  e.xclient.window = win;
+  e.xclient.message_type = _atom_NET_WM_STATE;
+  e.xclient.format = 32;
+  e.xclient.data.l[0] = action; // 0: remove, 1: add, 2: toggle
+  e.xclient.data.l[1] = a1;
+  e.xclient.data.l[2] = a2;
+  e.xclient.data.l[3] = 1; // normal application
+  XSendEvent(_display, RootWindow(_display, _screenNum), False,
+    SubstructureNotifyMask | SubstructureRedirectMask, &e);
+}
+
+static void sendXdnd(Window to, Window target, Atom type, long l1, long l2, long l3, long l4) {
+  XEvent e;
+  memset(&e, 0, sizeof(e));
+  e.xclient.type = ClientMessage;
+  e.xclient.window = to;
+  e.xclient.message_type = type;
+  e.xclient.format = 32;
+  e.xclient.data.l[0] = target;
+  e.xclient.data.l[1] = l1;
+  e.xclient.data.l[2] = l2;
+  e.xclient.data.l[3] = l3;
+  e.xclient.data.l[4] = l4;
+  XSendEvent(_display, to, False, NoEventMask, &e);
+  XFlush(_display);
+}
+
+static int hexValue(char c) {
+  if (c >= '0' && c <= '9') {
+    return c - '0';
+  } else if (c >= 'a' && c <= 'f') {
+    return c - 'a' + 10;
+  } else if (c >= 'A' && c <= 'F') {
+    return c - 'A' + 10;
+  }
+  return -1;
+}
+
+// text/uri-list to local paths separated by '\n', use free to release memory
+static char* uriListToPaths(const char* data, size_t len) {
+  char* paths = malloc(len + 1);
+  size_t n = 0;
+  const char* end = data + len;
+  const char* line = data;
+  while (line < end) {
+    const char* eol = line;
+    while (eol < end && *eol != '\r' && *eol != '\n') {
+      eol++;
+    }
+    const char* p = line;
+    // file://host/path, only local files are supported
+    if (*p != '#' && eol - p > 7 && strncmp(p, "file://", 7) == 0) {
+      p += 7;
+      while (p < eol && *p != '/') {
+        p++;
+      }
+      if (n > 0) {
+        paths[n++] = '\n';
+      }
+      while (p < eol) {
+        if (*p == '%' && eol - p > 2 && hexValue(p[1]) >= 0 && hexValue(p[2]) >= 0) {
+          paths[n++] = (char)(hexValue(p[1]) * 16 + hexValue(p[2]));
+          p += 3;
+        } else {
+          paths[n++] = *p++;
+        }
+      }
+    }
+    line = eol;
+    while (line < end && (*line == '\r' || *line == '\n')) {
+      line++;
+    }
+  }
+  paths[n] = 0;
+  return paths;
+}
+
+static void handleXdndEnter(XClientMessageEvent* e) {
+  memset(&_dnd, 0, sizeof(_dnd));
+  _dnd.source = e->data.l[0];
+  _dnd.target = e->window;
+  _dnd.version = e->data.l[1] >> 24;
+  _dnd.format = None;
+  if (e->data.l[1] & 1) {
+    // more than 3 types
+    Atom* types;
+    unsigned long count = getAtomList(_dnd.source, _atom_XdndTypeList, &types);
+    for (unsigned long i = 0; i < count; i++) {
+      if (types[i] == _atom_text_uri_list) {
+        _dnd.format = _atom_text_uri_list;
+      }
+    }
+    if (types) {
+      XFree(types);
+    }
+  } else {
+    for (int i = 2; i < 5; i++) {
+      if ((Atom)e->data.l[i] == _atom_text_uri_list) {
+        _dnd.format = _atom_text_uri_list;
+      }
+    }
+  }
+}
+
+static void handleXdndPosition(XClientMessageEvent* e) {
+  if (_dnd.source != (Window)e->data.l[0] || _dnd.target != e->window) {
+    return;
+  }
+  int x, y;
+  Window child;
+  XTranslateCoordinates(_display, RootWindow(_display, _screenNum), e->window,
+    (e->data.l[2] >> 16) & 0xffff, e->data.l[2] & 0xffff, &x, &y, &child);
+  _dnd.x = x;
+  _dnd.y = y;
+  if (!_dnd.entered) {
+    _dnd.entered = 1;
+    winl_on_drag_enter(e->window, _dnd.x, _dnd.y);
+  }
+  _dnd.accepted = _dnd.format != None && winl_on_drag_over(e->window, _dnd.x, _dnd.y);
+  // bit 1: send position again even inside the empty rectangle
+  sendXdnd(_dnd.source, e->window, _atom_XdndStatus, (_dnd.accepted ? 1 : 0) | 2, 0, 0,
+    _dnd.accepted && _dnd.version >= 2 ? _atom_XdndActionCopy : None);
+}
+
+static void handleXdndLeave(XClientMessageEvent* e) {
+  if (_dnd.source != (Window)e->data.l[0]) {
+    return;
+  }
+  if (_dnd.entered) {
+    winl_on_drag_leave(_dnd.target);
+  }
+  memset(&_dnd, 0, sizeof(_dnd));
+}
+
+static void handleXdndDrop(XClientMessageEvent* e) {
+  if (_dnd.source != (Window)e->data.l[0]) {
+    return;
+  }
+  if (!_dnd.accepted) {
+    sendXdnd(_dnd.source, _dnd.target, _atom_XdndFinished, 0, None, 0, 0);
+    if (_dnd.entered) {
+      winl_on_drag_leave(_dnd.target);
+    }
+    memset(&_dnd, 0, sizeof(_dnd));
+    return;
+  }
+  // data arrives in SelectionNotify
+  XConvertSelection(_display, _atom_XdndSelection, _dnd.format, _atom_XdndSelection, _dnd.target,
+    _dnd.version >= 1 ? (Time)e->data.l[2] : CurrentTime);
+  XFlush(_display);
+}
+
+static void handleXdndData(XSelectionEvent* se) {
+  if (_dnd.source == 0 || se->requestor != _dnd.target) {
+    return;
+  }
+  int ok = 0;
+  if (se->property != None) {
+    Atom type;
+    int format;
+    unsigned long count, remain;
+    unsigned char* data = NULL;
+    if (XGetWindowProperty(_display, se->requestor, se->property, 0, LONG_MAX/4, True,
+        AnyPropertyType, &type, &format, &count, &remain, &data) == Success && data) {
+      char* paths = uriListToPaths((const char*)data, count);
+      if (paths[0]) {
+        ok = 1;
+        winl_on_drop(_dnd.target, paths, _dnd.x, _dnd.y);
+      }
+      free(paths);
+      XFree(data);
+    }
+  }
+  if (!ok) {
+    winl_on_drag_leave(_dnd.target);
+  }
+  sendXdnd(_dnd.source, _dnd.target, _atom_XdndFinished, ok, ok ? _atom_XdndActionCopy : None, 0, 0);
+  memset(&_dnd, 0, sizeof(_dnd));
+}
+
+static void setUrgency(Window win, NativeWndData* wd, int urgent) {
+  XWMHints* hints = XGetWMHints(_display, win);
+  if (!hints) {
+    hints = XAllocWMHints();
+  }
+  if (urgent) {
+    hints->flags |= XUrgencyHint;
+  } else {
+    hints->flags &= ~XUrgencyHint;
+  }
+  XSetWMHints(_display, win, hints);
+  XFree(hints);
+  // EWMH window managers may show it differently
+  sendWMState(win, urgent ? 1 : 0, _atom_NET_WM_STATE_DEMANDS_ATTENTION, None);
+  wd->urgent = urgent ? 1 : 0;
+  XFlush(_display);
+}
+
+// pointer is confined in win if confine is True
+static Bool grabPointer(Window win, Bool confine) {
 	return XGrabPointer(_display,
 		win,
 		0,
@@ -267,7 +1337,7 @@ static Bool grabPointer(Window win) {
 		ButtonReleaseMask,
 		GrabModeAsync,
 		GrabModeAsync,
-		None,
+		confine ? win : None,
 		None,
 		CurrentTime) == GrabSuccess;
 }
@@ -276,6 +1346,56 @@ static void ungrabPointer() {
 	XUngrabPointer(_display, CurrentTime);
 }
 
+int winl_grab_mouse(NativeWnd win, int grab) {
+  if(!win) {
+    return 0;
+  }
+  NativeWndData* wd = getWndData(win);
+  if(grab) {
+    // relative mode already holds a confined grab
+    if(!wd->relative && !grabPointer(win, False)) {
+      return 0;
+    }
+    wd->grabbed = 1;
+  } else {
+    if(!wd->relative) {
+      ungrabPointer();
+    }
+    wd->grabbed = 0;
+  }
+  XFlush(_display);
+  return 1;
+}
+
+// the pointer is hidden, confined and warped back to center after every motion
+int winl_set_relative_mouse(NativeWnd win, int enable) {
+  if(!win) {
+    return 0;
+  }
+  NativeWndData* wd = getWndData(win);
+  if((enable != 0) == (wd->relative != 0)) {
+    return 1;
+  }
+  if(enable) {
+    if(!grabPointer(win, True)) {
+      return 0;
+    }
+    wd->relative = 1;
+    XDefineCursor(_display, win, standardCursor(WINL_CURSOR_HIDDEN));
+    XWarpPointer(_display, None, win, 0, 0, 0, 0, wd->width/2, wd->height/2);
+  } else {
+    wd->relative = 0;
+    XDefineCursor(_display, win, wd->cursor);
+    if(wd->grabbed) {
+      grabPointer(win, False);
+    } else {
+      ungrabPointer();
+    }
+  }
+  XFlush(_display);
+  return 1;
+}
+
 
 static void _windowProc(XEvent* _event)
 {
@@ -296,6 +1416,14 @@ static void _windowProc(XEvent* _event)
         wd->width = w; wd->height = h;
         winl_on_resize(win, w, h);
       }
+      // coordinates in event are relative to the frame if reparented by window manager
+      int x, y;
+      Window child;
+      XTranslateCoordinates(_display, win, RootWindow(_display, _screenNum), 0, 0, &x, &y, &child);
+      if(x != wd->x || y != wd->y) {
+        wd->x = x; wd->y = y;
+        winl_on_move(win, x, y);
+      }
   } break; case MapNotify: {
     NativeWndData* wd = getWndData(win);
     wd->visible = 1;
@@ -307,168 +1435,129 @@ static void _windowProc(XEvent* _event)
     wd->visible = 0;
     //OnVisibleChanged(False);
   } break; case Expose: {
-    winl_expose(win, (float)_event->xexpose.x, (float)_event->xexpose.y,
-     (float)_event->xexpose.width, (float)_event->xexpose.height);
+    // the one sent by winl_expose only wakes the loop, its area is in dirty already
+    if (!_event->xexpose.send_event) {
+      addDirty(getWndData(win), (float)_event->xexpose.x, (float)_event->xexpose.y,
+       (float)_event->xexpose.width, (float)_event->xexpose.height);
+    }
      // send later in event loop
   } break; case ButtonPress: {
     XButtonEvent *be = (XButtonEvent*) _event;
-    int btn = btnNum(be->button);
-    if (btn) {
-      winl_on_mouse_press(win, btn, be->x, be->y);
-    }
+    winl_on_x_button(win, be->button, 1, be->x, be->y);
   } break; case ButtonRelease: {
     XButtonEvent *be = (XButtonEvent*) _event;
-    int btn = btnNum(be->button);
-    if (btn) {
-  		winl_on_mouse_release(win, btn, be->x, be->y);
-    }
+    winl_on_x_button(win, be->button, 0, be->x, be->y);
   } break; case MotionNotify:{
     XMotionEvent* me = (XMotionEvent*) _event;
-    winl_on_mouse_move(win, me->x, me->y);
+    NativeWndData* wd = getWndData(win);
+    if (wd->relative) {
+      // motion made by XWarpPointer is at center, has no delta
+      int cx = wd->width/2, cy = wd->height/2;
+      if (me->x != cx || me->y != cy) {
+        winl_on_mouse_delta(win, me->x - cx, me->y - cy);
+        XWarpPointer(_display, None, win, 0, 0, 0, 0, cx, cy);
+      }
+    } else {
+      winl_on_mouse_move(win, me->x, me->y);
+    }
   } break; case KeyPress: {
-//     {
-//       KeySym keysym = 0;
-//       Bool charsValid = False;
-//       Bool keysymValid = False;
-//       String text;
-//       if(_xic)
-//       {
-//         int chars = 0;
-//         int buf_size = 64;
-//         wchar_t * buf = new wchar_t[buf_size + 1];
-//         buf[0] = 0;
-//         Bool again = True;
-//         while(again)
-//         {
-//           buf[0] = 0;
-//           keysym = 0;
-//           Status status;
-//           chars = XwcLookupString(_xic, (XKeyPressedEvent *)&_event->xkey, buf, buf_size, &keysym, &status);
-//           //hx_trace("[e_ui] XwcLookupString()");
-//           switch(status)
-//           {
-//           case XBufferOverflow:
-//             //hx_trace("[e_ui]     XBufferOverflow");
-//             buf_size+= 64;
-//             delete[] buf;
-//             buf = new wchar_t[buf_size + 1];
-//             continue;
-//           case XLookupNone:
-//             //hx_trace("[e_ui]     XLookupNone");
-//             again = False;
-//             break;
-//           case XLookupChars:
-//             //hx_trace("[e_ui]     XLookupChars");
-//             charsValid = True;
-//             again = False;
-//             break;
-//           case XLookupKeySym:
-//             //hx_trace("[e_ui]     XLookupKeySym");
-//             keysymValid = True;
-//             again = False;
-//             break;
-//           case XLookupBoth:
-//             //hx_trace("[e_ui]     XLookupBoth");
-//             charsValid = True;
-//             keysymValid = True;
-//             again = False;
-//             break;
-//           }
-//         }
-//
-//         buf[chars] = 0;
-//         text = String(buf);
-//         delete[] buf;
-//       }
-//
-//       // xim dosn't work, try x11 method
-//       if(_xic == 0 || (!charsValid && !keysymValid))
-//       {
-//         //hx_trace("[e_ui] XLookupString ");
-//         //keysym = XKeycodeToKeysym(_display, _event->xkey.keycode, 0);
-//         keysymValid = True;
-//         Buffer buf1(64);
-//         //buf1.(64);
-//         //Status status;
-//         int len = XLookupString(&_event->xkey, buf1, 64, &keysym, 0);
-//         buf1[len] = 0;
-//         text = String(buf1);
-//       }
-//
-//       // process keysym
-//       if(keysymValid)
-//       {
-// //				if(keysym == Keyboard::LeftAlt || keysym == Keyboard::RightAlt)
-//   //			{
-//   //				_altJustDown = True;
-//   //			}
-//   //			else
-//   //			{
-//   //				_altJustDown = False;
-//   //			}
-//
-//   //			if(Keyboard::isAltDown())
-//   //			{
-//   //				OnAltCommand(keysym);
-//   //			}
-//   //			else
-//   //			{
-//   //				this->onKeyDown(keysym);
-//   //			}
-//         if(keysym >= 'a' && keysym <= 'z')
-//         {
-//           keysym-= 'a' - 'A';
-//         }
-//         this->onKeyDown(keysym);
-//       }
-//
-//       // process chars
-//       if(charsValid)
-//       {
-//         for(int i = 0; i < text.length(); i++)
-//         {
-//           this->onCharInput(text[i]);
-//         }
-//       }
-//     }
+    NativeWndData* wd = getWndData(win);
+    unsigned int code = _event->xkey.keycode & 0xff;
+    // keycode is 0 when input method commits text
+    if (code != 0) {
+      int repeat = (wd->keys[code/8] & (1 << (code%8))) != 0;
+      wd->keys[code/8] |= 1 << (code%8);
+      int key = translateKey(code);
+      if (key != WINL_KEY_UNKNOWN) {
+        winl_on_key_press(win, key, keyMods(wd, key, _event->xkey.state), repeat);
+      }
+    }
+    lookupText(win, wd->xic, &_event->xkey);
   } break; case KeyRelease:{
-    //{
-    //   KeySym keysym = XKeycodeToKeysym(_display, _event->xkey.keycode, 0);
-    //
-    //   if(Keyboard::isAltDown())
-    //   {
-    //     // we dont need sys_key_up
-    //   }
-    //   else
-    //   {
-    //     if(keysym >= 'a' && keysym <= 'z')
-    //     {
-    //       keysym-= 'a' - 'A';
-    //     }
-    //     this->onKeyUp(keysym);
-    //   }
-    // }
+    if (!_detectableAutoRepeat && isAutoRepeatRelease(_event)) {
+      // the KeyPress followed will be reported as repeat
+      break;
+    }
+    NativeWndData* wd = getWndData(win);
+    unsigned int code = _event->xkey.keycode & 0xff;
+    wd->keys[code/8] &= ~(1 << (code%8));
+    int key = translateKey(code);
+    if (key != WINL_KEY_UNKNOWN) {
+      winl_on_key_release(win, key, keyMods(wd, key, _event->xkey.state));
+    }
   } break; case FocusIn: {
-  	// if(!win->focus)
-  	// {
-  	// 	win->focus = True;
-  	// 	win->OnFocusIn();
-  	// }
+    NativeWndData* wd = getWndData(win);
+    // ignore focus moving by keyboard grab, i.e. window manager's Alt+Tab
+    if (_event->xfocus.mode == NotifyGrab || _event->xfocus.mode == NotifyUngrab) {
+      break;
+    }
+    if (wd->xic) {
+      XSetICFocus(wd->xic);
+    }
+    if (!wd->focused) {
+      wd->focused = 1;
+      winl_on_focus(win, 1);
+    }
+    if (wd->urgent) {
+      setUrgency(win, wd, 0);
+    }
   } break; case FocusOut:{
-  	// if(win->focus){
-  	// 	win->focus = False;
-  	// 	win->OnFocusOut();
-  	// }
+    NativeWndData* wd = getWndData(win);
+    if (!wd || _event->xfocus.mode == NotifyGrab || _event->xfocus.mode == NotifyUngrab) {
+      break;
+    }
+    // releases of keys are not reported to inactive window, the next press is not a repeat
+    memset(wd->keys, 0, sizeof(wd->keys));
+    if (wd->xic) {
+      XUnsetICFocus(wd->xic);
+    }
+    if (wd->focused) {
+      wd->focused = 0;
+      winl_on_focus(win, 0);
+    }
   } break; case DestroyNotify: {
+    if (_dnd.target == win) {
+      memset(&_dnd, 0, sizeof(_dnd));
+    }
     winl_on_destroy(win);
-    free(getWndData(win));
+    NativeWndData* wd = getWndData(win);
+    if (wd->xic) {
+      XDestroyIC(wd->xic);
+    }
+    if (wd->ctx) {
+      if (glXGetCurrentContext() == wd->ctx) {
+        glXMakeCurrent(_display, None, NULL);
+      }
+      glXDestroyContext(_display, wd->ctx);
+    }
+    free(wd);
     XDeleteContext(_display, win, _wdContext);
     _windowCount--;
     //winl_make_current(0);
   } break; case SelectionRequest: {
-    //Clipboard::Singleton().this->HandleSelectionRequest(_event);
+    handleSelectionRequest(&_event->xselectionrequest);
+  } break; case SelectionClear: {
+    handleSelectionClear(&_event->xselectionclear);
   } break; case SelectionNotify: {
-  //	Clipboard::Singleton().this->HandleSelectionNotify(_event);
+    if (_event->xselection.selection == _atom_XdndSelection) {
+      handleXdndData(&_event->xselection);
+    }
+    // others are consumed by convertSelection(), late reply is dropped
+  } break; case PropertyNotify: {
+    if (win == RootWindow(_display, _screenNum)) {
+      if (_event->xproperty.atom == XA_RESOURCE_MANAGER) {
+        float scale = readContentScale();
+        if (scale != _contentScale) {
+          _contentScale = scale;
+          winl_on_scale_changed(0, scale);
+        }
+      }
+    } else if (getWndData(win) == 0) {
+      // requestor window of other client
+      handleIncrProperty(&_event->xproperty);
+    } else if (_event->xproperty.atom == _atom_NET_WM_STATE || _event->xproperty.atom == _atom_WM_STATE) {
+      updateWindowState(win);
+    }
   } break; default: {
 
   }}
@@ -488,6 +1577,14 @@ static void _HandleEvent(XEvent *_e)
       (Atom)(e1->data.l[0]) == _atom_WM_DELETE_WINDOW) {
       // close button
       XDestroyWindow(_display, e1->window);
+		} else if(e1->message_type == _atom_XdndEnter) {
+      handleXdndEnter(e1);
+		} else if(e1->message_type == _atom_XdndPosition) {
+      handleXdndPosition(e1);
+		} else if(e1->message_type == _atom_XdndLeave) {
+      handleXdndLeave(e1);
+		} else if(e1->message_type == _atom_XdndDrop) {
+      handleXdndDrop(e1);
 		}
     /*
 		else if((Atom)(e1.data.l[0]) == _atom_NET_WM_PING)
@@ -538,14 +1635,36 @@ static Bool pumpMessage() {
   }
 }
 
+void winl_wakeup() {
+  if(_wakePipe[1] >= 0) {
+    char c = 0;
+    // pipe is full if failed, the loop will wake up anyway
+    if(write(_wakePipe[1], &c, 1) < 0) {
+    }
+  }
+}
+
+int winl_is_ui_thread() {
+  return pthread_equal(pthread_self(), _uiThread);
+}
+
 int winl_event_loop() {
 
   winl_on_start();
 
   while(!_toExit && _windowCount > 1) {
-  	if(!pumpMessage()) {
-      usleep(1000);
-  	}
+    Bool busy = pumpMessage();
+    // due timers, frames and posted work run after every event, so a steady stream of events doesn't starve them
+    int timeout = winl_on_idle();
+    if(!busy && !_toExit && XEventsQueued(_display, QueuedAfterFlush) == 0) {
+      // sleep until X event, winl_wakeup or next timer
+      struct pollfd pfd[2] = {{ConnectionNumber(_display), POLLIN, 0}, {_wakePipe[0], POLLIN, 0}};
+      if(poll(pfd, _wakePipe[0] < 0 ? 1 : 2, timeout) > 0 && (pfd[1].revents & POLLIN)) {
+        char buf[64];
+        while(read(_wakePipe[0], buf, sizeof(buf)) > 0) {
+        }
+      }
+    }
   }
 
   _CloseXLib();
@@ -553,10 +1672,9 @@ int winl_event_loop() {
 	return _exitCode;
 }
 
-Bool createGraphics(Window win) {
-  if(hGL != NULL)
-    return True;
-
+// context of window, in share group of hGL
+Bool createGraphics(Window win, NativeWndData* wd) {
+  if(hGL == NULL) {
     int visAttributes[20];
     int i = 0;
     visAttributes[i++] = GLX_USE_GL;
@@ -579,21 +1697,23 @@ Bool createGraphics(Window win) {
     visAttributes[i++] = None;
 
     //hx_trace("++++++++++++++++++++++");
-    XVisualInfo *visinfo = glXChooseVisual(_display, 0, visAttributes);
+    _visinfo = glXChooseVisual(_display, 0, visAttributes);
     // assert(visinfo);
-    if(!visinfo)
+    if(!_visinfo)
     {
       return False;
     }
     //hx_trace("----------------------");
-    hGL = glXCreateContext(_display, visinfo, NULL, True);
+    hGL = glXCreateContext(_display, _visinfo, NULL, True);
     // assert(hGL);
     if(!hGL)
     {
       return False;
     }
+  }
 
-  return True;
+  wd->ctx = glXCreateContext(_display, _visinfo, hGL, True);
+  return wd->ctx != NULL;
 }
 
 void winl_get_screen_size(int *width, int *height) {
@@ -605,6 +1725,44 @@ void winl_get_screen_size(int *width, int *height) {
   }
 }
 
+int winl_get_monitors(WinlMonitor* monitors, int max) {
+  int n = 0;
+  if(_XRRGetMonitors) {
+    int count = 0;
+    _XRRMonitorInfo* mi = _XRRGetMonitors(_display, RootWindow(_display, _screenNum), True, &count);
+    for(int i = 0; i < count && n < max; i++, n++) {
+      monitors[n].x = mi[i].x;
+      monitors[n].y = mi[i].y;
+      monitors[n].width = mi[i].width;
+      monitors[n].height = mi[i].height;
+      monitors[n].widthMM = mi[i].mwidth;
+      monitors[n].heightMM = mi[i].mheight;
+      monitors[n].scale = _contentScale;
+      monitors[n].primary = mi[i].primary;
+    }
+    if(mi) {
+      _XRRFreeMonitors(mi);
+    }
+  }
+  if(n == 0 && max > 0) {
+    // RandR 1.5 is not available, the screen as single monitor
+    monitors[0].x = 0;
+    monitors[0].y = 0;
+    monitors[0].width = DisplayWidth(_display, _screenNum);
+    monitors[0].height = DisplayHeight(_display, _screenNum);
+    monitors[0].widthMM = DisplayWidthMM(_display, _screenNum);
+    monitors[0].heightMM = DisplayHeightMM(_display, _screenNum);
+    monitors[0].scale = _contentScale;
+    monitors[0].primary = 1;
+    n = 1;
+  }
+  return n;
+}
+
+float winl_get_content_scale(NativeWnd win) {
+  return _contentScale;
+}
+
 NativeWnd winl_create(int ws, int width, int height) {
   _InitXLib();
   unsigned long valueMask = 0;
@@ -644,6 +1802,11 @@ NativeWnd winl_create(int ws, int width, int height) {
 
   NativeWndData* wd = malloc(sizeof(NativeWndData));
   memset(wd, 0, sizeof(NativeWndData));
+  wd->x = x;
+  wd->y = y;
+  wd->width = width;
+  wd->height = height;
+  wd->resizable = (ws & WINL_HINT_RESIZABLE) != 0;
   XSaveContext(_display, win, _wdContext, (XPointer)wd);
 
   _windowCount++;
@@ -652,10 +1815,16 @@ NativeWnd winl_create(int ws, int width, int height) {
   //hint.res_class = (char*)"E_WIN";
   //XSetClassHint(_display, imp->hWnd, &hint);
 
+  wd->xic = createIC(win);
+  long imEventMask = 0;
+  if (wd->xic) {
+    XGetICValues(wd->xic, XNFilterEvents, &imEventMask, NULL);
+  }
+
   XSelectInput(_display, win,
       ExposureMask
       | PointerMotionMask
-      // FocusChangeMask
+      | FocusChangeMask
       | ButtonMotionMask
       | Button1MotionMask
       // Button2MotionMask
@@ -666,25 +1835,20 @@ NativeWnd winl_create(int ws, int width, int height) {
       | EnterWindowMask
       | LeaveWindowMask
       | StructureNotifyMask
-      // im_event_mask
+      | PropertyChangeMask
+      | imEventMask
       );
 
   // add delete button
   XSetWMProtocols (_display, win, &_atom_WM_DELETE_WINDOW, 1);
 
-  if( (ws & WINL_HINT_RESIZABLE) == 0 ) {
-    XSizeHints size_hints;
-    size_hints.flags = PMinSize | PMaxSize;
-    size_hints.min_width = width;
-    size_hints.max_width = width;
-    size_hints.min_height = height;
-    size_hints.max_height = height;
-    XSetWMNormalHints(_display, win, &size_hints);
-  }
+  // accept drag and drop
+  Atom xdndVersion = 5;
+  XChangeProperty(_display, win, _atom_XdndAware, XA_ATOM, 32,
+    PropModeReplace, (const unsigned char *)&xdndVersion, 1);
+
+  updateSizeHints(win, wd);
 
-  XSetICValues(_xic, XNClientWindow, win, NULL);
-//	WindowImp::_xicWindow = imp->hWnd;
-  XSetICFocus(_xic);
 
   //if(_CreateGraphics())
   //{
@@ -699,7 +1863,9 @@ NativeWnd winl_create(int ws, int width, int height) {
   //	return False;
   //}
 
-  createGraphics(win);
+  if (!createGraphics(win, wd)) {
+    winl_printf("%s\n", "failed to create OpenGL context");
+  }
 
   return win;
 }
@@ -720,19 +1886,56 @@ void winl_destroy(NativeWnd win) {
 int winl_make_current(NativeWnd win) {
   winl_printf("winl_make_current %ld", win);
   if (win) {
-    return glXMakeCurrent(_display, win, hGL);
+    return glXMakeCurrent(_display, win, getWndData(win)->ctx);
   } else {
     return glXMakeCurrent(_display, None, NULL);
   }
   //return 0;
 }
 
+void* winl_get_context(NativeWnd win) {
+  if (!win) {
+    return NULL;
+  }
+  return getWndData(win)->ctx;
+}
+
+void* winl_current_context() {
+  return glXGetCurrentContext();
+}
+
 void winl_swap_buffers(NativeWnd win) {
   if (win) {
     glXSwapBuffers(_display, win);
   }
 }
 
+typedef void (*PFN_glXSwapIntervalEXT)(Display *dpy, GLXDrawable drawable, int interval);
+typedef int (*PFN_glXSwapIntervalMESA)(unsigned int interval);
+typedef int (*PFN_glXSwapIntervalSGI)(int interval);
+
+void winl_set_swap_interval(NativeWnd win, int interval) {
+  if (!win) {
+    return;
+  }
+  PFN_glXSwapIntervalEXT ext = (PFN_glXSwapIntervalEXT)glXGetProcAddress((const GLubyte*)"glXSwapIntervalEXT");
+  if (ext) {
+    ext(_display, win, interval);
+    return;
+  }
+  // MESA and SGI version apply to current context
+  glXMakeCurrent(_display, win, getWndData(win)->ctx);
+  PFN_glXSwapIntervalMESA mesa = (PFN_glXSwapIntervalMESA)glXGetProcAddress((const GLubyte*)"glXSwapIntervalMESA");
+  if (mesa) {
+    mesa(interval);
+    return;
+  }
+  PFN_glXSwapIntervalSGI sgi = (PFN_glXSwapIntervalSGI)glXGetProcAddress((const GLubyte*)"glXSwapIntervalSGI");
+  if (sgi && interval > 0) {
+    sgi(interval);
+  }
+}
+
 void winl_get_size(NativeWnd win, float *width, float *height) {
   int _w, _h;
   if (win) {
@@ -787,6 +1990,96 @@ void winl_toggle_full_screen(NativeWnd win){
     PropModeReplace, (const unsigned char *)arr, 2);
 }
 
+void winl_get_position(NativeWnd win, float *x, float *y) {
+  NativeWndData* wd = win ? getWndData(win) : NULL;
+  if(x) {
+    *x = wd ? wd->x : 0;
+  }
+  if(y) {
+    *y = wd ? wd->y : 0;
+  }
+}
+
+void winl_move(NativeWnd win, float x, float y) {
+  if(!win) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  wd->hasPosition = 1;
+  updateSizeHints(win, wd);
+  XMoveWindow(_display, win, (int)x, (int)y);
+  XFlush(_display);
+}
+
+void winl_resize(NativeWnd win, float width, float height) {
+  if(!win) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  if (!wd->resizable) {
+    // fixed size is in hints, or window manager rejects the new size
+    wd->width = (int)width;
+    wd->height = (int)height;
+    updateSizeHints(win, wd);
+  }
+  XResizeWindow(_display, win, (unsigned int)width, (unsigned int)height);
+  XFlush(_display);
+}
+
+void winl_set_size_limits(NativeWnd win, float minWidth, float minHeight, float maxWidth, float maxHeight) {
+  if(!win) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  wd->minWidth = (int)minWidth;
+  wd->minHeight = (int)minHeight;
+  wd->maxWidth = (int)maxWidth;
+  wd->maxHeight = (int)maxHeight;
+  updateSizeHints(win, wd);
+  XFlush(_display);
+}
+
+void winl_set_state(NativeWnd win, int state) {
+  if(!win) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  switch(state) {
+  case WINL_STATE_MINIMIZED: {
+    XIconifyWindow(_display, win, _screenNum);
+  } break; case WINL_STATE_MAXIMIZED: {
+    if (wd->state == WINL_STATE_MINIMIZED) {
+      XMapWindow(_display, win);
+    }
+    sendWMState(win, 1, _atom_NET_WM_STATE_MAXIMIZED_VERT, _atom_NET_WM_STATE_MAXIMIZED_HORZ);
+  } break; default: {
+    if (wd->state == WINL_STATE_MINIMIZED) {
+      XMapWindow(_display, win);
+    }
+    sendWMState(win, 0, _atom_NET_WM_STATE_MAXIMIZED_VERT, _atom_NET_WM_STATE_MAXIMIZED_HORZ);
+  }}
+  XFlush(_display);
+}
+
+void winl_focus(NativeWnd win) {
+  if(!win) {
+    return;
+  }
+  // window manager may refuse to steal focus from other application
+  XEvent e;
+  memset(&e, 0, sizeof(e));
+  e.xclient.type = ClientMessage;
+  e.xclient.window = win;
+  e.xclient.message_type = _atom_NET_ACTIVE_WINDOW;
+  e.xclient.format = 32;
+  e.xclient.data.l[0] = 1; // normal application
+  e.xclient.data.l[1] = CurrentTime;
+  XSendEvent(_display, RootWindow(_display, _screenNum), False,
+    SubstructureNotifyMask | SubstructureRedirectMask, &e);
+  XRaiseWindow(_display, win);
+  XFlush(_display);
+}
+
 void winl_set_title(NativeWnd win, const char * title) {
   if(!win) {
     return;
@@ -800,15 +2093,90 @@ void winl_set_title(NativeWnd win, const char * title) {
   }
 }
 
+void winl_set_icon(NativeWnd win, int count, const int* sizes, const unsigned char* rgba) {
+  if (!win) {
+    return;
+  }
+  if (count == 0) {
+    XDeleteProperty(_display, win, _atom_NET_WM_ICON);
+    XFlush(_display);
+    return;
+  }
+  // width, height, then ARGB pixels of each image.
+  // data of format 32 property is array of long, even if long is 64 bits
+  long n = 0;
+  for (int i = 0; i < count; i++) {
+    n += 2 + sizes[i*2] * sizes[i*2+1];
+  }
+  long* data = malloc(n * sizeof(long));
+  long* p = data;
+  for (int i = 0; i < count; i++) {
+    int w = sizes[i*2], h = sizes[i*2+1];
+    *p++ = w;
+    *p++ = h;
+    for (int j = 0; j < w * h; j++) {
+      *p++ = ((long)rgba[3] << 24) | ((long)rgba[0] << 16) | ((long)rgba[1] << 8) | (long)rgba[2];
+      rgba += 4;
+    }
+  }
+  XChangeProperty(_display, win, _atom_NET_WM_ICON, XA_CARDINAL, 32,
+    PropModeReplace, (const unsigned char*)data, n);
+  free(data);
+  XFlush(_display);
+}
+
+void winl_request_attention(NativeWnd win) {
+  if (!win) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  if (!wd || wd->focused) {
+    return;
+  }
+  setUrgency(win, wd, 1);
+}
+
+void winl_set_progress(NativeWnd win, float fraction) {
+  // no-op, X11 has no standard hint for progress, docks of desktop environments use D-Bus
+}
+
 void winl_expose(NativeWnd win, float x, float y, float width, float height) {
   if (!win) {
     return;
   }
   NativeWndData * wd = getWndData(win);
-  wd->dirty.l = MIN(x, wd->dirty.l);
-  wd->dirty.t = MIN(y, wd->dirty.t);
-  wd->dirty.r = MAX(x+width, wd->dirty.r);
-  wd->dirty.b = MAX(y+height, wd->dirty.b);
+  if (!wd) {
+    return;
+  }
+  if (addDirty(wd, x, y, width, height)) {
+    // dirty area is dispatched after an event of the window, make one if called out of event handler
+    XEvent ev;
+    memset(&ev, 0, sizeof(ev));
+    ev.type = Expose;
+    ev.xexpose.window = win;
+    ev.xexpose.x = (int)x;
+    ev.xexpose.y = (int)y;
+    ev.xexpose.width = (int)width;
+    ev.xexpose.height = (int)height;
+    XSendEvent(_display, win, False, ExposureMask, &ev);
+  }
+}
+
+void winl_set_text_input_rect(NativeWnd win, float x, float y, float width, float height) {
+  if (!win) {
+    return;
+  }
+  NativeWndData* wd = getWndData(win);
+  if (!wd->xic || !(_ximStyle & XIMPreeditPosition)) {
+    return;
+  }
+  // the spot is base line of the preedit text
+  XPoint spot;
+  spot.x = (short)x;
+  spot.y = (short)(y + height);
+  XVaNestedList preedit_attr = XVaCreateNestedList(0, XNSpotLocation, &spot, NULL);
+  XSetICValues(wd->xic, XNPreeditAttributes, preedit_attr, NULL);
+  XFree(preedit_attr);
 }
 
 void winl_exit_loop(int code) {
@@ -816,14 +2184,14 @@ void winl_exit_loop(int code) {
   _exitCode = code;
 }
 
+// X11 has no message box, gui package installs its own dialogs
 void winl_message_box(NativeWnd win, const char* msg, const char* title) {
-	// stub for linux
-  winl_printf("%s\n", "stub: winl_message_box");
+  winl_printf("message box: %s: %s\n", title, msg);
 }
 
 int winl_confirm_box(NativeWnd win, const char* msg, const char* title) {
-  // stub for linux
-  winl_printf("%s\n", "stub: winl_confirm_box");
+  winl_printf("confirm box: %s: %s\n", title, msg);
+  return 0;
 }
 
 char* winl_os_version() {
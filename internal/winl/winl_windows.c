@@ -1,6 +1,10 @@
 #include <windows.h>
 #include <windowsx.h>
 #include <stdio.h>
+#include <imm.h>
+#include <ole2.h>
+#include <shellapi.h>
+#include <shobjidl.h>
 //#include <assert.h>
 #include <GL/GL.h>
 #include "winl-c.h"
@@ -9,15 +13,19 @@
 #ifndef WM_MOUSEHWHEEL
 #	define WM_MOUSEHWHEEL 0x020E
 #endif
+#ifndef WM_DPICHANGED
+#	define WM_DPICHANGED 0x02E0
+#endif
 
-#define szOpenGLWndClass "WINL_OPENGL"
+// unicode window class, WM_CHAR delivers UTF-16
+#define szOpenGLWndClass L"WINL_OPENGL"
 
 LRESULT CALLBACK MainWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lParam);
 LRESULT CALLBACK OpenGLWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lParam);
 void InitOpenGL(HWND hWnd);
 //void CleanUp(HWND hWnd);
 
-HGLRC _hGLRC;
+HGLRC _hGLRC; // root of share group, never current
 int _windowCount;
 
 typedef struct NativeWndData {
@@ -29,25 +37,194 @@ typedef struct NativeWndData {
 	DWORD restoreExStyle;
 	int btnDown;
 	HDC hDC;
+	HGLRC hGLRC; // shares lists with _hGLRC
+	WCHAR highSurrogate; // first half of surrogate pair from WM_CHAR
+	int state; // WINL_STATE_*
+	int minWidth, minHeight, maxWidth, maxHeight; // client area, 0 for no limit
+	HCURSOR cursor; // NULL for hidden
+	int grabbed; // mouse is captured by winl_grab_mouse
+	int relative; // relative mouse mode
+	IDropTarget* dropTarget;
+	HICON iconBig, iconSmall; // set by winl_set_icon
 }NativeWndData;
 
 static NativeWndData* getWndData(HWND hWnd) {
 	return (NativeWndData*)(void*)GetWindowLongPtr(hWnd, GWLP_USERDATA);
 }
 
+// OLE drop target of a window
+typedef struct DropTarget {
+	IDropTarget iface; // must be first
+	LONG ref;
+	HWND hWnd;
+	int hasFiles; // data object provides CF_HDROP
+	DWORD effect; // of last DragOver
+} DropTarget;
+
+static HRESULT STDMETHODCALLTYPE dtQueryInterface(IDropTarget* This, REFIID riid, void** ppv) {
+	if(IsEqualIID(riid, &IID_IUnknown) || IsEqualIID(riid, &IID_IDropTarget)) {
+		*ppv = This;
+		This->lpVtbl->AddRef(This);
+		return S_OK;
+	}
+	*ppv = NULL;
+	return E_NOINTERFACE;
+}
+
+static ULONG STDMETHODCALLTYPE dtAddRef(IDropTarget* This) {
+	return InterlockedIncrement(&((DropTarget*)This)->ref);
+}
+
+static ULONG STDMETHODCALLTYPE dtRelease(IDropTarget* This) {
+	DropTarget* dt = (DropTarget*)This;
+	LONG ref = InterlockedDecrement(&dt->ref);
+	if(ref == 0) {
+		free(dt);
+	}
+	return ref;
+}
+
+static POINT dropPoint(DropTarget* dt, POINTL pt) {
+	POINT p = {pt.x, pt.y};
+	ScreenToClient(dt->hWnd, &p);
+	return p;
+}
+
+static void updateDropEffect(DropTarget* dt, POINTL pt, DWORD* effect) {
+	POINT p = dropPoint(dt, pt);
+	if(dt->hasFiles && (*effect & DROPEFFECT_COPY) && winl_on_drag_over(dt->hWnd, p.x, p.y)) {
+		dt->effect = DROPEFFECT_COPY;
+	} else {
+		dt->effect = DROPEFFECT_NONE;
+	}
+	*effect = dt->effect;
+}
+
+static HRESULT STDMETHODCALLTYPE dtDragEnter(IDropTarget* This, IDataObject* data, DWORD keys, POINTL pt, DWORD* effect) {
+	DropTarget* dt = (DropTarget*)This;
+	FORMATETC fmt = {CF_HDROP, NULL, DVASPECT_CONTENT, -1, TYMED_HGLOBAL};
+	dt->hasFiles = data->lpVtbl->QueryGetData(data, &fmt) == S_OK;
+	POINT p = dropPoint(dt, pt);
+	winl_on_drag_enter(dt->hWnd, p.x, p.y);
+	updateDropEffect(dt, pt, effect);
+	return S_OK;
+}
+
+static HRESULT STDMETHODCALLTYPE dtDragOver(IDropTarget* This, DWORD keys, POINTL pt, DWORD* effect) {
+	updateDropEffect((DropTarget*)This, pt, effect);
+	return S_OK;
+}
+
+static HRESULT STDMETHODCALLTYPE dtDragLeave(IDropTarget* This) {
+	winl_on_drag_leave(((DropTarget*)This)->hWnd);
+	return S_OK;
+}
+
+static HRESULT STDMETHODCALLTYPE dtDrop(IDropTarget* This, IDataObject* data, DWORD keys, POINTL pt, DWORD* effect) {
+	DropTarget* dt = (DropTarget*)This;
+	updateDropEffect(dt, pt, effect);
+	FORMATETC fmt = {CF_HDROP, NULL, DVASPECT_CONTENT, -1, TYMED_HGLOBAL};
+	STGMEDIUM stg;
+	if(dt->effect == DROPEFFECT_NONE || data->lpVtbl->GetData(data, &fmt, &stg) != S_OK) {
+		*effect = DROPEFFECT_NONE;
+		winl_on_drag_leave(dt->hWnd);
+		return S_OK;
+	}
+	HDROP hDrop = (HDROP)GlobalLock(stg.hGlobal);
+	UINT count = DragQueryFileW(hDrop, 0xFFFFFFFF, NULL, 0);
+	size_t size = 1, n = 0;
+	char* paths = malloc(size);
+	for(UINT i = 0; i < count; i++) {
+		UINT len = DragQueryFileW(hDrop, i, NULL, 0);
+		WCHAR* wpath = malloc((len + 1) * sizeof(WCHAR));
+		DragQueryFileW(hDrop, i, wpath, len + 1);
+		int u8len = WideCharToMultiByte(CP_UTF8, 0, wpath, -1, NULL, 0, NULL, NULL);
+		size += u8len;
+		paths = realloc(paths, size);
+		if(n > 0) {
+			paths[n++] = '\n';
+		}
+		WideCharToMultiByte(CP_UTF8, 0, wpath, -1, paths + n, u8len, NULL, NULL);
+		n += u8len - 1;
+		free(wpath);
+	}
+	paths[n] = 0;
+	GlobalUnlock(stg.hGlobal);
+	ReleaseStgMedium(&stg);
+	POINT p = dropPoint(dt, pt);
+	winl_on_drop(dt->hWnd, paths, p.x, p.y);
+	free(paths);
+	return S_OK;
+}
+
+static IDropTargetVtbl _dropTargetVtbl = {
+	dtQueryInterface, dtAddRef, dtRelease,
+	dtDragEnter, dtDragOver, dtDragLeave, dtDrop,
+};
+
+static IDropTarget* registerDropTarget(HWND hWnd) {
+	DropTarget* dt = malloc(sizeof(DropTarget));
+	memset(dt, 0, sizeof(DropTarget));
+	dt->iface.lpVtbl = &_dropTargetVtbl;
+	dt->ref = 1;
+	dt->hWnd = hWnd;
+	if(RegisterDragDrop(hWnd, &dt->iface) != S_OK) {
+		free(dt);
+		return NULL;
+	}
+	return &dt->iface;
+}
+
+// shcore.dll is Windows 8.1 and later, load at runtime
+typedef HRESULT (WINAPI *PFN_GetDpiForMonitor)(HMONITOR, int, UINT*, UINT*);
+typedef HRESULT (WINAPI *PFN_SetProcessDpiAwareness)(int);
+static PFN_GetDpiForMonitor _GetDpiForMonitor;
+
+static void initDpiAwareness() {
+	HMODULE shcore = LoadLibraryA("shcore.dll");
+	if(shcore) {
+		_GetDpiForMonitor = (PFN_GetDpiForMonitor)GetProcAddress(shcore, "GetDpiForMonitor");
+		PFN_SetProcessDpiAwareness setAwareness = (PFN_SetProcessDpiAwareness)GetProcAddress(shcore, "SetProcessDpiAwareness");
+		if(setAwareness) {
+			setAwareness(2); // PROCESS_PER_MONITOR_DPI_AWARE
+			return;
+		}
+	}
+	SetProcessDPIAware();
+}
+
+static float monitorScale(HMONITOR hMonitor) {
+	UINT dpiX = 96, dpiY = 96;
+	if(_GetDpiForMonitor) {
+		_GetDpiForMonitor(hMonitor, 0, &dpiX, &dpiY); // MDT_EFFECTIVE_DPI
+	} else {
+		HDC dc = GetDC(NULL);
+		dpiX = GetDeviceCaps(dc, LOGPIXELSX);
+		ReleaseDC(NULL, dc);
+	}
+	return dpiX / 96.0f;
+}
+
+static DWORD _uiThreadId;
 
 void MyRegisterClass()
 {
 	static BOOL _inited;
-	WNDCLASSEX wcex;
+	WNDCLASSEXW wcex;
 
 	if(_inited)
 		return;
 	_inited = TRUE;
 
+	// must before creating any window
+	initDpiAwareness();
+	// drag and drop requires single-threaded apartment
+	OleInitialize(NULL);
+	_uiThreadId = GetCurrentThreadId();
+
 	HINSTANCE hInstance = GetModuleHandle(NULL);
 
-	wcex.cbSize = sizeof(WNDCLASSEX);
+	wcex.cbSize = sizeof(WNDCLASSEXW);
 
 	wcex.style			= CS_HREDRAW | CS_VREDRAW | CS_OWNDC;
 	wcex.lpfnWndProc	= OpenGLWndProc;
@@ -61,7 +238,7 @@ void MyRegisterClass()
 	wcex.lpszClassName	= szOpenGLWndClass;
 	wcex.hIconSm		= NULL;//LoadIcon(wcex.hInstance, MAKEINTRESOURCE(IDI_SMALL));
 
-	RegisterClassEx(&wcex);
+	RegisterClassExW(&wcex);
 
 	return;
 }
@@ -83,6 +260,104 @@ static void get_mouse_pos(NativeWnd win, float *x, float *y) {
 	}
 }
 
+static int translateKey(WPARAM vk, LPARAM lParam) {
+	int extended = (lParam & 0x01000000) != 0;
+	if(vk >= '0' && vk <= '9') {
+		return WINL_KEY_0 + (int)(vk - '0');
+	}
+	if(vk >= 'A' && vk <= 'Z') {
+		return WINL_KEY_A + (int)(vk - 'A');
+	}
+	if(vk >= VK_F1 && vk <= VK_F12) {
+		return WINL_KEY_F1 + (int)(vk - VK_F1);
+	}
+	if(vk >= VK_NUMPAD0 && vk <= VK_NUMPAD9) {
+		return WINL_KEY_KP_0 + (int)(vk - VK_NUMPAD0);
+	}
+	switch(vk) {
+	case VK_SPACE:      return WINL_KEY_SPACE;
+	case VK_OEM_7:      return WINL_KEY_APOSTROPHE;
+	case VK_OEM_COMMA:  return WINL_KEY_COMMA;
+	case VK_OEM_MINUS:  return WINL_KEY_MINUS;
+	case VK_OEM_PERIOD: return WINL_KEY_PERIOD;
+	case VK_OEM_2:      return WINL_KEY_SLASH;
+	case VK_OEM_1:      return WINL_KEY_SEMICOLON;
+	case VK_OEM_PLUS:   return WINL_KEY_EQUAL;
+	case VK_OEM_4:      return WINL_KEY_LEFT_BRACKET;
+	case VK_OEM_5:      return WINL_KEY_BACKSLASH;
+	case VK_OEM_6:      return WINL_KEY_RIGHT_BRACKET;
+	case VK_OEM_3:      return WINL_KEY_GRAVE;
+	case VK_ESCAPE:     return WINL_KEY_ESCAPE;
+	case VK_RETURN:     return extended ? WINL_KEY_KP_ENTER : WINL_KEY_ENTER;
+	case VK_TAB:        return WINL_KEY_TAB;
+	case VK_BACK:       return WINL_KEY_BACKSPACE;
+	case VK_INSERT:     return WINL_KEY_INSERT;
+	case VK_DELETE:     return WINL_KEY_DELETE;
+	case VK_RIGHT:      return WINL_KEY_RIGHT;
+	case VK_LEFT:       return WINL_KEY_LEFT;
+	case VK_DOWN:       return WINL_KEY_DOWN;
+	case VK_UP:         return WINL_KEY_UP;
+	case VK_PRIOR:      return WINL_KEY_PAGE_UP;
+	case VK_NEXT:       return WINL_KEY_PAGE_DOWN;
+	case VK_HOME:       return WINL_KEY_HOME;
+	case VK_END:        return WINL_KEY_END;
+	case VK_CAPITAL:    return WINL_KEY_CAPS_LOCK;
+	case VK_SCROLL:     return WINL_KEY_SCROLL_LOCK;
+	case VK_NUMLOCK:    return WINL_KEY_NUM_LOCK;
+	case VK_SNAPSHOT:   return WINL_KEY_PRINT_SCREEN;
+	case VK_PAUSE:      return WINL_KEY_PAUSE;
+	case VK_APPS:       return WINL_KEY_MENU;
+	case VK_DECIMAL:    return WINL_KEY_KP_DECIMAL;
+	case VK_DIVIDE:     return WINL_KEY_KP_DIVIDE;
+	case VK_MULTIPLY:   return WINL_KEY_KP_MULTIPLY;
+	case VK_SUBTRACT:   return WINL_KEY_KP_SUBTRACT;
+	case VK_ADD:        return WINL_KEY_KP_ADD;
+	case VK_SHIFT:
+		// left and right shift share the extended flag, distinguish by scan code
+		if(MapVirtualKey((lParam >> 16) & 0xff, MAPVK_VSC_TO_VK_EX) == VK_RSHIFT) {
+			return WINL_KEY_RIGHT_SHIFT;
+		}
+		return WINL_KEY_LEFT_SHIFT;
+	case VK_CONTROL:    return extended ? WINL_KEY_RIGHT_CONTROL : WINL_KEY_LEFT_CONTROL;
+	case VK_MENU:       return extended ? WINL_KEY_RIGHT_ALT : WINL_KEY_LEFT_ALT;
+	case VK_LWIN:       return WINL_KEY_LEFT_SUPER;
+	case VK_RWIN:       return WINL_KEY_RIGHT_SUPER;
+	}
+	return WINL_KEY_UNKNOWN;
+}
+
+static int translateMods() {
+	int mods = 0;
+	if(GetKeyState(VK_SHIFT) & 0x8000) {
+		mods |= WINL_MOD_SHIFT;
+	}
+	if(GetKeyState(VK_CONTROL) & 0x8000) {
+		mods |= WINL_MOD_CONTROL;
+	}
+	if(GetKeyState(VK_MENU) & 0x8000) {
+		mods |= WINL_MOD_ALT;
+	}
+	if((GetKeyState(VK_LWIN) | GetKeyState(VK_RWIN)) & 0x8000) {
+		mods |= WINL_MOD_SUPER;
+	}
+	if(GetKeyState(VK_CAPITAL) & 1) {
+		mods |= WINL_MOD_CAPS_LOCK;
+	}
+	if(GetKeyState(VK_NUMLOCK) & 1) {
+		mods |= WINL_MOD_NUM_LOCK;
+	}
+	return mods;
+}
+
+// confine cursor in client area
+static void clipCursor(HWND hWnd) {
+	RECT rc;
+	GetClientRect(hWnd, &rc);
+	ClientToScreen(hWnd, (POINT*)&rc.left);
+	ClientToScreen(hWnd, (POINT*)&rc.right);
+	ClipCursor(&rc);
+}
+
 LRESULT CALLBACK OpenGLWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lParam) {
 	PAINTSTRUCT ps;
 	NativeWndData* wd = getWndData(hWnd);
@@ -92,7 +367,9 @@ LRESULT CALLBACK OpenGLWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lP
 		wd = malloc(sizeof(NativeWndData));
 		memset(wd, 0, sizeof(NativeWndData));
 		wd->hDC = GetDC(hWnd);
+		wd->cursor = LoadCursor(NULL, IDC_ARROW);
 		SetWindowLongPtr(hWnd, GWLP_USERDATA, (UINT_PTR)wd);
+		wd->dropTarget = registerDropTarget(hWnd);
 		InitOpenGL(hWnd);
 	} break; case WM_TIMER: {
 		//if(wParam == nTimerRedraw)
@@ -108,7 +385,7 @@ LRESULT CALLBACK OpenGLWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lP
 	} break; case WM_LBUTTONUP: {
 		wd->btnDown &= ~WINL_MOUSE_BTN_LEFT;
 		winl_on_mouse_release(hWnd, WINL_MOUSE_BTN_LEFT, GET_X_LPARAM(lParam), GET_Y_LPARAM(lParam));
-		if(wd->trackMouse && !wd->btnDown) {
+		if(wd->trackMouse && !wd->btnDown && !wd->grabbed && !wd->relative) {
 			ReleaseCapture();
 		}
 	} break; case WM_RBUTTONDOWN: {
@@ -120,7 +397,7 @@ LRESULT CALLBACK OpenGLWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lP
 	} break; case WM_RBUTTONUP: {
 		wd->btnDown &= ~WINL_MOUSE_BTN_RIGHT;
 		winl_on_mouse_release(hWnd, WINL_MOUSE_BTN_RIGHT, GET_X_LPARAM(lParam), GET_Y_LPARAM(lParam));
-		if(wd->trackMouse && !wd->btnDown) {
+		if(wd->trackMouse && !wd->btnDown && !wd->grabbed && !wd->relative) {
 			ReleaseCapture();
 		}
 	} break; case WM_MOUSEMOVE: {
@@ -130,11 +407,29 @@ LRESULT CALLBACK OpenGLWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lP
 			TrackMouseEvent(&tme); // one shot
 			winl_on_mouse_enter(hWnd, GET_X_LPARAM(lParam), GET_Y_LPARAM(lParam));
 		}
-		winl_on_mouse_move(hWnd, GET_X_LPARAM(lParam), GET_Y_LPARAM(lParam));
+		if(wd->relative) {
+			// motion made by SetCursorPos is at center, has no delta
+			POINT center;
+			RECT rc;
+			GetClientRect(hWnd, &rc);
+			center.x = rc.right / 2;
+			center.y = rc.bottom / 2;
+			int dx = GET_X_LPARAM(lParam) - center.x;
+			int dy = GET_Y_LPARAM(lParam) - center.y;
+			if(dx != 0 || dy != 0) {
+				winl_on_mouse_delta(hWnd, (float)dx, (float)dy);
+				ClientToScreen(hWnd, &center);
+				SetCursorPos(center.x, center.y);
+			}
+		} else {
+			winl_on_mouse_move(hWnd, GET_X_LPARAM(lParam), GET_Y_LPARAM(lParam));
+		}
 	} break; case WM_MOUSEWHEEL: {
-		winl_on_mouse_wheel(hWnd, 1, GET_WHEEL_DELTA_WPARAM(wParam));
+		// delta is WHEEL_DELTA a notch, precise devices send less
+		winl_on_mouse_wheel(hWnd, 1, (float)GET_WHEEL_DELTA_WPARAM(wParam) / WHEEL_DELTA);
 	} break; case WM_MOUSEHWHEEL: {
-		winl_on_mouse_wheel(hWnd, 0, GET_WHEEL_DELTA_WPARAM(wParam));
+		// tilting right is positive on Windows
+		winl_on_mouse_wheel(hWnd, 0, -(float)GET_WHEEL_DELTA_WPARAM(wParam) / WHEEL_DELTA);
 	} break; case WM_MOUSELEAVE: {
 		if(wd->trackMouse && wd->mouseHover) {
 			wd->mouseHover = 0;
@@ -142,20 +437,143 @@ LRESULT CALLBACK OpenGLWndProc(HWND hWnd, UINT message, WPARAM wParam, LPARAM lP
 			get_mouse_pos(hWnd, &x, &y);
 			winl_on_mouse_leave(hWnd, x, y);
 		}
+	} break; case WM_KEYDOWN: case WM_SYSKEYDOWN: {
+		int key = translateKey(wParam, lParam);
+		if(key != WINL_KEY_UNKNOWN) {
+			// bit 30 is the previous key state, set for auto-repeat
+			winl_on_key_press(hWnd, key, translateMods(), (lParam & 0x40000000) != 0);
+		}
+		if(message == WM_SYSKEYDOWN) {
+			// keep Alt+F4 and the system menu working
+			return DefWindowProcW(hWnd, message, wParam, lParam);
+		}
+	} break; case WM_KEYUP: case WM_SYSKEYUP: {
+		int key = translateKey(wParam, lParam);
+		if(key != WINL_KEY_UNKNOWN) {
+			winl_on_key_release(hWnd, key, translateMods());
+		}
+		if(message == WM_SYSKEYUP) {
+			return DefWindowProcW(hWnd, message, wParam, lParam);
+		}
+	} break; case WM_CHAR: case WM_SYSCHAR: {
+		WCHAR wc[3] = {0};
+		if(IS_HIGH_SURROGATE(wParam)) {
+			wd->highSurrogate = (WCHAR)wParam;
+			break;
+		}
+		if(IS_LOW_SURROGATE(wParam)) {
+			if(!wd->highSurrogate) {
+				break;
+			}
+			wc[0] = wd->highSurrogate;
+			wc[1] = (WCHAR)wParam;
+		} else {
+			wc[0] = (WCHAR)wParam;
+		}
+		wd->highSurrogate = 0;
+		char buf[16];
+		int n = WideCharToMultiByte(CP_UTF8, 0, wc, -1, buf, sizeof(buf), NULL, NULL);
+		if(n > 1) {
+			winl_on_text_input(hWnd, buf);
+		}
+		if(message == WM_SYSCHAR) {
+			return DefWindowProcW(hWnd, message, wParam, lParam);
+		}
 	} break; case WM_PAINT: {
 		HDC hdc = BeginPaint(hWnd, &ps);
 		RECT rc = ps.rcPaint;
 		winl_on_expose(hWnd, (float)(rc.left), (float)(rc.top), (float)(rc.right - rc.left), (float)(rc.bottom - rc.top));
 		EndPaint(hWnd, &ps);
 	} break; case WM_SIZE: {
-		winl_on_resize(hWnd, LOWORD(lParam), HIWORD(lParam));
+		int state = WINL_STATE_NORMAL;
+		if(wParam == SIZE_MINIMIZED) {
+			state = WINL_STATE_MINIMIZED;
+		} else if(wParam == SIZE_MAXIMIZED) {
+			state = WINL_STATE_MAXIMIZED;
+		}
+		if(state != wd->state) {
+			wd->state = state;
+			winl_on_state_change(hWnd, state);
+		}
+		// client area is 0x0 when minimized
+		if(state != WINL_STATE_MINIMIZED) {
+			winl_on_resize(hWnd, LOWORD(lParam), HIWORD(lParam));
+		}
+	} break; case WM_MOVE: {
+		// position of client area, ignore the far away position of minimized window
+		if(!IsIconic(hWnd)) {
+			winl_on_move(hWnd, (short)LOWORD(lParam), (short)HIWORD(lParam));
+		}
+	} break; case WM_GETMINMAXINFO: {
+		// sent before WM_CREATE
+		if(!wd) {
+			return DefWindowProcW(hWnd, message, wParam, lParam);
+		}
+		MINMAXINFO* mmi = (MINMAXINFO*)lParam;
+		DWORD style = (DWORD)GetWindowLongPtr(hWnd, GWL_STYLE);
+		DWORD exStyle = (DWORD)GetWindowLongPtr(hWnd, GWL_EXSTYLE);
+		if(wd->minWidth > 0 || wd->minHeight > 0) {
+			RECT rc = {0, 0, wd->minWidth, wd->minHeight};
+			AdjustWindowRectEx(&rc, style, FALSE, exStyle);
+			mmi->ptMinTrackSize.x = rc.right - rc.left;
+			mmi->ptMinTrackSize.y = rc.bottom - rc.top;
+		}
+		if(wd->maxWidth > 0 || wd->maxHeight > 0) {
+			RECT rc = {0, 0, wd->maxWidth, wd->maxHeight};
+			AdjustWindowRectEx(&rc, style, FALSE, exStyle);
+			if(wd->maxWidth > 0) {
+				mmi->ptMaxTrackSize.x = rc.right - rc.left;
+			}
+			if(wd->maxHeight > 0) {
+				mmi->ptMaxTrackSize.y = rc.bottom - rc.top;
+			}
+		}
+	} break; case WM_DPICHANGED: {
+		// notify before resize, so OnResize sees the new scale
+		winl_on_scale_changed(hWnd, HIWORD(wParam) / 96.0f);
+		RECT* rc = (RECT*)lParam;
+		SetWindowPos(hWnd, NULL, rc->left, rc->top, rc->right - rc->left, rc->bottom - rc->top,
+			SWP_NOZORDER | SWP_NOACTIVATE);
+	} break; case WM_SETCURSOR: {
+		// keep default cursors of borders
+		if(LOWORD(lParam) != HTCLIENT) {
+			return DefWindowProcW(hWnd, message, wParam, lParam);
+		}
+		SetCursor(wd->relative ? NULL : wd->cursor);
+		return TRUE;
+	} break; case WM_SETFOCUS: {
+		if(wd->relative) {
+			clipCursor(hWnd);
+		}
+		winl_on_focus(hWnd, 1);
+	} break; case WM_KILLFOCUS: {
+		if(wd->relative) {
+			ClipCursor(NULL);
+		}
+		winl_on_focus(hWnd, 0);
 	} break; case WM_DESTROY: {
 		winl_on_destroy(hWnd);
 		_windowCount--;
+		if(wd->dropTarget) {
+			RevokeDragDrop(hWnd);
+			wd->dropTarget->lpVtbl->Release(wd->dropTarget);
+		}
+		if(wd->iconBig) {
+			DestroyIcon(wd->iconBig);
+		}
+		if(wd->iconSmall) {
+			DestroyIcon(wd->iconSmall);
+		}
+		if(wd->hGLRC) {
+			if(wglGetCurrentContext() == wd->hGLRC) {
+				wglMakeCurrent(NULL, NULL);
+			}
+			wglDeleteContext(wd->hGLRC);
+		}
 		ReleaseDC(hWnd, wd->hDC);
 		free(wd);
 	} break; default: {
-		return DefWindowProc(hWnd, message, wParam, lParam);
+		return DefWindowProcW(hWnd, message, wParam, lParam);
 	}}
 	return 0;
 }
@@ -203,15 +621,19 @@ void InitOpenGL(HWND hWnd)
 	}
 
 
-	// HGLRC �ǹ�����, ֻ����һ��
+	// every window has its own context, objects are shared with the root
 	if (_hGLRC == NULL && !(_hGLRC = wglCreateContext(wd->hDC)))
 	{
-		winl_panicf("Failed to create opengl context"); // ���� OpenGL ʧ��
+		winl_panicf("Failed to create opengl context");
+		return;
+	}
+	if (!(wd->hGLRC = wglCreateContext(wd->hDC)) || !wglShareLists(_hGLRC, wd->hGLRC))
+	{
+		winl_panicf("Failed to create shared opengl context");
 		return;
 	}
 
-
-	if (!wglMakeCurrent(wd->hDC, _hGLRC))					// Try To Activate The Rendering Context
+	if (!wglMakeCurrent(wd->hDC, wd->hGLRC))					// Try To Activate The Rendering Context
 	{
 		winl_panicf("Failed to activate opengl context");
 		return;
@@ -263,10 +685,10 @@ void InitOpenGL(HWND hWnd)
 }
 
 static BOOL pumpMessage(MSG *msg) {
-	if(PeekMessage(msg, NULL, 0, 0, PM_REMOVE)) {
+	if(PeekMessageW(msg, NULL, 0, 0, PM_REMOVE)) {
 		if(msg->message != WM_QUIT) {
 			TranslateMessage(msg);
-			DispatchMessage(msg);
+			DispatchMessageW(msg);
 		}
 		return TRUE;
 	} else {
@@ -274,6 +696,15 @@ static BOOL pumpMessage(MSG *msg) {
 	}
 }
 
+void winl_wakeup() {
+	// any message breaks MsgWaitForMultipleObjectsEx
+	PostThreadMessageW(_uiThreadId, WM_NULL, 0, 0);
+}
+
+int winl_is_ui_thread() {
+	return GetCurrentThreadId() == _uiThreadId;
+}
+
 int winl_event_loop(){
 	winl_printf("winl_event_loop\n");
   MSG msg;
@@ -281,12 +712,17 @@ int winl_event_loop(){
 
 	winl_on_start();
 	while(_windowCount > 1) {
-		if(!pumpMessage(&msg)) {
-			Sleep(1);
-		} else if(msg.message == WM_QUIT) {
+		int busy = pumpMessage(&msg);
+		if(busy && msg.message == WM_QUIT) {
 			exitCode = (int)msg.wParam;
 			break;
 		}
+		// due timers, frames and posted work run after every message, so a steady stream of messages doesn't starve them
+		int timeout = winl_on_idle();
+		if(!busy) {
+			// sleep until message or next timer
+			MsgWaitForMultipleObjectsEx(0, NULL, timeout < 0 ? INFINITE : (DWORD)timeout, QS_ALLINPUT, MWMO_INPUTAVAILABLE);
+		}
 	}
 	winl_on_exit(exitCode);
 
@@ -302,6 +738,58 @@ void winl_get_screen_size(int *width, int *height) {
 	}
 }
 
+typedef struct MonitorEnum {
+	WinlMonitor* monitors;
+	int max;
+	int count;
+} MonitorEnum;
+
+static BOOL CALLBACK monitorEnumProc(HMONITOR hMonitor, HDC hdc, LPRECT rect, LPARAM lParam) {
+	MonitorEnum* me = (MonitorEnum*)lParam;
+	if(me->count >= me->max) {
+		return FALSE;
+	}
+	MONITORINFOEXW mi;
+	mi.cbSize = sizeof(mi);
+	if(!GetMonitorInfoW(hMonitor, (MONITORINFO*)&mi)) {
+		return TRUE;
+	}
+	WinlMonitor* m = me->monitors + me->count;
+	m->x = mi.rcMonitor.left;
+	m->y = mi.rcMonitor.top;
+	m->width = mi.rcMonitor.right - mi.rcMonitor.left;
+	m->height = mi.rcMonitor.bottom - mi.rcMonitor.top;
+	m->widthMM = 0;
+	m->heightMM = 0;
+	HDC dc = CreateDCW(L"DISPLAY", mi.szDevice, NULL, NULL);
+	if(dc) {
+		m->widthMM = GetDeviceCaps(dc, HORZSIZE);
+		m->heightMM = GetDeviceCaps(dc, VERTSIZE);
+		DeleteDC(dc);
+	}
+	m->scale = monitorScale(hMonitor);
+	m->primary = (mi.dwFlags & MONITORINFOF_PRIMARY) != 0;
+	me->count++;
+	return TRUE;
+}
+
+int winl_get_monitors(WinlMonitor* monitors, int max) {
+	MonitorEnum me = {monitors, max, 0};
+	EnumDisplayMonitors(NULL, NULL, monitorEnumProc, (LPARAM)&me);
+	return me.count;
+}
+
+float winl_get_content_scale(NativeWnd win) {
+	HMONITOR hMonitor;
+	if(win) {
+		hMonitor = MonitorFromWindow((HWND)win, MONITOR_DEFAULTTONEAREST);
+	} else {
+		POINT pt = {0, 0};
+		hMonitor = MonitorFromPoint(pt, MONITOR_DEFAULTTOPRIMARY);
+	}
+	return monitorScale(hMonitor);
+}
+
 NativeWnd winl_create(int ws, int width, int height) {
 	MyRegisterClass();
 
@@ -323,7 +811,7 @@ NativeWnd winl_create(int ws, int width, int height) {
 	}
 	RECT rect = {x, y, x+width, y+height};
 	AdjustWindowRect(&rect, style, FALSE);
-	HWND hWnd = CreateWindow(szOpenGLWndClass, "", style,
+	HWND hWnd = CreateWindowW(szOpenGLWndClass, L"", style,
 		rect.left, rect.top, rect.right-rect.left, rect.bottom-rect.top,
 		NULL, NULL, GetModuleHandle(NULL), NULL);
 	if (hWnd) {
@@ -338,12 +826,306 @@ void winl_show(NativeWnd win) {
 	}
 }
 
+void winl_get_position(NativeWnd win, float *x, float *y) {
+	POINT pt = {0, 0};
+	if(win) {
+		ClientToScreen((HWND)win, &pt);
+	}
+	if(x) {
+		*x = (float)pt.x;
+	}
+	if(y) {
+		*y = (float)pt.y;
+	}
+}
+
+void winl_move(NativeWnd win, float x, float y) {
+	if(win == 0) {
+		return;
+	}
+	RECT rc = {(LONG)x, (LONG)y, (LONG)x + 1, (LONG)y + 1};
+	AdjustWindowRectEx(&rc, (DWORD)GetWindowLongPtr((HWND)win, GWL_STYLE), FALSE,
+		(DWORD)GetWindowLongPtr((HWND)win, GWL_EXSTYLE));
+	SetWindowPos((HWND)win, NULL, rc.left, rc.top, 0, 0, SWP_NOSIZE | SWP_NOZORDER | SWP_NOACTIVATE);
+}
+
+void winl_resize(NativeWnd win, float width, float height) {
+	if(win == 0) {
+		return;
+	}
+	RECT rc = {0, 0, (LONG)width, (LONG)height};
+	AdjustWindowRectEx(&rc, (DWORD)GetWindowLongPtr((HWND)win, GWL_STYLE), FALSE,
+		(DWORD)GetWindowLongPtr((HWND)win, GWL_EXSTYLE));
+	SetWindowPos((HWND)win, NULL, 0, 0, rc.right - rc.left, rc.bottom - rc.top,
+		SWP_NOMOVE | SWP_NOZORDER | SWP_NOACTIVATE);
+}
+
+void winl_set_size_limits(NativeWnd win, float minWidth, float minHeight, float maxWidth, float maxHeight) {
+	if(win == 0) {
+		return;
+	}
+	NativeWndData* wd = getWndData((HWND)win);
+	wd->minWidth = (int)minWidth;
+	wd->minHeight = (int)minHeight;
+	wd->maxWidth = (int)maxWidth;
+	wd->maxHeight = (int)maxHeight;
+}
+
+void winl_set_state(NativeWnd win, int state) {
+	if(win == 0) {
+		return;
+	}
+	switch(state) {
+	case WINL_STATE_MINIMIZED:
+		ShowWindow((HWND)win, SW_MINIMIZE);
+		break;
+	case WINL_STATE_MAXIMIZED:
+		ShowWindow((HWND)win, SW_MAXIMIZE);
+		break;
+	default:
+		ShowWindow((HWND)win, SW_RESTORE);
+	}
+}
+
+void winl_focus(NativeWnd win) {
+	if(win == 0) {
+		return;
+	}
+	if(IsIconic((HWND)win)) {
+		ShowWindow((HWND)win, SW_RESTORE);
+	}
+	SetForegroundWindow((HWND)win);
+	SetFocus((HWND)win);
+}
+
 void winl_destroy(NativeWnd win) {
 	if(win) {
 		DestroyWindow((HWND)win);
 	}
 }
 
+static HWND _clipOwner; // message-only window, EmptyClipboard needs an owner
+static char* _primaryText; // no PRIMARY selection on Windows, keep in process
+
+// apply immediately if mouse is in client area, otherwise on next WM_SETCURSOR
+static void applyCursor(HWND hWnd, HCURSOR cursor) {
+	NativeWndData* wd = getWndData(hWnd);
+	wd->cursor = cursor;
+	if(wd->relative) {
+		return;
+	}
+	POINT pt;
+	RECT rc;
+	GetCursorPos(&pt);
+	ScreenToClient(hWnd, &pt);
+	GetClientRect(hWnd, &rc);
+	if(PtInRect(&rc, pt)) {
+		SetCursor(cursor);
+	}
+}
+
+int winl_grab_mouse(NativeWnd win, int grab) {
+	if(win == 0) {
+		return 0;
+	}
+	NativeWndData* wd = getWndData((HWND)win);
+	wd->grabbed = grab ? 1 : 0;
+	if(grab) {
+		SetCapture((HWND)win);
+	} else if(!wd->relative && !wd->btnDown) {
+		ReleaseCapture();
+	}
+	return 1;
+}
+
+// the cursor is hidden, confined and moved back to center after every motion
+int winl_set_relative_mouse(NativeWnd win, int enable) {
+	if(win == 0) {
+		return 0;
+	}
+	HWND hWnd = (HWND)win;
+	NativeWndData* wd = getWndData(hWnd);
+	enable = enable ? 1 : 0;
+	if(wd->relative == enable) {
+		return 1;
+	}
+	wd->relative = enable;
+	if(enable) {
+		SetCapture(hWnd);
+		clipCursor(hWnd);
+		SetCursor(NULL);
+		RECT rc;
+		GetClientRect(hWnd, &rc);
+		POINT center = {rc.right / 2, rc.bottom / 2};
+		ClientToScreen(hWnd, &center);
+		SetCursorPos(center.x, center.y);
+	} else {
+		ClipCursor(NULL);
+		SetCursor(wd->cursor);
+		if(!wd->grabbed && !wd->btnDown) {
+			ReleaseCapture();
+		}
+	}
+	return 1;
+}
+
+void winl_set_cursor(NativeWnd win, int shape) {
+	if(win == 0) {
+		return;
+	}
+	LPCTSTR id = IDC_ARROW;
+	switch(shape) {
+	case WINL_CURSOR_IBEAM: id = IDC_IBEAM; break;
+	case WINL_CURSOR_HRESIZE: id = IDC_SIZEWE; break;
+	case WINL_CURSOR_VRESIZE: id = IDC_SIZENS; break;
+	case WINL_CURSOR_HAND: id = IDC_HAND; break;
+	case WINL_CURSOR_CROSSHAIR: id = IDC_CROSS; break;
+	}
+	applyCursor((HWND)win, shape == WINL_CURSOR_HIDDEN ? NULL : LoadCursor(NULL, id));
+}
+
+// icon or cursor from image, rgba is not premultiplied
+static HICON createIconRGBA(const unsigned char* rgba, int width, int height, BOOL isIcon, int hotX, int hotY) {
+	BITMAPV5HEADER bi;
+	memset(&bi, 0, sizeof(bi));
+	bi.bV5Size = sizeof(bi);
+	bi.bV5Width = width;
+	bi.bV5Height = -height; // top-down
+	bi.bV5Planes = 1;
+	bi.bV5BitCount = 32;
+	bi.bV5Compression = BI_BITFIELDS;
+	bi.bV5RedMask = 0x00ff0000;
+	bi.bV5GreenMask = 0x0000ff00;
+	bi.bV5BlueMask = 0x000000ff;
+	bi.bV5AlphaMask = 0xff000000;
+
+	unsigned char* bits = NULL;
+	HDC dc = GetDC(NULL);
+	HBITMAP color = CreateDIBSection(dc, (BITMAPINFO*)&bi, DIB_RGB_COLORS, (void**)&bits, NULL, 0);
+	ReleaseDC(NULL, dc);
+	if(!color) {
+		return NULL;
+	}
+	HBITMAP mask = CreateBitmap(width, height, 1, 1, NULL);
+	if(!mask) {
+		DeleteObject(color);
+		return NULL;
+	}
+	for(int i = 0; i < width * height; i++) {
+		bits[i*4+0] = rgba[i*4+2];
+		bits[i*4+1] = rgba[i*4+1];
+		bits[i*4+2] = rgba[i*4+0];
+		bits[i*4+3] = rgba[i*4+3];
+	}
+
+	ICONINFO ii;
+	memset(&ii, 0, sizeof(ii));
+	ii.fIcon = isIcon;
+	ii.xHotspot = hotX;
+	ii.yHotspot = hotY;
+	ii.hbmMask = mask;
+	ii.hbmColor = color;
+	HICON icon = CreateIconIndirect(&ii);
+	DeleteObject(color);
+	DeleteObject(mask);
+	return icon;
+}
+
+WinlCursor winl_create_cursor(const unsigned char* rgba, int width, int height, int hotX, int hotY) {
+	return (WinlCursor)createIconRGBA(rgba, width, height, FALSE, hotX, hotY);
+}
+
+void winl_set_custom_cursor(NativeWnd win, WinlCursor cursor) {
+	if(win == 0 || cursor == 0) {
+		return;
+	}
+	applyCursor((HWND)win, (HCURSOR)cursor);
+}
+
+void winl_destroy_cursor(WinlCursor cursor) {
+	if(cursor) {
+		DestroyIcon((HICON)cursor);
+	}
+}
+
+void winl_set_clipboard_text(int primary, const char* text) {
+	if(primary) {
+		free(_primaryText);
+		_primaryText = _strdup(text);
+		return;
+	}
+	if(!_clipOwner) {
+		_clipOwner = CreateWindowW(L"STATIC", L"", 0, 0, 0, 0, 0, HWND_MESSAGE, NULL, GetModuleHandle(NULL), NULL);
+	}
+	int n = MultiByteToWideChar(CP_UTF8, 0, text, -1, NULL, 0);
+	HGLOBAL hMem = GlobalAlloc(GMEM_MOVEABLE, n * sizeof(WCHAR));
+	if(!hMem) {
+		return;
+	}
+	WCHAR* ws = (WCHAR*)GlobalLock(hMem);
+	MultiByteToWideChar(CP_UTF8, 0, text, -1, ws, n);
+	GlobalUnlock(hMem);
+	if(!OpenClipboard(_clipOwner)) {
+		winl_printf("warning: OpenClipboard() failed.\n");
+		GlobalFree(hMem);
+		return;
+	}
+	EmptyClipboard();
+	if(!SetClipboardData(CF_UNICODETEXT, hMem)) {
+		GlobalFree(hMem);
+	}
+	CloseClipboard();
+}
+
+char* winl_get_clipboard_text(int primary) {
+	if(primary) {
+		return _primaryText ? _strdup(_primaryText) : NULL;
+	}
+	if(!OpenClipboard(_clipOwner)) {
+		return NULL;
+	}
+	char* s = NULL;
+	HANDLE hMem = GetClipboardData(CF_UNICODETEXT);
+	if(hMem) {
+		WCHAR* ws = (WCHAR*)GlobalLock(hMem);
+		if(ws) {
+			int n = WideCharToMultiByte(CP_UTF8, 0, ws, -1, NULL, 0, NULL, NULL);
+			s = malloc(n);
+			WideCharToMultiByte(CP_UTF8, 0, ws, -1, s, n, NULL, NULL);
+			GlobalUnlock(hMem);
+		}
+	}
+	CloseClipboard();
+	return s;
+}
+
+void winl_set_text_input_rect(NativeWnd win, float x, float y, float width, float height) {
+	if(win == 0) {
+		return;
+	}
+	HIMC himc = ImmGetContext((HWND)win);
+	if(!himc) {
+		return;
+	}
+	COMPOSITIONFORM cf;
+	cf.dwStyle = CFS_POINT;
+	cf.ptCurrentPos.x = (LONG)x;
+	cf.ptCurrentPos.y = (LONG)y;
+	ImmSetCompositionWindow(himc, &cf);
+
+	CANDIDATEFORM cdf;
+	cdf.dwIndex = 0;
+	cdf.dwStyle = CFS_EXCLUDE;
+	cdf.ptCurrentPos.x = (LONG)x;
+	cdf.ptCurrentPos.y = (LONG)(y + height);
+	cdf.rcArea.left = (LONG)x;
+	cdf.rcArea.top = (LONG)y;
+	cdf.rcArea.right = (LONG)(x + width);
+	cdf.rcArea.bottom = (LONG)(y + height);
+	ImmSetCandidateWindow(himc, &cdf);
+	ImmReleaseContext((HWND)win, himc);
+}
+
 void winl_track_mouse(NativeWnd win, int enable) {
 	if(win == 0) {
 		return;
@@ -367,12 +1149,24 @@ void winl_track_mouse(NativeWnd win, int enable) {
 int winl_make_current(NativeWnd win) {
 	winl_printf("winl_make_current %ld", win);
 	if(win) {
-		return wglMakeCurrent(getWndData((HWND)win)->hDC, _hGLRC);
+		NativeWndData* wd = getWndData((HWND)win);
+		return wglMakeCurrent(wd->hDC, wd->hGLRC);
 	} else {
 		return wglMakeCurrent(NULL, NULL);
 	}
 }
 
+void* winl_get_context(NativeWnd win) {
+	if(!win) {
+		return NULL;
+	}
+	return getWndData((HWND)win)->hGLRC;
+}
+
+void* winl_current_context() {
+	return wglGetCurrentContext();
+}
+
 void winl_swap_buffers(NativeWnd win) {
 	winl_printf("winl_swap_buffers %ld", win);
 	if(win) {
@@ -380,6 +1174,21 @@ void winl_swap_buffers(NativeWnd win) {
 	}
 }
 
+typedef BOOL (WINAPI *PFN_wglSwapIntervalEXT)(int interval);
+
+void winl_set_swap_interval(NativeWnd win, int interval) {
+	if(!win) {
+		return;
+	}
+	// the interval is state of current context
+	NativeWndData* wd = getWndData((HWND)win);
+	wglMakeCurrent(wd->hDC, wd->hGLRC);
+	PFN_wglSwapIntervalEXT swapInterval = (PFN_wglSwapIntervalEXT)wglGetProcAddress("wglSwapIntervalEXT");
+	if(swapInterval) {
+		swapInterval(interval);
+	}
+}
+
 int winl_is_full_screen(NativeWnd win) {
 	if(!win) {
 		return 0;
@@ -434,6 +1243,88 @@ void winl_set_title(NativeWnd win, const char * title) {
 	free(buf);
 }
 
+// index of image closest to size
+static int closestIcon(int count, const int* sizes, int size) {
+	int best = 0;
+	for(int i = 1; i < count; i++) {
+		if(abs(sizes[i*2] - size) < abs(sizes[best*2] - size)) {
+			best = i;
+		}
+	}
+	return best;
+}
+
+static HICON iconOfSize(int count, const int* sizes, const unsigned char* rgba, int size) {
+	int k = closestIcon(count, sizes, size);
+	for(int i = 0; i < k; i++) {
+		rgba += sizes[i*2] * sizes[i*2+1] * 4;
+	}
+	return createIconRGBA(rgba, sizes[k*2], sizes[k*2+1], TRUE, 0, 0);
+}
+
+void winl_set_icon(NativeWnd win, int count, const int* sizes, const unsigned char* rgba) {
+	if(!win) {
+		return;
+	}
+	HWND hWnd = (HWND)win;
+	NativeWndData* wd = (NativeWndData*)GetWindowLongPtr(hWnd, GWLP_USERDATA);
+	HICON big = NULL, small = NULL;
+	if(count > 0) {
+		big = iconOfSize(count, sizes, rgba, GetSystemMetrics(SM_CXICON));
+		small = iconOfSize(count, sizes, rgba, GetSystemMetrics(SM_CXSMICON));
+	}
+	// NULL restores icon of window class
+	SendMessageW(hWnd, WM_SETICON, ICON_BIG, (LPARAM)big);
+	SendMessageW(hWnd, WM_SETICON, ICON_SMALL, (LPARAM)small);
+	if(wd->iconBig) {
+		DestroyIcon(wd->iconBig);
+	}
+	if(wd->iconSmall) {
+		DestroyIcon(wd->iconSmall);
+	}
+	wd->iconBig = big;
+	wd->iconSmall = small;
+}
+
+void winl_request_attention(NativeWnd win) {
+	if(!win) {
+		return;
+	}
+	FLASHWINFO fi;
+	memset(&fi, 0, sizeof(fi));
+	fi.cbSize = sizeof(fi);
+	fi.hwnd = (HWND)win;
+	fi.dwFlags = FLASHW_TRAY | FLASHW_TIMERNOFG;
+	FlashWindowEx(&fi);
+}
+
+static ITaskbarList3* _taskbar; // created on first use
+
+void winl_set_progress(NativeWnd win, float fraction) {
+	if(!win) {
+		return;
+	}
+	if(!_taskbar) {
+		if(CoCreateInstance(&CLSID_TaskbarList, NULL, CLSCTX_INPROC_SERVER,
+			&IID_ITaskbarList3, (void**)&_taskbar) != S_OK) {
+			_taskbar = NULL;
+			return;
+		}
+		if(_taskbar->lpVtbl->HrInit(_taskbar) != S_OK) {
+			_taskbar->lpVtbl->Release(_taskbar);
+			_taskbar = NULL;
+			return;
+		}
+	}
+	HWND hWnd = (HWND)win;
+	if(fraction < 0) {
+		_taskbar->lpVtbl->SetProgressState(_taskbar, hWnd, TBPF_NOPROGRESS);
+	} else {
+		_taskbar->lpVtbl->SetProgressState(_taskbar, hWnd, TBPF_NORMAL);
+		_taskbar->lpVtbl->SetProgressValue(_taskbar, hWnd, (ULONGLONG)(fraction * 1000), 1000);
+	}
+}
+
 void winl_expose(NativeWnd win, float x, float y, float width, float height) {
 	if(!win) {
 		return;
@@ -2,7 +2,10 @@ package winl
 
 // Auto generated file, do NOT edit!
 
-import "tetra/lib/factory"
+import (
+	"image"
+	"tetra/lib/factory"
+)
 
 var factoryRegisted bool
 
@@ -33,28 +36,98 @@ func (p *Window) Class() string {
 
 // IWindow is interface of class Window
 type IWindow interface {
+	// CenterOnScreen move the window to center of main screen
+	CenterOnScreen()
 	// Class name for factory
 	Class() string
+	// ContentScale reports how many pixels is an unit of UI, it is 1 for 96 DPI screen
+	ContentScale() float32
+	// Context returns the OpenGL context of window, contexts of all windows are in one share group
+	Context() uintptr
 	// Create the window, width and height can be zero.
 	Create(width, height int) error
 	// Destroy the window
 	Destroy()
 	// Expose triggle expose event
 	Expose(x, y, width, height float32)
+	// Focus bring window to front and make it active
+	Focus()
+	// GrabMouse capture the mouse, mouse events are delivered even if pointer is outside of window
+	GrabMouse() bool
+	// HasFocus determine if window is active and receives keyboard input
+	HasFocus() bool
 	// Init the object
 	Init()
+	// InjectDrop dispatch a synthetic event of files dropped at x, y
+	InjectDrop(paths []string, x, y float32)
+	// InjectFocus dispatch a synthetic event of window becoming active or inactive
+	InjectFocus(focused bool)
+	// InjectKey dispatch a synthetic key press or release event
+	InjectKey(key Key, mods Mod, press bool)
+	// InjectMouseDelta dispatch a synthetic mouse motion event of relative mouse mode
+	InjectMouseDelta(dx, dy float32)
+	// InjectMouseEnter dispatch a synthetic event of mouse entering the window
+	InjectMouseEnter(x, y float32)
+	// InjectMouseLeave dispatch a synthetic event of mouse leaving the window
+	InjectMouseLeave(x, y float32)
+	// InjectMouseMove dispatch a synthetic mouse move event
+	InjectMouseMove(x, y float32)
+	// InjectMousePress dispatch a synthetic mouse press event
+	InjectMousePress(btn int, x, y float32)
+	// InjectMouseRelease dispatch a synthetic mouse release event
+	InjectMouseRelease(btn int, x, y float32)
+	// InjectMouseWheel dispatch a synthetic mouse wheel event
+	InjectMouseWheel(vert bool, dz float32)
+	// InjectResize dispatch a synthetic resize event, the native window is not resized
+	InjectResize(width, height float32)
+	// InjectTextInput dispatch a synthetic text input event
+	InjectTextInput(text string)
 	// IsFullScreen determine if window is full screen
 	IsFullScreen() bool
+	// IsMaximized determine if window is maximized
+	IsMaximized() bool
+	// IsMinimized determine if window is minimized
+	IsMinimized() bool
+	// IsRelativeMouse reports whether relative mouse mode is enabled
+	IsRelativeMouse() bool
 	// IsVisible determine if window is visible
 	IsVisible() bool
 	// MakeCurrent set current OpenGL to this window
 	MakeCurrent() bool
+	// Maximize the window
+	Maximize()
+	// Minimize the window
+	Minimize()
+	// Move the window's client area to x, y of screen
+	Move(x, y float32)
 	// OnCreate event handler
 	OnCreate()
 	// OnDestroy event handler
 	OnDestroy()
+	// OnDragEnter event handler, files are dragged into the window
+	OnDragEnter(x, y float32)
+	// OnDragLeave event handler, files are dragged out or the drop is canceled
+	OnDragLeave()
+	// OnDragOver event handler, returns true if files can be dropped at x, y
+	OnDragOver(x, y float32) bool
+	// OnDrop event handler, paths are local files dropped at x, y
+	OnDrop(paths []string, x, y float32)
 	// OnExpose event handler
 	OnExpose(x, y, width, height float32)
+	// OnFocus event handler, focused is false when window becomes inactive
+	OnFocus(focused bool)
+	// OnFrame event handler, called every frame for window with HintVideo, dt is seconds since last frame
+	OnFrame(dt float32)
+	// OnKeyPress event handler, mods are held after the event, repeat is true if generated by auto-repeat
+	OnKeyPress(key Key, mods Mod, repeat bool)
+	// OnKeyRelease event handler, mods are held after the event
+	OnKeyRelease(key Key, mods Mod)
+	// OnMaximize event handler
+	OnMaximize()
+	// OnMinimize event handler
+	OnMinimize()
+	// OnMouseDelta event handler, called instead of OnMouseMove in relative mouse mode
+	OnMouseDelta(dx, dy float32)
 	// OnMouseEnter event handler
 	OnMouseEnter(x, y float32)
 	// OnMouseLeave event handler
@@ -65,14 +138,44 @@ type IWindow interface {
 	OnMousePress(btn int, x, y float32)
 	// OnMouseRelease event handler
 	OnMouseRelease(btn int, x, y float32)
-	// OnMouseWheel event handler
+	// OnMouseWheel event handler, dz is in notches of wheel, positive if it's rotated up or tilted left, fractions from precise devices
 	OnMouseWheel(vert bool, dz float32)
+	// OnMove event handler, x and y is position of client area in screen
+	OnMove(x, y float32)
 	// OnResize event handler
 	OnResize(width, height float32)
+	// OnRestore event handler, window is restored from minimized or maximized state
+	OnRestore()
+	// OnScaleChanged event handler, i.e. moved to monitor with different DPI
+	OnScaleChanged(scale float32)
+	// OnTextInput event handler, text is composed by keyboard layout or input method
+	OnTextInput(text string)
+	// Position reports position of window's client area in screen
+	Position() (x, y float32)
 	// Present copy OpenGL content from back buffer to front buffer, make it visible
 	Present()
+	// RequestAttention flashes taskbar entry or bounces dock icon until the window is focused
+	RequestAttention()
+	// Resize the window's client area
+	Resize(width, height float32)
+	// Restore the window from minimized or maximized state
+	Restore()
+	// SetCursor set shape of mouse cursor when it is over the window
+	SetCursor(c Cursor)
 	// SetHints set hints for window style
 	SetHints(hints hints)
+	// SetIcon set icon of the window, pass images of different sizes to let the system choose
+	SetIcon(images ...image.Image)
+	// SetMaxSize limit the maximum size of client area, 0 for no limit
+	SetMaxSize(width, height float32)
+	// SetMinSize limit the minimum size of client area, 0 for no limit
+	SetMinSize(width, height float32)
+	// SetProgress shows progress of long task in taskbar or dock, fraction in [0, 1], negative to hide, it's a no-op on X11
+	SetProgress(fraction float32)
+	// SetRelativeMouse enable relative mouse mode, pointer is hidden and locked, motion is reported by OnMouseDelta
+	SetRelativeMouse(enable bool) bool
+	// SetTextInputRect tells input method where text is edited, candidate window is placed near it
+	SetTextInputRect(x, y, width, height float32)
 	// SetTitle set the window title
 	SetTitle(title string)
 	// Show the window
@@ -81,4 +184,6 @@ type IWindow interface {
 	Size() (width, height float32)
 	// ToggleFullScreen switch between full screen mode and normal mode
 	ToggleFullScreen()
+	// UngrabMouse release the mouse captured by GrabMouse
+	UngrabMouse()
 }
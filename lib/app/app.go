@@ -42,6 +42,7 @@ func Run(onStart func() error, onExit func(err error)) {
 	// parse the command line options
 	flag.Usage = usage
 	flag.Parse()
+	winl.SetAutoConfirm(force)
 
 	for _, d := range []string{"log", "dict", "state", "layout"} {
 		store.MkdirAll(d)
@@ -50,6 +50,43 @@ func (r Rect) Contains(x, y float32) bool {
 	return true
 }
 
+// Intersect returns the common area of positive rects r and b, it's empty if they don't overlap.
+func (r Rect) Intersect(b Rect) (x Rect) {
+	for i := 0; i < 2; i++ {
+		x[i], x[i+2] = r[i], r[i+2]
+		if b[i] > x[i] {
+			x[i] = b[i]
+		}
+		if b[i+2] < x[i+2] {
+			x[i+2] = b[i+2]
+		}
+		if x[i+2] < x[i] {
+			x[i+2] = x[i]
+		}
+	}
+	return
+}
+
+// Union returns the smallest rect containing r and b, an empty rect is ignored
+func (r Rect) Union(b Rect) (x Rect) {
+	switch {
+	case r.IsEmpty():
+		return b
+	case b.IsEmpty():
+		return r
+	}
+	for i := 0; i < 2; i++ {
+		x[i], x[i+2] = r[i], r[i+2]
+		if b[i] < x[i] {
+			x[i] = b[i]
+		}
+		if b[i+2] > x[i+2] {
+			x[i+2] = b[i+2]
+		}
+	}
+	return
+}
+
 // IsNegative reports x1 < x0 or y1 < y0
 func (r Rect) IsNegative() bool { return r[2] < r[0] || r[3] < r[1] }
 
@@ -1,6 +1,7 @@
 package glman
 
 import (
+	"math"
 	"tetra/internal/gl"
 )
 
@@ -42,6 +43,7 @@ func bindDynArray30() {
 
 // DynFillRect fill rectangle
 func DynFillRect(rect Rect, color Color) {
+	DbgCheckThread()
 	p := UseProgSimpleDraw()
 	bindDynArray12()
 	gl.Uniform4fv(p.UniColors, 1, &color[0])
@@ -53,6 +55,8 @@ func DynFillRect(rect Rect, color Color) {
 		rect.X1(), rect.Y0(), 0}
 	gl.BufferData(gl.ARRAY_BUFFER, 12*4, gl.Ptr(&tmp[0]), gl.STREAM_DRAW)
 	DbgCheckError()
+	gl.EnableVertexAttribArray(uint32(p.AttPos))
+	DbgCheckError()
 	gl.VertexAttribPointer(uint32(p.AttPos), 3, gl.FLOAT, false, 0, gl.PtrOffset(0))
 	DbgCheckError()
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(4))
@@ -66,6 +70,7 @@ func DynDrawRect(rect Rect, color Color, lineWidth float32) {
 
 // DynDrawRectEx draw rectangle
 func DynDrawRectEx(rect Rect, color Color, szLeft, szRight, szTop, szBottom float32) {
+	DbgCheckThread()
 	p := UseProgSimpleDraw()
 	bindDynArray30()
 	gl.Uniform4fv(p.UniColors, 1, &color[0])
@@ -97,6 +102,8 @@ func DynDrawRectEx(rect Rect, color Color, szLeft, szRight, szTop, szBottom floa
 		x0, yb, 0}
 	gl.BufferData(gl.ARRAY_BUFFER, 30*4, gl.Ptr(&tmp[0]), gl.STREAM_DRAW)
 	DbgCheckError()
+	gl.EnableVertexAttribArray(uint32(p.AttPos))
+	DbgCheckError()
 	gl.VertexAttribPointer(uint32(p.AttPos), 3, gl.FLOAT, false, 0, gl.PtrOffset(0))
 	DbgCheckError()
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(10))
@@ -117,6 +124,8 @@ func DynDrawImageRect(rect Rect, color Color) {
 		rect.X1(), rect.Y0(), 0}
 	gl.BufferData(gl.ARRAY_BUFFER, 12*4, gl.Ptr(&tmp[0]), gl.STREAM_DRAW)
 	DbgCheckError()
+	gl.EnableVertexAttribArray(uint32(p.AttPos))
+	DbgCheckError()
 	gl.VertexAttribPointer(uint32(p.AttPos), 3, gl.FLOAT, false, 0, gl.PtrOffset(0))
 	DbgCheckError()
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(4))
@@ -128,19 +137,32 @@ type OptionDrawText int
 
 // options for draw text
 const (
-	DtTop        OptionDrawText = 0x00000000
-	DtLeft       OptionDrawText = 0x00000000
-	DtCenter     OptionDrawText = 0x00000001
-	DtRight      OptionDrawText = 0x00000002
-	DtVCenter    OptionDrawText = 0x00000004
-	DtBottom     OptionDrawText = 0x00000008
-	DtSingleLine OptionDrawText = 0x00000010
+	DtTop         OptionDrawText = 0x00000000
+	DtLeft        OptionDrawText = 0x00000000
+	DtCenter      OptionDrawText = 0x00000001
+	DtRight       OptionDrawText = 0x00000002
+	DtVCenter     OptionDrawText = 0x00000004
+	DtBottom      OptionDrawText = 0x00000008
+	DtSingleLine  OptionDrawText = 0x00000010 // line breaks are drawn as spaces
+	DtWordBreak   OptionDrawText = 0x00000020 // lines are wrapped at width of rect
+	DtEndEllipsis OptionDrawText = 0x00000040 // lines wider than rect end with "…"
 )
 
-// DynDrawText draw text
+// DynDrawText draw text in rect, aligned by options, lines are split at '\n' unless DtSingleLine
 func DynDrawText(s string, rect Rect, font Font, color Color, options OptionDrawText) {
+	DbgCheckThread()
+	f, k := pixelFont(font)
+	lines := f.layoutText(s, rect.Width(), options, k)
+	lh := float32(f.height) * k
+	y := rect.Y0()
+	switch {
+	case options&DtVCenter != 0:
+		y += (rect.Height() - lh*float32(len(lines))) * 0.5
+	case options&DtBottom != 0:
+		y = rect.Y1() - lh*float32(len(lines))
+	}
+
 	p := UseProgTexFont(false)
-	f := accessFont(font)
 	//bindDynArray30()
 	gl.Uniform4fv(p.UniColors, 1, &color[0])
 	DbgCheckError()
@@ -148,13 +170,38 @@ func DynDrawText(s string, rect Rect, font Font, color Color, options OptionDraw
 	DbgCheckError()
 
 	bindDynArray20()
+	gl.EnableVertexAttribArray(uint32(p.AttPos))
+	DbgCheckError()
+	gl.EnableVertexAttribArray(uint32(p.AttTC))
+	DbgCheckError()
 	gl.ActiveTexture(gl.TEXTURE0)
-	x0 := rect.X0()
-	y0 := rect.Y0() + f.lineGap
-	y1 := rect.Y0() + float32(f.height)
+	for _, line := range lines {
+		x := rect.X0()
+		if options&(DtCenter|DtRight) != 0 {
+			lw := f.lineWidth(line, k)
+			if options&DtCenter != 0 {
+				x += (rect.Width() - lw) * 0.5
+			} else {
+				x = rect.X1() - lw
+			}
+		}
+		f.drawLine(p, line, snapPixel(x), snapPixel(y), k)
+		y += lh
+	}
+}
+
+// snapPixel rounds v in UI units to the nearest pixel, so glyphs are not blurred
+func snapPixel(v float32) float32 {
+	return float32(math.Floor(float64(v*contentScale)+0.5)) / contentScale
+}
+
+// drawLine draws glyphs of s from x0, y0, program p is in use
+func (f *texFont) drawLine(p *Program, s string, x0, y0 float32, k float32) {
+	y1 := y0 + float32(f.height)*k
+	y0 += f.lineGap * k
 	for _, ch := range s {
 		g := f.loadGlyph(ch)
-		x1 := x0 + float32(g.w)
+		x1 := x0 + float32(g.w)*k
 
 		// padding 1 pixel is inluded, we use normalized space because the lack of texelFetch func
 		tx0 := float32(g.x) / float32(f.texsize)
@@ -181,6 +228,47 @@ func DynDrawText(s string, rect Rect, font Font, color Color, options OptionDraw
 		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(4))
 		DbgCheckError()
 
-		x0 = x1 - 2 //
+		x0 = x1 - 2*k //
+	}
+}
+
+// MeasureText reports size of single line text drawn by DynDrawText
+func MeasureText(s string, font Font) (width, height float32) {
+	DbgCheckThread()
+	f, k := pixelFont(font)
+	return f.lineWidth(s, k), float32(f.height) * k
+}
+
+// DynDrawImage draw image stretched to rect, color multiplies the pixels, a released image is not drawn
+func DynDrawImage(img *Image, rect Rect, color Color) {
+	DbgCheckThread()
+	if img.pt == nil {
+		return
+	}
+	p := UseProgImage()
+	gl.Uniform4fv(p.UniColors, 1, &color[0])
+	DbgCheckError()
+
+	bindDynArray20()
+	gl.EnableVertexAttribArray(uint32(p.AttPos))
+	DbgCheckError()
+	gl.EnableVertexAttribArray(uint32(p.AttTC))
+	DbgCheckError()
+	v := [4][5]float32{
+		{rect.X0(), rect.Y1(), 0, img.tx0, img.ty1}, // front face is CCW
+		{rect.X1(), rect.Y1(), 0, img.tx1, img.ty1}, //  2 3
+		{rect.X0(), rect.Y0(), 0, img.tx0, img.ty0}, //  |\|
+		{rect.X1(), rect.Y0(), 0, img.tx1, img.ty0}, //  0 1
 	}
+	gl.BufferData(gl.ARRAY_BUFFER, 20*4, gl.Ptr(&v[0][0]), gl.STREAM_DRAW)
+	DbgCheckError()
+	gl.VertexAttribPointer(uint32(p.AttPos), 3, gl.FLOAT, false, 5*4, gl.PtrOffset(0))
+	DbgCheckError()
+	gl.VertexAttribPointer(uint32(p.AttTC), 2, gl.FLOAT, false, 5*4, gl.PtrOffset(3*4))
+	DbgCheckError()
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, img.pt.x.ID())
+	DbgCheckError()
+	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, int32(4))
+	DbgCheckError()
 }
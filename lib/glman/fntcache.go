@@ -1,6 +1,9 @@
 package glman
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	durFreeFont = time.Second
@@ -43,3 +46,15 @@ func accessFont(name Font) (f *texFont) {
 	fntCache[name] = &fcItem{f: f, atime: now}
 	return f
 }
+
+// pixelFont returns font rasterized for current content scale,
+// and the factor to convert its pixels to UI units.
+func pixelFont(font Font) (f *texFont, k float32) {
+	size := font.Size()
+	ppem := int(float32(size)*contentScale + 0.5)
+	if ppem == size {
+		return accessFont(font), 1
+	}
+	f = accessFont(Font(fmt.Sprintf("%s %d", font.Name(), ppem)))
+	return f, float32(size) / float32(f.ppem)
+}
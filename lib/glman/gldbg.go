@@ -2,12 +2,21 @@ package glman
 
 import (
 	"fmt"
+	"tetra/internal/winl"
 	"tetra/lib/dbg"
 	"unsafe"
 
 	"tetra/internal/gl"
 )
 
+// DbgCheckThread panic if not called from UI thread, OpenGL context is only current on it.
+// it does nothing in release build.
+func DbgCheckThread() {
+	if dbg.Enabled && !winl.IsUIThread() {
+		panic("glman: OpenGL is called from non-UI thread, use winl.Post or winl.Call")
+	}
+}
+
 // DbgCheckError invoke glGetError and verify the return code, panic if got a error
 func DbgCheckError() {
 	x := gl.GetError()
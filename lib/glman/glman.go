@@ -46,10 +46,27 @@ var (
 	StackMatM = geom.Mat4Stack{geom.Mat4Ident()}
 	// StackClip2D is stack for 2D clipping
 	StackClip2D = Clip2DStack([]Rect{infClipRect})
+
+	// pixels per UI unit of current window, fonts are rasterized in pixels
+	contentScale float32 = 1
 )
 
+// SetContentScale set pixels per UI unit of the window being rendered
+func SetContentScale(scale float32) {
+	if scale <= 0 {
+		scale = 1
+	}
+	contentScale = scale
+}
+
+// ContentScale returns pixels per UI unit of the window being rendered
+func ContentScale() float32 {
+	return contentScale
+}
+
 // GetViewport is convenience wrapper for gl.Get(gl.VIEWPORT)
 func GetViewport() Rect {
+	DbgCheckThread()
 	var v [4]int32
 	gl.GetIntegerv(gl.VIEWPORT, &v[0])
 	return Rect{
@@ -61,5 +78,6 @@ func GetViewport() Rect {
 
 // SetViewport is convenience wrapper for gl.Viewport
 func SetViewport(rc Rect) {
+	DbgCheckThread()
 	gl.Viewport(int32(rc.X0()), int32(rc.Y0()), int32(rc.Width()), int32(rc.Height()))
 }
@@ -0,0 +1,312 @@
+package glman
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/vector"
+)
+
+// iconBox is size of the square icon paths are designed in
+const iconBox = 24
+
+// iconCacheLimit is the most rasterized icons kept, the least recently used one is released beyond it
+const iconCacheLimit = 64
+
+// pathSeg is a segment of icon path, op is one of 'M', 'L', 'Q', 'C', 'Z' with absolute points
+type pathSeg struct {
+	op   byte
+	args []float32
+}
+
+type iconKey struct {
+	name string
+	size int // in pixels
+}
+
+type iconEntry struct {
+	img  *Image
+	used uint64 // iconTick when it's drawn last time
+}
+
+var (
+	icons     = make(map[string][]pathSeg)
+	iconCache = make(map[iconKey]*iconEntry) // rasterized icons
+	iconTick  uint64
+)
+
+// built in icons, in SVG path data of 24x24 box, holes are drawn in reverse direction
+var builtinIcons = map[string]string{
+	"check":       "M4 12.5 L6 10.5 L10 14.5 L18 6.5 L20 8.5 L10 18.5 Z",
+	"close":       "M6.3 4.9 L12 10.6 L17.7 4.9 L19.1 6.3 L13.4 12 L19.1 17.7 L17.7 19.1 L12 13.4 L6.3 19.1 L4.9 17.7 L10.6 12 L4.9 6.3 Z",
+	"plus":        "M11 5 H13 V11 H19 V13 H13 V19 H11 V13 H5 V11 H11 Z",
+	"minus":       "M5 11 H19 V13 H5 Z",
+	"menu":        "M3 6 H21 V8 H3 Z M3 11 H21 V13 H3 Z M3 16 H21 V18 H3 Z",
+	"arrow-up":    "M6 15 L12 8 L18 15 Z",
+	"arrow-down":  "M6 9 H18 L12 16 Z",
+	"arrow-left":  "M15 6 V18 L8 12 Z",
+	"arrow-right": "M9 6 L16 12 L9 18 Z",
+	"box":         "M3 3 H21 V21 H3 Z M5 5 V19 H19 V5 Z",
+	"circle": "M12 3 C16.97 3 21 7.03 21 12 C21 16.97 16.97 21 12 21 C7.03 21 3 16.97 3 12 C3 7.03 7.03 3 12 3 Z " +
+		"M12 5 C8.13 5 5 8.13 5 12 C5 15.87 8.13 19 12 19 C15.87 19 19 15.87 19 12 C19 8.13 15.87 5 12 5 Z",
+	"dot": "M12 7 C14.76 7 17 9.24 17 12 C17 14.76 14.76 17 12 17 C9.24 17 7 14.76 7 12 C7 9.24 9.24 7 12 7 Z",
+}
+
+func init() {
+	for name, path := range builtinIcons {
+		if err := RegisterIcon(name, path); err != nil {
+			panic(name + ": " + err.Error())
+		}
+	}
+}
+
+// RegisterIcon adds or replaces icon of name, path is SVG path data in 24x24 box, of commands M, L, H, V, Q, C, Z, or lower case for relative
+func RegisterIcon(name, path string) error {
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	icons[name] = segs
+	for key, e := range iconCache {
+		if key.name == name {
+			e.img.Release()
+			delete(iconCache, key)
+		}
+	}
+	return nil
+}
+
+// HasIcon reports whether icon of name is registered
+func HasIcon(name string) bool {
+	_, ok := icons[name]
+	return ok
+}
+
+// parsePath parses SVG path data into absolute segments
+func parsePath(path string) (segs []pathSeg, err error) {
+	toks := tokenizePath(path)
+	var cmd byte
+	var cx, cy, sx, sy float32 // current and subpath start point
+	nums := func(n int) ([]float32, error) {
+		if len(toks) < n {
+			return nil, errors.New("missing numbers in path")
+		}
+		v := make([]float32, n)
+		for i := range v {
+			f, err := strconv.ParseFloat(toks[i], 32)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = float32(f)
+		}
+		toks = toks[n:]
+		return v, nil
+	}
+	for len(toks) > 0 {
+		if c := toks[0][0]; strings.IndexByte("MmLlHhVvQqCcZz", c) >= 0 {
+			cmd = c
+			toks = toks[1:]
+		} else if cmd == 0 {
+			return nil, errors.New("path must begin with command")
+		}
+		rel := cmd >= 'a'
+		var v []float32
+		switch cmd | 0x20 {
+		case 'm', 'l':
+			if v, err = nums(2); err != nil {
+				return
+			}
+		case 'h', 'v':
+			if v, err = nums(1); err != nil {
+				return
+			}
+		case 'q':
+			if v, err = nums(4); err != nil {
+				return
+			}
+		case 'c':
+			if v, err = nums(6); err != nil {
+				return
+			}
+		case 'z':
+			segs = append(segs, pathSeg{op: 'Z'})
+			cx, cy = sx, sy
+			cmd = 0
+			continue
+		}
+		switch cmd | 0x20 {
+		case 'h':
+			if rel {
+				v[0] += cx
+			}
+			v = []float32{v[0], cy}
+		case 'v':
+			if rel {
+				v[0] += cy
+			}
+			v = []float32{cx, v[0]}
+		default:
+			if rel {
+				for i := range v {
+					if i%2 == 0 {
+						v[i] += cx
+					} else {
+						v[i] += cy
+					}
+				}
+			}
+		}
+		op := cmd &^ 0x20
+		if op == 'H' || op == 'V' {
+			op = 'L'
+		}
+		segs = append(segs, pathSeg{op: op, args: v})
+		cx, cy = v[len(v)-2], v[len(v)-1]
+		if op == 'M' {
+			sx, sy = cx, cy
+			// numbers after moveto are lineto
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		}
+	}
+	return segs, nil
+}
+
+// tokenizePath splits path data into commands and numbers
+func tokenizePath(path string) (toks []string) {
+	start := -1
+	dot := false
+	flush := func(i int) {
+		if start >= 0 {
+			toks = append(toks, path[start:i])
+			start = -1
+		}
+	}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if start < 0 {
+				start, dot = i, false
+			}
+		case c == '.':
+			// "1.5.5" is two numbers
+			if start >= 0 && dot {
+				flush(i)
+			}
+			if start < 0 {
+				start = i
+			}
+			dot = true
+		case c == '-' || c == '+':
+			// sign begins a number, unless it's of exponent
+			if start >= 0 && (path[i-1] == 'e' || path[i-1] == 'E') {
+				break
+			}
+			flush(i)
+			start, dot = i, false
+		case c == 'e' || c == 'E':
+			if start < 0 {
+				return nil
+			}
+		case strings.IndexByte("MmLlHhVvQqCcZz", c) >= 0:
+			flush(i)
+			toks = append(toks, path[i:i+1])
+		default:
+			flush(i)
+		}
+	}
+	flush(len(path))
+	return
+}
+
+// iconImage returns icon rasterized to size pixels
+func iconImage(name string, size int) (*Image, error) {
+	key := iconKey{name, size}
+	iconTick++
+	if e, ok := iconCache[key]; ok {
+		e.used = iconTick
+		return e.img, nil
+	}
+	segs, ok := icons[name]
+	if !ok {
+		return nil, errors.New("icon not found: " + name)
+	}
+	k := float32(size) / iconBox
+	r := vector.NewRasterizer(size, size)
+	r.DrawOp = draw.Src
+	for _, seg := range segs {
+		a := seg.args
+		switch seg.op {
+		case 'M':
+			r.MoveTo(a[0]*k, a[1]*k)
+		case 'L':
+			r.LineTo(a[0]*k, a[1]*k)
+		case 'Q':
+			r.QuadTo(a[0]*k, a[1]*k, a[2]*k, a[3]*k)
+		case 'C':
+			r.CubeTo(a[0]*k, a[1]*k, a[2]*k, a[3]*k, a[4]*k, a[5]*k)
+		case 'Z':
+			r.ClosePath()
+		}
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, size, size))
+	r.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	// white with alpha of mask, colored by DynDrawImage
+	pix := image.NewNRGBA(mask.Bounds())
+	for i, a := range mask.Pix {
+		pix.Pix[i*4], pix.Pix[i*4+1], pix.Pix[i*4+2], pix.Pix[i*4+3] = 255, 255, 255, a
+	}
+	img, err := NewImage(pix)
+	if err != nil {
+		return nil, err
+	}
+	if len(iconCache) >= iconCacheLimit {
+		evictIcon()
+	}
+	iconCache[key] = &iconEntry{img, iconTick}
+	return img, nil
+}
+
+// evictIcon releases the least recently used icon in cache
+func evictIcon() {
+	var oldest iconKey
+	var used uint64
+	for key, e := range iconCache {
+		if used == 0 || e.used < used {
+			oldest, used = key, e.used
+		}
+	}
+	if e, ok := iconCache[oldest]; ok {
+		e.img.Release()
+		delete(iconCache, oldest)
+	}
+}
+
+// DynDrawIcon draw icon of name in the largest square fits rect, centered
+func DynDrawIcon(name string, rect Rect, color Color) {
+	DbgCheckThread()
+	s := rect.Width()
+	if rect.Height() < s {
+		s = rect.Height()
+	}
+	// rasterized at pixel size of screen, so it's sharp at any size
+	size := int(s*contentScale + 0.5)
+	if size <= 0 {
+		return
+	}
+	img, err := iconImage(name, size)
+	if err != nil {
+		return
+	}
+	s = float32(size) / contentScale
+	x0 := snapPixel(rect.X0() + (rect.Width()-s)*0.5)
+	y0 := snapPixel(rect.Y0() + (rect.Height()-s)*0.5)
+	DynDrawImage(img, Rect{x0, y0, x0 + s, y0 + s}, color)
+}
@@ -59,6 +59,7 @@ func (m *mText) SetDrawEdge(b bool) {
 }
 
 func (m *mText) Render() {
+	DbgCheckThread()
 	if len(m.segs) == 0 {
 		return
 	}
@@ -91,7 +92,8 @@ func (m *mText) Render() {
 	}
 }
 
-func (f *texFont) mkMText(s string, width, height float32, options uint32) (m *mText) {
+// mkMText make text model, k converts font pixels to UI units
+func (f *texFont) mkMText(s string, width, height float32, options uint32, k float32) (m *mText) {
 	m = new(mText)
 	runtime.SetFinalizer(m, finalizeMText)
 	m.f = f
@@ -106,12 +108,12 @@ func (f *texFont) mkMText(s string, width, height float32, options uint32) (m *m
 
 	var vas = make([][][5]float32, len(f.textures)) // [texture][vertex][x,y,z,tx,ty]
 	x0 := float32(0)
-	y0 := f.lineGap
-	y1 := float32(f.height)
+	y0 := f.lineGap * k
+	y1 := float32(f.height) * k
 	for _, ch := range s {
 		g := f.loadGlyph(ch)
 		m.gs = append(m.gs, g)
-		x1 := x0 + float32(g.w)
+		x1 := x0 + float32(g.w)*k
 
 		// padding 1 pixel is inluded, we use normalized space because the lack of texelFetch func
 		tx0 := float32(g.x) / float32(f.texsize)
@@ -133,7 +135,7 @@ func (f *texFont) mkMText(s string, width, height float32, options uint32) (m *m
 		}
 		vas[g.tex] = append(vas[g.tex], v[0], v[1], v[2], v[3], v[3]) // also append degenerated triangle
 
-		x0 = x1 - 2 //
+		x0 = x1 - 2*k //
 	}
 
 	// merge into single array
@@ -157,5 +159,7 @@ func (f *texFont) mkMText(s string, width, height float32, options uint32) (m *m
 
 // MkMText create text model
 func (f Font) MkMText(s string, width, height float32, options uint32) MText {
-	return accessFont(f).mkMText(s, width, height, options)
+	DbgCheckThread()
+	tf, k := pixelFont(f)
+	return tf.mkMText(s, width, height, options, k)
 }
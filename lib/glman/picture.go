@@ -1,6 +1,9 @@
 package glman
 
 import (
+	"errors"
+	"image"
+	"image/draw"
 	"tetra/internal/gl"
 	"unsafe"
 )
@@ -14,7 +17,8 @@ var (
 
 // Image quad
 type Image struct {
-	pt  *packTex
+	pt  *packTex // nil after Release
+	rc  i32Rect  // space in pack texture, with padding
 	w   int32
 	h   int32
 	tx0 float32
@@ -25,6 +29,12 @@ type Image struct {
 
 type i32Rect struct{ x, y, w, h int32 }
 
+// contains reports whether b is in r
+func (r i32Rect) contains(b i32Rect) bool {
+	return b.x >= r.x && b.y >= r.y && b.x+b.w <= r.x+r.w && b.y+b.h <= r.y+r.h
+}
+
+// ptTree is a node of space in pack texture, a leaf is used or free, or it's split into s0 and s1
 type ptTree struct {
 	r    i32Rect
 	s0   *ptTree
@@ -34,67 +44,66 @@ type ptTree struct {
 
 // pack small pictures into large texture
 type packTex struct {
-	t ptTree
-	x *Res
+	t    ptTree
+	x    *Res
+	size int32
+	n    int  // number of images in it
+	own  bool // texture of one large image, deleted with the image
 }
 
 func (t *ptTree) alloc(w, h int32) (ret i32Rect, ok bool) {
 	if w <= 0 || h <= 0 {
 		return
 	}
-	//ASSERT(s0 && s1 || !s0 && !s1);
-
-	if t.s0 != nil && t.s1 != nil {
+	if t.s0 != nil {
 		if ret, ok = t.s0.alloc(w, h); ok {
 			return
 		}
 		return t.s1.alloc(w, h)
 	}
 	if w > t.r.w || h > t.r.h || t.used {
-		ok = false
 		return
 	}
 	if w == t.r.w && h == t.r.h {
 		t.used = true
 		return t.r, true
 	}
-	if h == t.r.h {
-		ret = t.r
-		ret.w = w
-		t.r.x += w
-		t.r.w -= w
-		return ret, true
-	}
-	if w == t.r.w {
-		ret = t.r
-		ret.h = h
-		t.r.y += h
-		t.r.h -= h
-		return ret, true
-	}
-	t.s0 = new(ptTree)
-	t.s0.r = t.r
-	t.s1 = new(ptTree)
-	t.s1.r = t.r
+	// split along the longer side left, s0 fits w or h exactly
+	t.s0, t.s1 = &ptTree{r: t.r}, &ptTree{r: t.r}
 	dx := t.r.w - w
 	dy := t.r.h - h
 	if dx > dy {
-		t.s0.r.x += w
-		t.s0.r.w -= w
-		t.s1.r.w = w
-		t.s1.r.y += h
-		t.s1.r.h -= h
+		t.s0.r.w = w
+		t.s1.r.x += w
+		t.s1.r.w = dx
 	} else {
 		t.s0.r.h = h
-		t.s0.r.x += w
-		t.s0.r.w -= w
 		t.s1.r.y += h
-		t.s1.r.h -= h
+		t.s1.r.h = dy
 	}
-	ret = t.r
-	ret.w = w
-	ret.h = h
-	return ret, true
+	return t.s0.alloc(w, h)
+}
+
+// free the space allocated as rc, nodes become free are merged, returns false if rc is not allocated
+func (t *ptTree) free(rc i32Rect) bool {
+	if t.s0 == nil {
+		if !t.used || t.r != rc {
+			return false
+		}
+		t.used = false
+		return true
+	}
+	s := t.s1
+	if t.s0.r.contains(rc) {
+		s = t.s0
+	}
+	if !s.free(rc) {
+		return false
+	}
+	if t.s0.s0 == nil && !t.s0.used && t.s1.s0 == nil && !t.s1.used {
+		t.s0, t.s1 = nil, nil
+	}
+	return true
 }
 
 func newPackTex(size int32) *packTex {
@@ -104,6 +113,7 @@ func newPackTex(size int32) *packTex {
 	ct.t.r.y = 0
 	ct.t.r.w = size
 	ct.t.r.h = size
+	ct.size = size
 
 	ct.x = GenTexture("*packTex")
 
@@ -141,3 +151,71 @@ func allocPicSpace(w, h int32) (pt *packTex, rect i32Rect) {
 	pt = nil
 	return
 }
+
+// NewImage copies img into a pack texture, the space is kept until Release.
+// a picture too large to pack gets its own texture.
+func NewImage(img image.Image) (*Image, error) {
+	DbgCheckThread()
+	b := img.Bounds()
+	w, h := int32(b.Dx()), int32(b.Dy())
+	if w <= 0 || h <= 0 {
+		return nil, errors.New("empty image")
+	}
+	// padding 1 pixel around picture
+	var pt *packTex
+	var rc i32Rect
+	if w+2 > sizePackTex || h+2 > sizePackTex {
+		size := w + 2
+		if h+2 > size {
+			size = h + 2
+		}
+		pt = newPackTex(size)
+		pt.own = true
+		rc, _ = pt.t.alloc(w+2, h+2)
+	} else {
+		pt, rc = allocPicSpace(w+2, h+2)
+	}
+	if pt == nil {
+		return nil, errors.New("no space for image")
+	}
+
+	pix := image.NewNRGBA(image.Rect(0, 0, int(w), int(h)))
+	draw.Draw(pix, pix.Bounds(), img, b.Min, draw.Src)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.BindTexture(gl.TEXTURE_2D, pt.x.ID())
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, rc.x+1, rc.y+1, w, h, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(&pix.Pix[0]))
+	DbgCheckError()
+
+	pt.n++
+	size := float32(pt.size)
+	return &Image{
+		pt:  pt,
+		rc:  rc,
+		w:   w,
+		h:   h,
+		tx0: float32(rc.x+1) / size,
+		tx1: float32(rc.x+1+w) / size,
+		ty0: float32(rc.y+1) / size,
+		ty1: float32(rc.y+1+h) / size,
+	}, nil
+}
+
+// Size reports size of image in pixels
+func (img *Image) Size() (width, height int) {
+	return int(img.w), int(img.h)
+}
+
+// Release frees the space of image in texture, it's not drawn any more
+func (img *Image) Release() {
+	DbgCheckThread()
+	pt := img.pt
+	if pt == nil {
+		return
+	}
+	img.pt = nil
+	pt.t.free(img.rc)
+	pt.n--
+	if pt.own && pt.n == 0 {
+		pt.x.Release()
+	}
+}
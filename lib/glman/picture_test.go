@@ -0,0 +1,43 @@
+//go:build headless
+// +build headless
+
+package glman
+
+import "testing"
+
+func TestPackTree(t *testing.T) {
+	var tr ptTree
+	tr.r = i32Rect{0, 0, 64, 64}
+	var rcs []i32Rect
+	for i := 0; i < 16; i++ {
+		rc, ok := tr.alloc(16, 16)
+		if !ok {
+			t.Fatalf("alloc %d failed", i)
+		}
+		rcs = append(rcs, rc)
+	}
+	if _, ok := tr.alloc(16, 16); ok {
+		t.Error("alloc in full tree succeeded")
+	}
+
+	// freed space is reused
+	if !tr.free(rcs[5]) || tr.free(rcs[5]) {
+		t.Error("free twice, want true then false")
+	}
+	if rc, ok := tr.alloc(16, 16); !ok || rc != rcs[5] {
+		t.Errorf("alloc after free = %v, %v, want %v", rc, ok, rcs[5])
+	}
+
+	// all freed, nodes are merged, so that a large one fits
+	for _, rc := range rcs {
+		if !tr.free(rc) {
+			t.Errorf("free(%v) failed", rc)
+		}
+	}
+	if tr.s0 != nil || tr.used {
+		t.Error("empty tree is not merged")
+	}
+	if _, ok := tr.alloc(64, 64); !ok {
+		t.Error("alloc whole space failed after free")
+	}
+}
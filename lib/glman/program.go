@@ -112,6 +112,7 @@ func newProgram(id uint32) *Program {
 
 // UseProgram use the program, same as gl.UseProgram(p.ID)
 func (p *Program) UseProgram() {
+	DbgCheckThread()
 	//dbg.Logln(p.ID)
 	//DbgCheckError()
 	gl.UseProgram(p.ID)
@@ -288,6 +289,7 @@ func link(shaders []uint32) (program uint32, err error) {
 
 // LoadProgram load and link shaders into program, cached in memory.
 func LoadProgram(files ...string) (p *Program, err error) {
+	DbgCheckThread()
 	if len(files) == 0 {
 		return nil, errors.New("no source files")
 	}
@@ -5,6 +5,7 @@ var (
 	progTexFont     *Program
 	progTexFontEdge *Program
 	progSimpleDraw  *Program
+	progImage       *Program
 
 	//progSimpleTex   *Program
 	//progColorDraw *Program
@@ -41,3 +42,15 @@ func UseProgSimpleDraw() (p *Program) {
 	p.LoadClip2DStack()
 	return p
 }
+
+// UseProgImage load and use the image program
+func UseProgImage() (p *Program) {
+	if progImage == nil {
+		progImage = MustLoadProgram("texfont.vert", "image.frag")
+	}
+	p = progImage
+	p.UseProgram()
+	p.LoadMVPStack()
+	p.LoadClip2DStack()
+	return p
+}
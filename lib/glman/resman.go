@@ -5,6 +5,7 @@ import (
 	"runtime"
 	"sync/atomic"
 	"tetra/internal/gl"
+	"tetra/internal/winl"
 	"tetra/lib/dbg"
 )
 
@@ -26,29 +27,49 @@ var (
 	//ProvideBuffer      Provider
 )
 
+// resKey identifies cached object, ctx is 0 for objects shared by all contexts
+type resKey struct {
+	ctx  uintptr
+	name string
+}
+
 var (
 	pendingDestroys [maxType][]*sharedRes
 	mutexResMan     = make(chan int, 1)
 
-	resCaches [maxType]map[string]*sharedRes
+	resCaches [maxType]map[resKey]*sharedRes
+
+	// objects can't be shared between contexts, they are destroyed with the context
+	ctxObjects = make(map[*sharedRes]struct{})
 )
 
 func init() {
 	for i := range resCaches {
-		resCaches[i] = make(map[string]*sharedRes)
+		resCaches[i] = make(map[resKey]*sharedRes)
 	}
 }
 
-// Routine will destory all pending object
+// Routine will destory all pending object,
+// objects of other context are kept until that context is current.
 func Routine() {
+	DbgCheckThread()
+	cur := winl.CurrentContext()
 	mutexResMan <- 1
 	for typ, s := range pendingDestroys {
 		if len(s) == 0 {
 			continue
 		}
-		for i, r := range s {
-			if r.name != "" {
-				delete(resCaches[typ], r.name)
+		keep := s[:0]
+		for _, r := range s {
+			if r.ctx != 0 && r.ctx != cur {
+				keep = append(keep, r)
+				continue
+			}
+			if r.name != "" && resCaches[typ][resKey{r.ctx, r.name}] == r {
+				delete(resCaches[typ], resKey{r.ctx, r.name})
+			}
+			if r.ctx != 0 {
+				delete(ctxObjects, r)
 			}
 			switch typ {
 			case tTexture:
@@ -64,11 +85,43 @@ func Routine() {
 				gl.DeleteBuffers(1, &r.id)
 				DbgCheckError()
 			default:
-				panic(fmt.Sprintf("destroy type %d", i))
+				panic(fmt.Sprintf("destroy type %d", typ))
 			}
+		}
+		for i := len(keep); i < len(s); i++ {
 			s[i] = nil
 		}
-		pendingDestroys[typ] = s[:0]
+		pendingDestroys[typ] = keep
+	}
+	<-mutexResMan
+}
+
+// ForgetContext drops objects of a context which is being destroyed, they are freed with the context.
+// references to them remain valid Go values, but ID reports 0.
+func ForgetContext(ctx uintptr) {
+	DbgCheckThread()
+	if ctx == 0 {
+		return
+	}
+	mutexResMan <- 1
+	for typ, s := range pendingDestroys {
+		keep := s[:0]
+		for _, r := range s {
+			if r.ctx != ctx {
+				keep = append(keep, r)
+			}
+		}
+		for i := len(keep); i < len(s); i++ {
+			s[i] = nil
+		}
+		pendingDestroys[typ] = keep
+	}
+	for r := range ctxObjects {
+		if r.ctx == ctx {
+			delete(ctxObjects, r)
+			delete(resCaches[r.typ], resKey{r.ctx, r.name})
+			r.id = 0
+		}
 	}
 	<-mutexResMan
 }
@@ -80,6 +133,7 @@ type sharedRes struct {
 	ref  int32
 	ac   uint32
 	name string
+	ctx  uintptr // context owns the object, 0 if it's shareable
 }
 
 // Res is pointer to resource
@@ -100,7 +154,10 @@ func release(r *Res) {
 			panic(fmt.Sprintf("free: %s", r))
 		}
 		mutexResMan <- 1
-		pendingDestroys[s.typ] = append(pendingDestroys[s.typ], s)
+		if s.id != 0 {
+			// 0 if destroyed with its context
+			pendingDestroys[s.typ] = append(pendingDestroys[s.typ], s)
+		}
 		<-mutexResMan
 		r.s = nil
 	}
@@ -168,6 +225,7 @@ func ref(s *sharedRes) *Res {
 
 // GenTexture is wrapper for gl.GenTextures
 func GenTexture(name string) *Res {
+	DbgCheckThread()
 	s := &sharedRes{typ: tTexture, name: name}
 	gl.GenTextures(1, &s.id)
 	DbgCheckError()
@@ -176,10 +234,11 @@ func GenTexture(name string) *Res {
 
 // LoadTexture load and cache texture by name, must set ProvideTexture before call this function.
 func LoadTexture(name string) *Res {
+	DbgCheckThread()
 	if name == "" {
 		return nil
 	}
-	if s, ok := resCaches[tTexture][name]; ok {
+	if s, ok := resCaches[tTexture][resKey{name: name}]; ok {
 		return ref(s)
 	}
 	if ProvideTexture == nil {
@@ -189,22 +248,47 @@ func LoadTexture(name string) *Res {
 	if r == nil {
 		return nil
 	}
-	resCaches[tTexture][name] = r.s
+	resCaches[tTexture][resKey{name: name}] = r.s
 	return r
 }
 
 // GenBuffer is wrapper for gl.GenBuffers
 func GenBuffer(name string) *Res {
+	DbgCheckThread()
 	s := &sharedRes{typ: tBuffer, name: name}
 	gl.GenBuffers(1, &s.id)
 	DbgCheckError()
 	return ref(s)
 }
 
-// GenVertexArray is wrapper for gl.GenVertexArrays
+// GenVertexArray is wrapper for gl.GenVertexArrays, the vertex array belongs to current context.
 func GenVertexArray(name string) *Res {
-	s := &sharedRes{typ: tVertexArray, name: name}
+	DbgCheckThread()
+	s := &sharedRes{typ: tVertexArray, name: name, ctx: winl.CurrentContext()}
 	gl.GenVertexArrays(1, &s.id)
 	DbgCheckError()
+	mutexResMan <- 1
+	ctxObjects[s] = struct{}{}
+	<-mutexResMan
 	return ref(s)
 }
+
+// LoadVertexArray returns vertex array cached by name for current context,
+// setup is called with the vertex array bound when it's created in a context.
+func LoadVertexArray(name string, setup func()) *Res {
+	DbgCheckThread()
+	key := resKey{winl.CurrentContext(), name}
+	if s, ok := resCaches[tVertexArray][key]; ok && s.id != 0 {
+		return ref(s)
+	}
+	r := GenVertexArray(name)
+	if r == nil {
+		return nil
+	}
+	resCaches[tVertexArray][key] = r.s
+	gl.BindVertexArray(r.ID())
+	setup()
+	gl.BindVertexArray(0)
+	DbgCheckError()
+	return r
+}
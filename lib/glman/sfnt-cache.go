@@ -169,8 +169,9 @@ func matchFntFile(name string) (m string) {
 	}
 	d := int(math.MaxInt32)
 	for s := range fntfiles {
+		// ties are broken by name, so the same font is matched every time
 		d1 := levenshtein.DistanceCI(s, name)
-		if d1 < d {
+		if d1 < d || d1 == d && s < m {
 			m = s
 			d = d1
 		}
@@ -0,0 +1,129 @@
+package glman
+
+import "strings"
+
+// ellipsis ends text elided by DtEndEllipsis
+const ellipsis = "…"
+
+// advance reports width of glyphs of s, without the padding of the last glyph
+func (f *texFont) advance(s string, k float32) (width float32) {
+	for _, ch := range s {
+		g := f.loadGlyphNoRef(ch)
+		width += float32(g.w)*k - 2*k
+	}
+	return
+}
+
+// lineWidth reports width of single line s
+func (f *texFont) lineWidth(s string, k float32) float32 {
+	if s == "" {
+		return 0
+	}
+	return f.advance(s, k) + 2*k
+}
+
+// breakAround reports lines can break before or after ch, for CJK text without spaces
+func breakAround(ch rune) bool {
+	return ch >= 0x2E80
+}
+
+// wrapLine breaks s into lines not wider than width, at spaces or around CJK characters,
+// or anywhere if a word doesn't fit in a line
+func (f *texFont) wrapLine(s string, width float32, k float32) (lines []string) {
+	start := 0          // offset of current line
+	brk, next := -1, -1 // the last break opportunity, where the line ends and the next starts
+	w := float32(0)     // advance of current line
+	prev := rune(0)
+	for i, ch := range s {
+		if i > start {
+			switch {
+			case ch == ' ':
+				brk, next = i, i+1
+			case breakAround(ch) || breakAround(prev):
+				brk, next = i, i
+			}
+		}
+		adv := float32(f.loadGlyphNoRef(ch).w)*k - 2*k
+		// trailing spaces may exceed the width
+		if ch != ' ' && i > start && w+adv+2*k > width {
+			if brk <= start {
+				brk, next = i, i
+			}
+			lines = append(lines, strings.TrimRight(s[start:brk], " "))
+			start = next
+			w = f.advance(s[start:i], k)
+			brk = -1
+		}
+		w += adv
+		prev = ch
+	}
+	return append(lines, s[start:])
+}
+
+// elide cuts s to fit in width, ending with ellipsis
+func (f *texFont) elide(s string, width float32, k float32) string {
+	if f.lineWidth(s, k) <= width {
+		return s
+	}
+	room := width - f.lineWidth(ellipsis, k)
+	w := float32(0)
+	for i, ch := range s {
+		w += float32(f.loadGlyphNoRef(ch).w)*k - 2*k
+		if w > room {
+			return strings.TrimRight(s[:i], " ") + ellipsis
+		}
+	}
+	return s
+}
+
+// layoutText splits s into lines as DynDrawText draws in width
+func (f *texFont) layoutText(s string, width float32, options OptionDrawText, k float32) (lines []string) {
+	if options&DtSingleLine != 0 {
+		lines = []string{strings.Replace(s, "\n", " ", -1)}
+	} else {
+		for _, para := range strings.Split(s, "\n") {
+			if options&DtWordBreak != 0 {
+				lines = append(lines, f.wrapLine(para, width, k)...)
+			} else {
+				lines = append(lines, para)
+			}
+		}
+	}
+	if options&DtEndEllipsis != 0 {
+		for i, line := range lines {
+			lines[i] = f.elide(line, width, k)
+		}
+	}
+	return
+}
+
+// LayoutText returns lines of s as DynDrawText draws it in width, wrapped or elided by options
+func LayoutText(s string, width float32, font Font, options OptionDrawText) []string {
+	DbgCheckThread()
+	f, k := pixelFont(font)
+	return f.layoutText(s, width, options, k)
+}
+
+// TextOffsets reports x offsets of glyphs of single line s drawn by DynDrawText, relative to the start, and offset of the end.
+// so s[i:] drawn at offset of rune i keeps glyphs at the same place, it's also where the caret is before rune i.
+func TextOffsets(s string, font Font) []float32 {
+	DbgCheckThread()
+	f, k := pixelFont(font)
+	offs := make([]float32, 1, len(s)+1)
+	x := float32(0)
+	for _, ch := range s {
+		x += float32(f.loadGlyphNoRef(ch).w)*k - 2*k
+		offs = append(offs, x)
+	}
+	return offs
+}
+
+// WrapText breaks s into lines not wider than width, at spaces, around CJK characters, and '\n'
+func WrapText(s string, width float32, font Font) []string {
+	return LayoutText(s, width, font, DtWordBreak)
+}
+
+// ElideText cuts single line s to fit in width, ending with "…"
+func ElideText(s string, width float32, font Font) string {
+	return LayoutText(s, width, font, DtSingleLine|DtEndEllipsis)[0]
+}
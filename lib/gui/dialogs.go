@@ -0,0 +1,246 @@
+package gui
+
+import (
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"tetra/internal/winl"
+	"tetra/lib/lang"
+	"tetra/lib/store"
+)
+
+// guiDialogs replace native message boxes of winl
+type guiDialogs struct{}
+
+func (guiDialogs) MessageBox(w *winl.Window, msg, title string, done func()) {
+	MessageBox(guiWindow(w), msg, title, done)
+}
+
+func (guiDialogs) ConfirmBox(w *winl.Window, msg, title string, done func(ok bool)) {
+	ConfirmBox(guiWindow(w), msg, title, done)
+}
+
+func guiWindow(w *winl.Window) IWindow {
+	if w == nil {
+		return nil
+	}
+	iw, _ := w.Self.(IWindow)
+	return iw
+}
+
+// dialogWindow returns w, or the first visible gui window if w is nil
+func dialogWindow(w IWindow) IWindow {
+	if w != nil {
+		return w
+	}
+	for _, x := range winl.List() {
+		if iw, ok := x.Self.(IWindow); ok && iw.IsVisible() {
+			return iw
+		}
+	}
+	return nil
+}
+
+// MessageBox shows message in modal dialog over w, done is called after it is closed and can be nil.
+// if w is nil, the first visible window is used.
+func MessageBox(w IWindow, msg, title string, done func()) {
+	if done == nil {
+		done = func() {}
+	}
+	if winl.AutoConfirm(title, msg) {
+		done()
+		return
+	}
+	if w = dialogWindow(w); w == nil {
+		log.Printf("%s: %s\n", title, msg)
+		done()
+		return
+	}
+	dlg := NewDialog()
+	dlg.SetTitle(title)
+	dlg.SetMessage(msg)
+	dlg.SetButtons(0, 0, []string{lang.Tr("OK")})
+	dlg.OnDone(func(int) { done() })
+	w.ShowDialog(dlg)
+}
+
+// ConfirmBox asks user to confirm in modal dialog over w, done receives the answer and can be nil.
+// it's confirmed without prompting if the -force flag is given.
+func ConfirmBox(w IWindow, msg, title string, done func(ok bool)) {
+	if done == nil {
+		done = func(bool) {}
+	}
+	if winl.AutoConfirm(title, msg) {
+		done(true)
+		return
+	}
+	if w = dialogWindow(w); w == nil {
+		log.Printf("%s: %s (no window to confirm)\n", title, msg)
+		done(false)
+		return
+	}
+	dlg := NewDialog()
+	dlg.SetTitle(title)
+	dlg.SetMessage(msg)
+	dlg.SetButtons(0, 1, []string{lang.Tr("OK"), lang.Tr("Cancel")})
+	dlg.OnDone(func(btn int) { done(btn == 0) })
+	w.ShowDialog(dlg)
+}
+
+// PromptBox asks user to input a line of text in modal dialog over w, text is the initial value.
+func PromptBox(w IWindow, msg, title, text string, done func(text string, ok bool)) {
+	if winl.AutoConfirm(title, msg+" "+strconv.Quote(text)) {
+		done(text, true)
+		return
+	}
+	if w = dialogWindow(w); w == nil {
+		done(text, false)
+		return
+	}
+	dlg := NewDialog()
+	dlg.SetTitle(title)
+	dlg.SetMessage(msg)
+	dlg.SetInput(text)
+	dlg.SetButtons(0, 1, []string{lang.Tr("OK"), lang.Tr("Cancel")})
+	dlg.OnDone(func(btn int) { done(dlg.Input(), btn == 0) })
+	w.ShowDialog(dlg)
+}
+
+// fileBrowser lists directory of lib/store for file dialogs
+type fileBrowser struct {
+	dlg     *Dialog
+	dir     string // relative to store, "" for top
+	entries []string
+}
+
+// chdir list entries of dir, sub directories ends with '/', then files
+func (fb *fileBrowser) chdir(dir string) {
+	fb.dir = dir
+	infos, _ := store.ReadDir(dir)
+	var dirs, files []string
+	for _, info := range infos {
+		if strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		if info.IsDir() {
+			dirs = append(dirs, info.Name()+"/")
+		} else {
+			files = append(files, info.Name())
+		}
+	}
+	sort.Strings(dirs)
+	sort.Strings(files)
+	fb.entries = nil
+	if dir != "" {
+		fb.entries = append(fb.entries, "../")
+	}
+	fb.entries = append(append(fb.entries, dirs...), files...)
+	fb.dlg.SetMessage("/" + dir)
+	fb.dlg.SetList(fb.entries)
+}
+
+// enter the directory at i, returns false if it's a file
+func (fb *fileBrowser) enter(i int) bool {
+	name := fb.entries[i]
+	if !strings.HasSuffix(name, "/") {
+		return false
+	}
+	if name == "../" {
+		fb.chdir(strings.TrimPrefix(path.Dir(fb.dir), "."))
+	} else {
+		fb.chdir(path.Join(fb.dir, strings.TrimSuffix(name, "/")))
+	}
+	return true
+}
+
+func (fb *fileBrowser) path(name string) string {
+	return path.Join(fb.dir, name)
+}
+
+func newFileBrowser(title, dir string) *fileBrowser {
+	fb := &fileBrowser{dlg: NewDialog()}
+	fb.dlg.SetTitle(title)
+	fb.chdir(strings.Trim(path.Clean("/"+dir), "/"))
+	return fb
+}
+
+// OpenFileBox asks user to choose a file of lib/store in modal dialog over w, browsing from dir.
+// name passed to done is relative to the store, i.e. for store.Open.
+func OpenFileBox(w IWindow, title, dir string, done func(name string, ok bool)) {
+	if winl.AutoConfirm(title, "/"+dir) {
+		// nothing to confirm without a choice
+		done("", false)
+		return
+	}
+	if w = dialogWindow(w); w == nil {
+		done("", false)
+		return
+	}
+	fb := newFileBrowser(title, dir)
+	fb.dlg.SetButtons(0, 1, []string{lang.Tr("Open"), lang.Tr("Cancel")})
+	fb.dlg.OnEnter(fb.enter)
+	fb.dlg.OnDone(func(btn int) {
+		i := fb.dlg.Selected()
+		if btn != 0 || i < 0 || strings.HasSuffix(fb.entries[i], "/") {
+			done("", false)
+			return
+		}
+		done(fb.path(fb.entries[i]), true)
+	})
+	w.ShowDialog(fb.dlg)
+}
+
+// SaveFileBox asks user for a file name to save in modal dialog over w, browsing from dir, name is the default.
+// it confirms before overwriting an existing file. name passed to done is relative to the store, i.e. for store.Create.
+func SaveFileBox(w IWindow, title, dir, name string, done func(name string, ok bool)) {
+	if name := path.Join(strings.Trim(path.Clean("/"+dir), "/"), name); winl.AutoConfirm(title, name) {
+		done(name, true)
+		return
+	}
+	if w = dialogWindow(w); w == nil {
+		done("", false)
+		return
+	}
+	fb := newFileBrowser(title, dir)
+	fb.dlg.SetInput(name)
+	fb.dlg.SetButtons(0, 1, []string{lang.Tr("Save"), lang.Tr("Cancel")})
+	fb.dlg.OnSelect(func(i int) {
+		if !strings.HasSuffix(fb.entries[i], "/") {
+			fb.dlg.SetInput(fb.entries[i])
+		}
+	})
+	fb.dlg.OnEnter(func(i int) bool {
+		// Enter saves the typed name, unless a directory is chosen
+		return fb.enter(i)
+	})
+	fb.dlg.OnDone(func(btn int) {
+		name := strings.TrimSpace(fb.dlg.Input())
+		if btn != 0 || name == "" {
+			done("", false)
+			return
+		}
+		name = fb.path(name)
+		info, err := store.Stat(name)
+		if err != nil {
+			done(name, true)
+			return
+		}
+		if info.IsDir() {
+			fb.chdir(name)
+			fb.dlg.SetInput("")
+			w.ShowDialog(fb.dlg)
+			return
+		}
+		ConfirmBox(w, lang.Tr("File already exists, overwrite it?")+"\n"+name, title, func(ok bool) {
+			if ok {
+				done(name, true)
+			} else {
+				// back to choose another name
+				w.ShowDialog(fb.dlg)
+			}
+		})
+	})
+	w.ShowDialog(fb.dlg)
+}
@@ -0,0 +1,130 @@
+//go:build headless
+// +build headless
+
+package gui
+
+import (
+	"testing"
+
+	"tetra/internal/winl"
+)
+
+func TestDialogs(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(640, 480); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		w.Show()
+
+		// Escape cancels
+		answer := 0
+		ConfirmBox(w, "Delete?", "Confirm", func(ok bool) {
+			if ok {
+				answer = 1
+			} else {
+				answer = 2
+			}
+		})
+		if w.Dialog() == nil {
+			t.Error("ConfirmBox() shows no dialog")
+			return
+		}
+		w.Render()
+		w.InjectKey(winl.KeyEscape, 0, true)
+		if answer != 2 || w.Dialog() != nil {
+			t.Errorf("after Escape, answer = %d, Dialog() = %v, want canceled and closed", answer, w.Dialog())
+		}
+
+		// click on OK button
+		ConfirmBox(w, "Delete?", "Confirm", func(ok bool) {
+			if ok {
+				answer = 1
+			}
+		})
+		x, y := w.Dialog().(*Dialog).btns[0].WindowBounds().Center()
+		w.InjectMousePress(winl.MouseLeft, x, y)
+		w.InjectMouseRelease(winl.MouseLeft, x, y)
+		if answer != 1 {
+			t.Errorf("click OK, answer = %d, want confirmed", answer)
+		}
+
+		// buttons take focus, Tab moves to Cancel and Space presses it
+		answer = 0
+		ConfirmBox(w, "Delete?", "Confirm", func(ok bool) {
+			if ok {
+				answer = 1
+			} else {
+				answer = 2
+			}
+		})
+		btns := w.Dialog().(*Dialog).btns
+		if !btns[0].HasFocus() {
+			t.Errorf("focus owner of ConfirmBox = %v, want OK button", w.FocusOwner())
+		}
+		w.InjectKey(winl.KeyTab, 0, true)
+		if !btns[1].HasFocus() {
+			t.Errorf("focus owner after Tab = %v, want Cancel button", w.FocusOwner())
+		}
+		w.InjectKey(winl.KeySpace, 0, true)
+		w.InjectKey(winl.KeySpace, 0, false)
+		if answer != 2 || w.Dialog() != nil {
+			t.Errorf("after Space on Cancel, answer = %d, Dialog() = %v, want canceled and closed", answer, w.Dialog())
+		}
+
+		// input is edited at the caret, it takes focus from content and gives it back
+		pn := NewPane()
+		if err := w.SetLayout(&WndLayout{Pane: pn}); err != nil {
+			t.Error(err)
+			return
+		}
+		focus := NewLineEdit()
+		pn.Insert(-1, focus)
+		focus.SetFocus()
+		var got string
+		PromptBox(w, "Name:", "Prompt", "ab", func(text string, ok bool) {
+			if ok {
+				got = text
+			}
+		})
+		w.InjectTextInput("cd")
+		w.InjectKey(winl.KeyBackspace, 0, true)
+		w.InjectKey(winl.KeyHome, 0, true)
+		w.InjectKey(winl.KeyDelete, 0, true)
+		w.InjectKey(winl.KeyEnd, 0, true)
+		w.InjectKey(winl.KeyLeft, 0, true)
+		w.InjectTextInput("z")
+		w.Render()
+		dlg := w.Dialog().(*Dialog)
+		x, y = dlg.edit.WindowBounds().Center()
+		w.InjectMouseMove(x, y)
+		if w.Hover() != dlg.edit.Self {
+			t.Errorf("Hover() = %v, want the input line", w.Hover())
+		}
+		w.InjectKey(winl.KeyEnter, 0, true)
+		if h := w.Hover(); h != nil && contains(dlg, h) {
+			t.Errorf("Hover() after closing dialog = %v, want out of it", h)
+		}
+		if got != "bzc" {
+			t.Errorf("PromptBox() = %q, want %q", got, "bzc")
+		}
+		if !focus.HasFocus() {
+			t.Errorf("focus owner after PromptBox = %v, want the content", w.FocusOwner())
+		}
+
+		// -force confirms without dialog
+		winl.SetAutoConfirm(true)
+		defer winl.SetAutoConfirm(false)
+		answer = 0
+		ConfirmBox(w, "Delete?", "Confirm", func(ok bool) {
+			if ok {
+				answer = 1
+			}
+		})
+		if answer != 1 || w.Dialog() != nil {
+			t.Errorf("with AutoConfirm, answer = %d, want confirmed without dialog", answer)
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package gui
+
+import (
+	"tetra/internal/winl"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+)
+
+// DockZone is where a pane is docked relative to the target pane
+type DockZone int
+
+// Dock zones
+const (
+	DockNone   DockZone = iota
+	DockLeft            // left half of target
+	DockRight           // right half of target
+	DockTop             // top half of target
+	DockBottom          // bottom half of target
+	DockCenter          // takes place of target, they are swapped
+)
+
+// dockMods are modifiers to drag pane by mouse for docking
+const dockMods = winl.ModControl | winl.ModShift
+
+// dockZoneAt reports the zone of rc where x, y is, the center area swaps, others dock to the nearest edge
+func dockZoneAt(rc Rect, x, y float32) DockZone {
+	if !rc.Contains(x, y) || rc.Width() <= 0 || rc.Height() <= 0 {
+		return DockNone
+	}
+	fx := (x - rc[0]) / rc.Width()
+	fy := (y - rc[1]) / rc.Height()
+	if fx > 0.25 && fx < 0.75 && fy > 0.25 && fy < 0.75 {
+		return DockCenter
+	}
+	zone, d := DockLeft, fx
+	if 1-fx < d {
+		zone, d = DockRight, 1-fx
+	}
+	if fy < d {
+		zone, d = DockTop, fy
+	}
+	if 1-fy < d {
+		zone = DockBottom
+	}
+	return zone
+}
+
+// dockPreview returns the area of rc a pane will take if docked at zone
+func dockPreview(rc Rect, zone DockZone) Rect {
+	mx, my := (rc[0]+rc[2])*0.5, (rc[1]+rc[3])*0.5
+	switch zone {
+	case DockLeft:
+		rc[2] = mx
+	case DockRight:
+		rc[0] = mx
+	case DockTop:
+		rc[3] = my
+	case DockBottom:
+		rc[1] = my
+	case DockNone:
+		return Rect{}
+	}
+	return rc
+}
+
+// calcLayout fits the layout to client area of window
+func (w *Window) calcLayout() {
+	if w.layout == nil {
+		return
+	}
+	width, height := w.Size()
+	s := w.ContentScale()
+	w.layout.rc = Rect{0, 0, width / s, height / s}
+	w.layout.CalcLayout(w.szSplit)
+}
+
+// layoutChanged relayout and redraw after the layout tree is edited
+func (w *Window) layoutChanged() {
+	w.calcLayout()
+	w.splitDrag = nil
+	w.hoverSplitter(nil)
+	w.Invalidate(Rect{})
+}
+
+// SplitPane splits pn in halves, new pane of class is created at right or bottom if vert, returns the new pane
+func (w *Window) SplitPane(pn IPane, vert bool, class string) (IPane, error) {
+	node, _ := w.layout.Find(pn)
+	if node == nil {
+		return nil, ErrNotFound
+	}
+	leaf := &WndLayout{Class: class}
+	if err := treeFixLoaded(leaf, w.Self.(IWindow)); err != nil {
+		return nil, err
+	}
+	node.split(vert, false, leaf)
+	w.layoutChanged()
+	return leaf.Pane, nil
+}
+
+// ClosePane removes pn from layout, its sibling takes the space, the last pane can't be closed
+func (w *Window) ClosePane(pn IPane) error {
+	node, parent := w.layout.Find(pn)
+	if node == nil {
+		return ErrNotFound
+	}
+	if parent == nil {
+		return ErrBadParams
+	}
+	w.layout.remove(node)
+	w.forgetElem(pn)
+	pn.SetWindow(nil)
+	w.layoutChanged()
+	return nil
+}
+
+// SwapPanes exchanges places of pane a and b
+func (w *Window) SwapPanes(a, b IPane) error {
+	na, _ := w.layout.Find(a)
+	nb, _ := w.layout.Find(b)
+	if na == nil || nb == nil {
+		return ErrNotFound
+	}
+	na.Pane, nb.Pane = nb.Pane, na.Pane
+	na.Class, nb.Class = nb.Class, na.Class
+	na.Param, nb.Param = nb.Param, na.Param
+	w.layoutChanged()
+	return nil
+}
+
+// DockPane moves pn to zone of target, DockCenter swaps them
+func (w *Window) DockPane(pn, target IPane, zone DockZone) error {
+	switch {
+	case zone == DockNone || pn == target:
+		return nil
+	case zone == DockCenter:
+		return w.SwapPanes(pn, target)
+	}
+	node, parent := w.layout.Find(pn)
+	if tnode, _ := w.layout.Find(target); node == nil || tnode == nil {
+		return ErrNotFound
+	}
+	if parent == nil {
+		return ErrBadParams
+	}
+	leaf := &WndLayout{Pane: pn, Class: node.Class, Param: node.Param}
+	w.layout.remove(node)
+	// nodes are moved by remove
+	tnode, _ := w.layout.Find(target)
+	tnode.split(zone == DockTop || zone == DockBottom, zone == DockLeft || zone == DockTop, leaf)
+	w.layoutChanged()
+	return nil
+}
+
+// BeginDock starts dragging pn by mouse, drop zones are shown over pane under mouse, until mouse is released or Escape is pressed
+func (w *Window) BeginDock(pn IPane) {
+	if node, parent := w.layout.Find(pn); node == nil || parent == nil {
+		return
+	}
+	w.ReleaseCapture()
+	w.dockPane, w.dockTarget, w.dockZone = pn, nil, DockNone
+	w.GrabMouse()
+	w.SetCursor(winl.CursorHand)
+}
+
+// dockDrag updates drop zone under mouse
+func (w *Window) dockDrag() {
+	target := w.layout.PaneAt(w.mx, w.my)
+	zone := DockNone
+	if target != nil && target != w.dockPane {
+		zone = dockZoneAt(target.Bounds(), w.mx, w.my)
+	}
+	if target != w.dockTarget || zone != w.dockZone {
+		old := w.dockPreview()
+		w.dockTarget, w.dockZone = target, zone
+		if rc := old.Union(w.dockPreview()); !rc.IsEmpty() {
+			w.Invalidate(rc)
+		}
+	}
+}
+
+// dockPreview returns area of drop zone overlay, with its frame
+func (w *Window) dockPreview() Rect {
+	if w.dockTarget == nil || w.dockZone == DockNone {
+		return Rect{}
+	}
+	return inset(dockPreview(w.dockTarget.Bounds(), w.dockZone), -2, -2)
+}
+
+// endDock stops dragging pane, without docking it
+func (w *Window) endDock() {
+	if w.dockPane == nil {
+		return
+	}
+	w.dockPane, w.dockTarget, w.dockZone = nil, nil, DockNone
+	w.UngrabMouse()
+	w.SetCursor(winl.CursorArrow)
+	w.splitHover = nil
+	w.Invalidate(Rect{})
+}
+
+// renderDock draws the dragged pane frame and the drop zone overlay
+func (w *Window) renderDock() {
+	if w.dockPane == nil {
+		return
+	}
+	sk := skin.Get()
+	hl := sk.Color(skin.RoleHighlight)
+	glman.DynDrawRect(w.dockPane.Bounds(), hl, 2)
+	if w.dockTarget != nil && w.dockZone != DockNone {
+		fill := hl
+		fill[3] = 0.35
+		rc := dockPreview(w.dockTarget.Bounds(), w.dockZone)
+		glman.DynFillRect(rc, fill)
+		glman.DynDrawRect(rc, hl, 2)
+	}
+}
@@ -0,0 +1,296 @@
+package gui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// editUndoLimit is the most undo steps kept by text editors
+const editUndoLimit = 100
+
+// editKind is kind of edit, successive typing or deleting are merged into one undo step
+type editKind int
+
+const (
+	editOther editKind = iota
+	editType
+	editDelete
+)
+
+// editStep is a change of text, runes removed at start are replaced by inserted
+type editStep struct {
+	start             int
+	removed, inserted []rune
+}
+
+// editGroup is edits undone at once, with caret and anchor before and after them
+type editGroup struct {
+	steps         []editStep
+	before, after [2]int
+}
+
+// editBuf is the text model of editors, positions are indexes of runes.
+// text and lines are changed in place, so that an edit costs about the lines it touches.
+type editBuf struct {
+	text          []rune
+	caret, anchor int   // selection is between anchor and caret
+	lines         []int // start of lines
+	multiline     bool
+	valid         func(text string) bool      // edits making invalid text are rejected
+	changed       func(l, removed, added int) // called after lines from l are replaced, for caches of lines
+	undo, redo    []editGroup
+	last          editKind // kind of the last edit, editOther if caret is moved after it
+}
+
+// String returns the text
+func (b *editBuf) String() string {
+	return string(b.text)
+}
+
+// setText replaces the text, caret is moved to the end, undo history is cleared
+func (b *editBuf) setText(s string) {
+	old := len(b.lines)
+	b.text = []rune(b.filter(s))
+	b.caret, b.anchor = len(b.text), len(b.text)
+	b.lines = append(b.lines[:0], 0)
+	for i, ch := range b.text {
+		if ch == '\n' {
+			b.lines = append(b.lines, i+1)
+		}
+	}
+	b.undo, b.redo, b.last = nil, nil, editOther
+	if b.changed != nil {
+		b.changed(0, old, len(b.lines))
+	}
+}
+
+// splice replaces runes between start and end by ins, only inserted runes are scanned for line breaks
+func (b *editBuf) splice(start, end int, ins []rune) {
+	l0, l1 := b.lineOf(start), b.lineOf(end)
+	n, d := len(b.text), len(ins)-(end-start)
+	if d > 0 {
+		b.text = append(b.text, ins[:d]...)
+	}
+	copy(b.text[start+len(ins):], b.text[end:n])
+	copy(b.text[start:], ins)
+	b.text = b.text[:n+d]
+
+	// starts of lines in removed text are replaced by those in ins, the following ones are shifted
+	var starts []int
+	for i, ch := range ins {
+		if ch == '\n' {
+			starts = append(starts, start+i+1)
+		}
+	}
+	n, k := len(b.lines), len(starts)-(l1-l0)
+	if k > 0 {
+		b.lines = append(b.lines, starts[:k]...)
+	}
+	copy(b.lines[l0+1+len(starts):], b.lines[l1+1:n])
+	copy(b.lines[l0+1:], starts)
+	b.lines = b.lines[:n+k]
+	for i := l0 + 1 + len(starts); i < len(b.lines); i++ {
+		b.lines[i] += d
+	}
+	if b.changed != nil {
+		b.changed(l0, l1-l0+1, len(starts)+1)
+	}
+}
+
+// filter removes control characters from s, line breaks are kept in multiline text, or replaced by space
+func (b *editBuf) filter(s string) string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	return strings.Map(func(ch rune) rune {
+		switch {
+		case ch == '\n' && b.multiline:
+			return ch
+		case ch == '\n' || ch == '\r' || ch == '\t':
+			return ' '
+		case unicode.IsControl(ch):
+			return -1
+		}
+		return ch
+	}, s)
+}
+
+// selection reports start and end of selection
+func (b *editBuf) selection() (start, end int) {
+	if b.anchor < b.caret {
+		return b.anchor, b.caret
+	}
+	return b.caret, b.anchor
+}
+
+// selectedText returns text of selection
+func (b *editBuf) selectedText() string {
+	start, end := b.selection()
+	return string(b.text[start:end])
+}
+
+// clamp limits i in text
+func (b *editBuf) clamp(i int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > len(b.text) {
+		return len(b.text)
+	}
+	return i
+}
+
+// setCaret moves caret to i, selection is extended to it if extend, otherwise it's cleared
+func (b *editBuf) setCaret(i int, extend bool) {
+	b.caret = b.clamp(i)
+	if !extend {
+		b.anchor = b.caret
+	}
+	b.last = editOther
+}
+
+// replace text between start and end by s, caret is moved to the end of s.
+// returns false if nothing is changed, or the result is rejected by valid.
+func (b *editBuf) replace(start, end int, s string, kind editKind) bool {
+	ins := []rune(b.filter(s))
+	if start == end && len(ins) == 0 {
+		return false
+	}
+	if b.valid != nil && !b.valid(string(b.text[:start])+string(ins)+string(b.text[end:])) {
+		return false
+	}
+	if kind == editOther || kind != b.last || len(b.undo) == 0 {
+		b.undo = append(b.undo, editGroup{before: [2]int{b.caret, b.anchor}})
+		if len(b.undo) > editUndoLimit {
+			b.undo = b.undo[1:]
+		}
+	}
+	g := &b.undo[len(b.undo)-1]
+	g.steps = append(g.steps, editStep{start, append([]rune(nil), b.text[start:end]...), ins})
+	b.splice(start, end, ins)
+	b.redo = nil
+	caret := start + len(ins)
+	b.caret, b.anchor = caret, caret
+	g.after = [2]int{caret, caret}
+	b.last = kind
+	return true
+}
+
+// insert s in place of selection
+func (b *editBuf) insert(s string, kind editKind) bool {
+	start, end := b.selection()
+	return b.replace(start, end, s, kind)
+}
+
+// deleteTo deletes selection, or text between caret and i if nothing is selected
+func (b *editBuf) deleteTo(i int) bool {
+	start, end := b.selection()
+	if start == end {
+		start, end = b.caret, b.clamp(i)
+		if end < start {
+			start, end = end, start
+		}
+	}
+	return b.replace(start, end, "", editDelete)
+}
+
+// undoEdit restores text before the last edit
+func (b *editBuf) undoEdit() bool {
+	if len(b.undo) == 0 {
+		return false
+	}
+	g := b.undo[len(b.undo)-1]
+	b.undo = b.undo[:len(b.undo)-1]
+	for i := len(g.steps) - 1; i >= 0; i-- {
+		st := g.steps[i]
+		b.splice(st.start, st.start+len(st.inserted), st.removed)
+	}
+	b.caret, b.anchor = g.before[0], g.before[1]
+	b.redo = append(b.redo, g)
+	b.last = editOther
+	return true
+}
+
+// redoEdit restores text undone by undoEdit
+func (b *editBuf) redoEdit() bool {
+	if len(b.redo) == 0 {
+		return false
+	}
+	g := b.redo[len(b.redo)-1]
+	b.redo = b.redo[:len(b.redo)-1]
+	for _, st := range g.steps {
+		b.splice(st.start, st.start+len(st.removed), st.inserted)
+	}
+	b.caret, b.anchor = g.after[0], g.after[1]
+	b.undo = append(b.undo, g)
+	b.last = editOther
+	return true
+}
+
+// charClass classifies runes for word navigation, 0 for spaces, 1 for word characters, 2 for others
+func charClass(ch rune) int {
+	switch {
+	case unicode.IsSpace(ch):
+		return 0
+	case ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch):
+		return 1
+	}
+	return 2
+}
+
+// wordLeft returns start of the word before i, spaces are skipped
+func (b *editBuf) wordLeft(i int) int {
+	for i > 0 && charClass(b.text[i-1]) == 0 {
+		i--
+	}
+	if i > 0 {
+		c := charClass(b.text[i-1])
+		for i > 0 && charClass(b.text[i-1]) == c {
+			i--
+		}
+	}
+	return i
+}
+
+// wordRight returns end of the word at i, then spaces after it are skipped
+func (b *editBuf) wordRight(i int) int {
+	n := len(b.text)
+	if i < n {
+		c := charClass(b.text[i])
+		for i < n && c != 0 && charClass(b.text[i]) == c {
+			i++
+		}
+	}
+	for i < n && charClass(b.text[i]) == 0 {
+		i++
+	}
+	return i
+}
+
+// wordAt returns start and end of the word contains i
+func (b *editBuf) wordAt(i int) (start, end int) {
+	start, end = i, i
+	if i < len(b.text) {
+		c := charClass(b.text[i])
+		for start > 0 && charClass(b.text[start-1]) == c {
+			start--
+		}
+		for end < len(b.text) && charClass(b.text[end]) == c {
+			end++
+		}
+	}
+	return
+}
+
+// lineOf returns index of line contains i
+func (b *editBuf) lineOf(i int) int {
+	return sort.SearchInts(b.lines, i+1) - 1
+}
+
+// line returns start and end of line l, excluding the line break
+func (b *editBuf) line(l int) (start, end int) {
+	start, end = b.lines[l], len(b.text)
+	if l+1 < len(b.lines) {
+		end = b.lines[l+1] - 1
+	}
+	return
+}
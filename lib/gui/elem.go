@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"tetra/internal/winl"
 	"tetra/lib/geom"
 	"tetra/lib/glman"
 	//	"tetra/lib/geom"
@@ -10,10 +11,15 @@ import (
 // Elem class is abstract gui element
 type Elem struct {
 	Self   interface{}
-	bounds Rect
+	bounds Rect // in coordinates of parent
 	parent IElem
 	child  []IElem
 	wnd    IWindow
+
+	layout  Layout // arranges children, nil to place them by SetBounds
+	margin  Margins
+	padding Margins
+	hint    *SizeHint // set by SetSizeHint
 }
 
 // Init a new object
@@ -40,18 +46,61 @@ func (el *Elem) Bounds() Rect {
 	return el.bounds
 }
 
-// BoundsGLCoord reports bounds rect of the element, in OpenGL (Y-UP) coordinate.
+// WindowBounds reports bounds rect of the element, in window coordinates
+func (el *Elem) WindowBounds() Rect {
+	rc := el.bounds
+	if el.parent != nil {
+		ox, oy := el.parent.ToLocal(0, 0)
+		rc[0], rc[1], rc[2], rc[3] = rc[0]-ox, rc[1]-oy, rc[2]-ox, rc[3]-oy
+	}
+	return rc
+}
+
+// BoundsGLCoord reports bounds rect of the element, in OpenGL (Y-UP) pixel coordinate.
 func (el *Elem) BoundsGLCoord() (rc Rect) {
-	rc = el.bounds
+	rc = el.WindowBounds()
 	win := el.Window()
 	_, h := win.Size()
-	rc[1], rc[3] = h-rc[3], h-rc[1]
+	s := win.ContentScale()
+	rc[0], rc[2] = rc[0]*s, rc[2]*s
+	rc[1], rc[3] = h-rc[3]*s, h-rc[1]*s
 	return rc
 }
 
-// SetBounds set the bounds rect of the element
+// SetBounds set the bounds rect of the element, children are arranged by layout if size is changed
 func (el *Elem) SetBounds(rect Rect) {
+	if rect == el.bounds {
+		return
+	}
+	resized := rect.Width() != el.bounds.Width() || rect.Height() != el.bounds.Height()
+	// both old and new area are repainted
+	el.Invalidate(Rect{})
 	el.bounds = rect
+	el.Invalidate(Rect{})
+	if resized {
+		el.Relayout()
+	}
+}
+
+// Invalidate marks rc in coordinates of the element to be repainted, empty rc for the whole element
+func (el *Elem) Invalidate(rc Rect) {
+	w := el.Window()
+	if w == nil {
+		return
+	}
+	wb := el.WindowBounds()
+	if !rc.IsEmpty() {
+		rc = Rect{rc[0] + wb[0], rc[1] + wb[1], rc[2] + wb[0], rc[3] + wb[1]}.Intersect(wb)
+	} else {
+		rc = wb
+	}
+	// children are clipped by ancestors, e.g. content of ScrollView
+	for p := el.parent; p != nil && !rc.IsEmpty(); p = p.Parent() {
+		rc = rc.Intersect(p.WindowBounds())
+	}
+	if !rc.IsEmpty() {
+		w.Invalidate(rc)
+	}
 }
 
 // Window reports the owner window
@@ -65,7 +114,7 @@ func (el *Elem) Window() IWindow {
 	return el.wnd
 }
 
-// SetWindow set the owner window
+// SetWindow set the owner window of the element and its children, nil when they are detached
 func (el *Elem) SetWindow(w IWindow) {
 	el.wnd = w
 	for _, c := range el.child {
@@ -75,6 +124,7 @@ func (el *Elem) SetWindow(w IWindow) {
 
 // Insert x at index i, if i < 0 then append to the end
 func (el *Elem) Insert(i int, x IElem) {
+	x.SetParent(el.Self.(IElem))
 	if i < 0 {
 		el.child = append(el.child, x)
 	} else {
@@ -82,24 +132,51 @@ func (el *Elem) Insert(i int, x IElem) {
 		copy(el.child[i+1:], el.child[i:])
 		el.child[i] = x
 	}
+	el.Relayout()
+	el.Invalidate(Rect{})
+}
+
+// elemForgetter is implemented by Window, it drops state referring to removed elements
+type elemForgetter interface {
+	forgetElem(el IElem)
 }
 
-// Remove child at index i
+// Remove child at index i, it's detached from the window
 func (el *Elem) Remove(i int) IElem {
 	x := el.child[i]
+	if w, ok := el.Window().(elemForgetter); ok {
+		w.forgetElem(x)
+	}
+	if cf, ok := el.layout.(childForgetter); ok {
+		cf.forget(x)
+	}
 	x.SetParent(nil)
+	x.SetWindow(nil)
 	copy(el.child[i:], el.child[i+1:])
 	el.child[len(el.child)-1] = nil
 	el.child = el.child[:len(el.child)-1]
+	el.Relayout()
+	el.Invalidate(Rect{})
 	return x
 }
 
-// RemoveAll remove all children
+// RemoveAll remove all children, they are detached from the window
 func (el *Elem) RemoveAll() {
+	cf, _ := el.layout.(childForgetter)
+	w, _ := el.Window().(elemForgetter)
 	for _, c := range el.child {
+		if w != nil {
+			w.forgetElem(c)
+		}
+		if cf != nil {
+			cf.forget(c)
+		}
 		c.SetParent(nil)
+		c.SetWindow(nil)
 	}
 	el.child = nil
+	el.Relayout()
+	el.Invalidate(Rect{})
 }
 
 // Index of x
@@ -112,20 +189,101 @@ func (el *Elem) Index(x IElem) int {
 	return -1
 }
 
+// ToLocal converts x, y in window coordinates to coordinates of the element, origin is top left of its bounds
+func (el *Elem) ToLocal(x, y float32) (float32, float32) {
+	if el.parent != nil {
+		x, y = el.parent.ToLocal(x, y)
+	}
+	return x - el.bounds[0], y - el.bounds[1]
+}
+
+// HitTest returns the deepest element contains x, y in coordinates of parent, nil if it's out of bounds
+func (el *Elem) HitTest(x, y float32) IElem {
+	if !el.bounds.Contains(x, y) {
+		return nil
+	}
+	// children rendered later are on top
+	x, y = x-el.bounds[0], y-el.bounds[1]
+	for i := len(el.child) - 1; i >= 0; i-- {
+		if hit := el.child[i].HitTest(x, y); hit != nil {
+			return hit
+		}
+	}
+	return el.Self.(IElem)
+}
+
+// OnMouseDown event handler, x, y are local coordinates, returns false to bubble it to parent
+func (el *Elem) OnMouseDown(btn int, x, y float32) bool {
+	return false
+}
+
+// OnMouseUp event handler, x, y are local coordinates, returns false to bubble it to parent
+func (el *Elem) OnMouseUp(btn int, x, y float32) bool {
+	return false
+}
+
+// OnMouseMove event handler, x, y are local coordinates, returns false to bubble it to parent
+func (el *Elem) OnMouseMove(x, y float32) bool {
+	return false
+}
+
+// OnMouseDelta event handler, dx, dy are mouse motion in pixels in relative mouse mode, returns false to bubble it to parent
+func (el *Elem) OnMouseDelta(dx, dy float32) bool {
+	return false
+}
+
+// OnMouseWheel event handler, dz is in notches, positive for up or left, returns false to bubble it to parent
+func (el *Elem) OnMouseWheel(vert bool, dz float32) bool {
+	return false
+}
+
+// OnMouseEnter event handler, called when mouse enters the element or one of its children
+func (el *Elem) OnMouseEnter() {
+}
+
+// OnMouseLeave event handler, called when mouse leaves the element and all of its children
+func (el *Elem) OnMouseLeave() {
+}
+
+// OnKeyDown event handler, key is delivered to focus owner, returns false to bubble it to parent
+func (el *Elem) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	return false
+}
+
+// OnKeyUp event handler, key is delivered to focus owner, returns false to bubble it to parent
+func (el *Elem) OnKeyUp(key winl.Key, mods winl.Mod) bool {
+	return false
+}
+
+// OnTextInput event handler, text is delivered to focus owner, returns false to bubble it to parent
+func (el *Elem) OnTextInput(text string) bool {
+	return false
+}
+
 func round(x float32) float32 {
 	return float32(int(x + 0.5))
 }
 
-// Render the element
+// renderView is the visible area of the innermost ScrollView being rendered, in window coordinates.
+// children out of it are not rendered, it's empty if there is no ScrollView.
+var renderView Rect
+
+// Render the element, children are rendered in its coordinates and clipped by its bounds, those out of view of ScrollView are skipped
 func (el *Elem) Render() {
 	glman.StackMatM.Push()
-	glman.StackMatM.Load(geom.Mat4Trans(round(el.bounds.X0()), round(el.bounds.Y0()), 0))
+	glman.StackMatM.Multi(geom.Mat4Trans(round(el.bounds.X0()), round(el.bounds.Y0()), 0))
+	// clip rect is in window coordinates
+	wb := el.WindowBounds()
+	rect := glman.StackClip2D.Peek().Intersect(wb)
 	glman.StackClip2D.Push()
-	rect := el.bounds
 	//dbg.Logf("rect=%v\n", rect)
 	glman.StackClip2D.Load(rect)
+	// view in local coordinates, where bounds of children are
+	view := Rect{renderView[0] - wb[0], renderView[1] - wb[1], renderView[2] - wb[0], renderView[3] - wb[1]}
 	for _, c := range el.child {
-		c.Render()
+		if renderView.IsEmpty() || !c.Bounds().Intersect(view).IsEmpty() {
+			c.Render()
+		}
 	}
 	glman.StackClip2D.Pop()
 	glman.StackMatM.Pop()
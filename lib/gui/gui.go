@@ -19,6 +19,7 @@ package gui
 
 import (
 	"errors"
+	"tetra/internal/winl"
 	"tetra/lib/geom"
 	"tetra/lib/glman"
 )
@@ -29,6 +30,7 @@ import (
 var (
 	ErrWrongType = errors.New("Wrong type")
 	ErrBadParams = errors.New("Bad params")
+	ErrNotFound  = errors.New("Not found")
 )
 
 type (
@@ -48,4 +50,5 @@ type (
 
 func init() {
 	FactoryRegister()
+	winl.SetDialogs(guiDialogs{})
 }
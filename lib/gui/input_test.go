@@ -0,0 +1,346 @@
+//go:build headless
+// +build headless
+
+package gui
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"tetra/internal/winl"
+)
+
+// logElem logs mouse events it receives
+type logElem struct {
+	Elem
+	name   string
+	log    *[]string
+	handle bool // handles down, up and move, otherwise they bubble to parent
+}
+
+func newLogElem(name string, rc Rect, log *[]string, handle bool) *logElem {
+	el := &logElem{name: name, log: log, handle: handle}
+	el.Self = el
+	el.SetBounds(rc)
+	return el
+}
+
+func (el *logElem) add(format string, args ...interface{}) {
+	*el.log = append(*el.log, el.name+" "+fmt.Sprintf(format, args...))
+}
+
+func (el *logElem) OnMouseDown(btn int, x, y float32) bool {
+	el.add("down %g,%g", x, y)
+	return el.handle
+}
+
+func (el *logElem) OnMouseUp(btn int, x, y float32) bool {
+	el.add("up %g,%g", x, y)
+	return el.handle
+}
+
+func (el *logElem) OnMouseWheel(vert bool, dz float32) bool {
+	el.add("wheel %v %g", vert, dz)
+	return el.handle
+}
+
+func (el *logElem) OnMouseEnter() { el.add("enter") }
+
+func (el *logElem) OnMouseLeave() { el.add("leave") }
+
+func TestPointerRouting(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		if err := w.SetLayout(&WndLayout{Pane: NewPane()}); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+		pn := w.layout.Pane
+
+		var log []string
+		outer := newLogElem("outer", Rect{100, 100, 200, 200}, &log, true)
+		inner := newLogElem("inner", Rect{10, 10, 50, 50}, &log, false)
+		outer.Insert(-1, inner)
+		pn.Insert(-1, outer)
+
+		if hit := w.HitTest(115, 115); hit != inner {
+			t.Errorf("HitTest(115, 115) = %v, want inner", hit)
+		}
+		if hit := w.HitTest(105, 105); hit != outer {
+			t.Errorf("HitTest(105, 105) = %v, want outer", hit)
+		}
+
+		s := w.ContentScale()
+		w.InjectMouseMove(115*s, 115*s)
+		w.InjectMousePress(winl.MouseLeft, 115*s, 115*s)
+		// captured by outer which handled the press
+		w.InjectMouseMove(300*s, 10*s)
+		w.InjectMouseRelease(winl.MouseLeft, 300*s, 10*s)
+		w.InjectMouseWheel(true, 1)
+		want := []string{
+			"outer enter",
+			"inner enter",
+			"inner down 5,5",
+			"outer down 15,15",
+			"outer up 200,-90",
+			"inner leave",
+			"outer leave",
+		}
+		if !reflect.DeepEqual(log, want) {
+			t.Errorf("events = %q, want %q", log, want)
+		}
+		if w.Capture() != nil || w.Hover() != pn {
+			t.Errorf("after release, Capture() = %v, Hover() = %v, want nil and pane", w.Capture(), w.Hover())
+		}
+	})
+}
+
+func TestNativeButtons(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		if err := w.SetLayout(&WndLayout{Pane: NewPane()}); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+
+		var log []string
+		el := newLogElem("el", Rect{100, 100, 200, 200}, &log, true)
+		w.layout.Pane.Insert(-1, el)
+
+		// X11 button numbers, through the native backend
+		s := w.ContentScale()
+		x, y := 110*s, 110*s
+		w.InjectMouseMove(x, y)
+		for _, btn := range []int{2, 4, 5, 6, 7} {
+			winl.InjectNativeButton(&w.Window, btn, true, x, y)
+			winl.InjectNativeButton(&w.Window, btn, false, x, y)
+		}
+		want := []string{
+			"el enter",
+			"el down 10,10",
+			"el up 10,10",
+			"el wheel true 1",
+			"el wheel true -1",
+			"el wheel false 1",
+			"el wheel false -1",
+		}
+		if !reflect.DeepEqual(log, want) {
+			t.Errorf("events = %q, want %q", log, want)
+		}
+	})
+}
+
+// lookPane is a 3D pane sums mouse deltas it receives
+type lookPane struct {
+	Pane3D
+	dx, dy float32
+}
+
+func (pn *lookPane) OnMouseDelta(dx, dy float32) bool {
+	pn.dx += dx
+	pn.dy += dy
+	return true
+}
+
+func TestMouseLook(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		pn := &lookPane{}
+		pn.Self = pn
+		pn.Init()
+		if err := w.SetLayout(&WndLayout{Pane: pn}); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+
+		if !pn.SetMouseLook(true) {
+			t.Fatal("SetMouseLook(true) failed")
+		}
+		w.InjectMouseDelta(3, -2)
+		w.InjectMouseDelta(1, 1)
+		if pn.dx != 4 || pn.dy != -1 {
+			t.Errorf("deltas = %g, %g, want 4, -1", pn.dx, pn.dy)
+		}
+		pn.SetMouseLook(false)
+		if w.IsRelativeMouse() || w.mouseLook != nil {
+			t.Errorf("after SetMouseLook(false), IsRelativeMouse() = %v, mouseLook = %v", w.IsRelativeMouse(), w.mouseLook)
+		}
+	})
+}
+
+// focusElem is a focusable widget, logs focus and key events it receives
+type focusElem struct {
+	Widget
+	name string
+	log  *[]string
+}
+
+func newFocusElem(name string, tabIndex int, log *[]string) *focusElem {
+	el := &focusElem{name: name, log: log}
+	el.Self = el
+	el.SetTabIndex(tabIndex)
+	return el
+}
+
+func (el *focusElem) Focusable() bool { return true }
+
+func (el *focusElem) OnFocusIn() { *el.log = append(*el.log, el.name+" in") }
+
+func (el *focusElem) OnFocusOut() { *el.log = append(*el.log, el.name+" out") }
+
+func (el *focusElem) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	*el.log = append(*el.log, el.name+" "+key.String())
+	return key == winl.KeyA && el.name == "a"
+}
+
+func TestFocus(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		if err := w.SetLayout(&WndLayout{Pane: NewPane()}); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+		pn := w.layout.Pane
+
+		var log []string
+		a := newFocusElem("a", 0, &log)
+		b := newFocusElem("b", 2, &log)
+		c := newFocusElem("c", 1, &log)
+		d := newFocusElem("d", -1, &log) // skipped by Tab
+		child := newFocusElem("child", 0, &log)
+		a.Insert(-1, child)
+		pn.Insert(-1, a)
+		pn.Insert(-1, b)
+		pn.Insert(-1, c)
+		pn.Insert(-1, d)
+
+		// c(1), b(2), then a, child in tree order
+		var got []string
+		for i := 0; i < 5; i++ {
+			w.InjectKey(winl.KeyTab, 0, true)
+			got = append(got, w.FocusOwner().(*focusElem).name)
+		}
+		w.InjectKey(winl.KeyTab, winl.ModShift, true)
+		got = append(got, w.FocusOwner().(*focusElem).name)
+		// lock keys don't stop Tab
+		w.InjectKey(winl.KeyTab, winl.ModNumLock|winl.ModCapsLock, true)
+		got = append(got, w.FocusOwner().(*focusElem).name)
+		w.InjectKey(winl.KeyTab, winl.ModShift|winl.ModNumLock, true)
+		got = append(got, w.FocusOwner().(*focusElem).name)
+		if want := []string{"c", "b", "a", "child", "c", "child", "c", "child"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Tab order = %q, want %q", got, want)
+		}
+
+		// key bubbles from child to a which handles it
+		log = nil
+		w.InjectKey(winl.KeyA, 0, true)
+		if want := []string{"child " + winl.KeyA.String(), "a " + winl.KeyA.String()}; !reflect.DeepEqual(log, want) {
+			t.Errorf("key events = %q, want %q", log, want)
+		}
+
+		d.SetFocus()
+		if !d.HasFocus() || child.HasFocus() {
+			t.Errorf("after SetFocus, d.HasFocus() = %v, child.HasFocus() = %v", d.HasFocus(), child.HasFocus())
+		}
+	})
+}
+
+func TestRemoveElem(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		if err := w.SetLayout(&WndLayout{Pane: NewPane()}); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+		s := w.ContentScale()
+		pn := w.layout.Pane
+
+		var log []string
+		e := newLogElem("e", Rect{10, 10, 100, 100}, &log, true)
+		f := newFocusElem("f", 0, &log)
+		pn.Insert(-1, e)
+		pn.Insert(-1, f)
+
+		// the capturing element is removed while mouse is pressed on it
+		w.InjectMouseMove(50*s, 50*s)
+		w.InjectMousePress(winl.MouseLeft, 50*s, 50*s)
+		if w.Capture() != e || !winl.MouseGrabbed(&w.Window) {
+			t.Errorf("Capture() = %v, want e with mouse grabbed", w.Capture())
+		}
+		log = nil
+		pn.Remove(pn.Index(e))
+		if w.Capture() != nil || winl.MouseGrabbed(&w.Window) {
+			t.Errorf("after Remove, Capture() = %v, grabbed = %v, want released", w.Capture(), winl.MouseGrabbed(&w.Window))
+		}
+		if w.Hover() != pn.(IElem) || !reflect.DeepEqual(log, []string{"e leave"}) {
+			t.Errorf("after Remove, Hover() = %v, events = %q, want pane and e leave", w.Hover(), log)
+		}
+		w.InjectMouseRelease(winl.MouseLeft, 50*s, 50*s)
+
+		// the focus owner is removed, keys go nowhere
+		f.SetFocus()
+		log = nil
+		pn.RemoveAll()
+		if w.FocusOwner() != nil {
+			t.Errorf("after RemoveAll, FocusOwner() = %v, want nil", w.FocusOwner())
+		}
+		w.InjectKey(winl.KeyA, 0, true)
+		if want := []string{"f out"}; !reflect.DeepEqual(log, want) {
+			t.Errorf("events after RemoveAll = %q, want %q", log, want)
+		}
+	})
+}
+
+func TestModifiers(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+
+		// mods reported by backend are held after the event
+		w.InjectKey(winl.KeyLeftShift, winl.ModShift, true)
+		w.InjectKey(winl.KeyRightShift, winl.ModShift, true)
+		w.InjectKey(winl.KeyRightShift, winl.ModShift, false)
+		if w.Mods() != winl.ModShift {
+			t.Errorf("Mods() with left Shift held = %v, want Shift", w.Mods())
+		}
+		w.InjectKey(winl.KeyLeftShift, 0, false)
+		if w.Mods() != 0 {
+			t.Errorf("Mods() after releasing both Shift keys = %v, want none", w.Mods())
+		}
+	})
+}
@@ -0,0 +1,480 @@
+package gui
+
+// Margins are spaces at four edges of a rect, in UI units
+type Margins struct {
+	Left, Top, Right, Bottom float32
+}
+
+// UniformMargins returns margins of v at all edges
+func UniformMargins(v float32) Margins {
+	return Margins{v, v, v, v}
+}
+
+// shrink returns rc without margins
+func (m Margins) shrink(rc Rect) Rect {
+	rc = Rect{rc[0] + m.Left, rc[1] + m.Top, rc[2] - m.Right, rc[3] - m.Bottom}
+	if rc[2] < rc[0] {
+		rc[2] = rc[0]
+	}
+	if rc[3] < rc[1] {
+		rc[3] = rc[1]
+	}
+	return rc
+}
+
+// size returns total of margins horizontally and vertically
+func (m Margins) size() Vec2 {
+	return Vec2{m.Left + m.Right, m.Top + m.Bottom}
+}
+
+// SizeHint is size range of element for layout, in UI units, 0 in Max means no limit
+type SizeHint struct {
+	Min  Vec2
+	Pref Vec2 // preferred size
+	Max  Vec2
+}
+
+// grow adds v to all sizes, Max without limit is kept
+func (h SizeHint) grow(v Vec2) SizeHint {
+	for i := 0; i < 2; i++ {
+		h.Min[i] += v[i]
+		h.Pref[i] += v[i]
+		if h.Max[i] > 0 {
+			h.Max[i] += v[i]
+		}
+	}
+	return h
+}
+
+// clamp v on axis i to the range of h
+func (h SizeHint) clamp(i int, v float32) float32 {
+	if h.Max[i] > 0 && v > h.Max[i] {
+		v = h.Max[i]
+	}
+	if v < h.Min[i] {
+		v = h.Min[i]
+	}
+	return v
+}
+
+// Layout arranges children of an element, set by Elem.SetLayout
+type Layout interface {
+	// SizeHint reports size range of el with its children arranged, padding of el is included
+	SizeHint(el IElem) SizeHint
+	// Arrange set bounds of children of el, within bounds of el without padding
+	Arrange(el IElem)
+}
+
+// childForgetter is implemented by layouts keeping settings of children, they are forgotten when the child is removed
+type childForgetter interface {
+	forget(c IElem)
+}
+
+// Layout returns the layout that arranges children, nil if they are placed by SetBounds
+func (el *Elem) Layout() Layout {
+	return el.layout
+}
+
+// SetLayout set the layout that arranges children, nil to place them by SetBounds
+func (el *Elem) SetLayout(l Layout) {
+	el.layout = l
+	el.Relayout()
+}
+
+// Relayout arranges children by layout, it's called when children or size are changed
+func (el *Elem) Relayout() {
+	if el.layout != nil {
+		el.layout.Arrange(el.Self.(IElem))
+	}
+}
+
+// Margin reports space around the element kept by layout of parent
+func (el *Elem) Margin() Margins {
+	return el.margin
+}
+
+// SetMargin set space around the element kept by layout of parent
+func (el *Elem) SetMargin(m Margins) {
+	el.margin = m
+	if el.parent != nil {
+		el.parent.Relayout()
+	}
+}
+
+// Padding reports space between bounds and children arranged by layout
+func (el *Elem) Padding() Margins {
+	return el.padding
+}
+
+// SetPadding set space between bounds and children arranged by layout
+func (el *Elem) SetPadding(p Margins) {
+	el.padding = p
+	el.Relayout()
+}
+
+// SizeHint reports size range for layout of parent, from SetSizeHint, layout, or current size in turn
+func (el *Elem) SizeHint() SizeHint {
+	if el.hint != nil {
+		return *el.hint
+	}
+	if el.layout != nil {
+		return el.layout.SizeHint(el.Self.(IElem))
+	}
+	return SizeHint{Pref: Vec2{el.bounds.Width(), el.bounds.Height()}}
+}
+
+// SetSizeHint overrides size range reported by SizeHint
+func (el *Elem) SetSizeHint(h SizeHint) {
+	el.hint = &h
+	if el.parent != nil {
+		el.parent.Relayout()
+	}
+}
+
+// contentRect returns local rect of el for children, without padding
+func contentRect(el IElem) Rect {
+	rc := el.Bounds()
+	return el.Padding().shrink(Rect{0, 0, rc.Width(), rc.Height()})
+}
+
+// outerHint returns size hint of c with its margins
+func outerHint(c IElem) SizeHint {
+	return c.SizeHint().grow(c.Margin().size())
+}
+
+// place set bounds of c to rc without its margins
+func place(c IElem, rc Rect) {
+	c.SetBounds(c.Margin().shrink(rc))
+}
+
+// distribute resizes sizes to fill avail, growing by factors of grow within max, or shrinking toward min.
+func distribute(sizes, mins, maxs, grow []float32, avail float32) {
+	total := float32(0)
+	for _, s := range sizes {
+		total += s
+	}
+	extra := avail - total
+	if extra < 0 {
+		slack := float32(0)
+		for i, s := range sizes {
+			slack += s - mins[i]
+		}
+		if slack <= 0 {
+			return
+		}
+		k := -extra / slack
+		if k > 1 {
+			k = 1
+		}
+		for i, s := range sizes {
+			sizes[i] = s - (s-mins[i])*k
+		}
+		return
+	}
+	// items reaching max stop growing, the rest share what's left
+	for extra > 0.01 {
+		sum := float32(0)
+		for i, g := range grow {
+			if g > 0 && (maxs[i] == 0 || sizes[i] < maxs[i]) {
+				sum += g
+			}
+		}
+		if sum == 0 {
+			return
+		}
+		left := extra
+		for i, g := range grow {
+			if g <= 0 || (maxs[i] > 0 && sizes[i] >= maxs[i]) {
+				continue
+			}
+			d := extra * g / sum
+			if maxs[i] > 0 && sizes[i]+d > maxs[i] {
+				d = maxs[i] - sizes[i]
+			}
+			sizes[i] += d
+			left -= d
+		}
+		if left == extra {
+			return
+		}
+		extra = left
+	}
+}
+
+// align returns the span of size within lo, hi, centered if it's smaller
+func align(lo, hi, size float32) (float32, float32) {
+	lo += (hi - lo - size) * 0.5
+	return lo, lo + size
+}
+
+// Box arranges children in a row, or a column if Vert, like flexbox.
+// children keep their preferred size, the extra space is shared by flex factors.
+type Box struct {
+	Vert    bool
+	Spacing float32 // space between children
+	flex    map[IElem]float32
+}
+
+// HBox returns a layout that arranges children from left to right
+func HBox(spacing float32) *Box {
+	return &Box{Spacing: spacing}
+}
+
+// VBox returns a layout that arranges children from top to bottom
+func VBox(spacing float32) *Box {
+	return &Box{Vert: true, Spacing: spacing}
+}
+
+// SetFlex set how much c grows to share the extra space, 0 (default) keeps its preferred size
+func (b *Box) SetFlex(c IElem, grow float32) {
+	if b.flex == nil {
+		b.flex = make(map[IElem]float32)
+	}
+	b.flex[c] = grow
+}
+
+func (b *Box) forget(c IElem) {
+	delete(b.flex, c)
+}
+
+// axes returns index of main and cross axis in Vec2
+func (b *Box) axes() (main, cross int) {
+	if b.Vert {
+		return 1, 0
+	}
+	return 0, 1
+}
+
+// SizeHint reports size range of el with its children arranged
+func (b *Box) SizeHint(el IElem) (h SizeHint) {
+	m, c := b.axes()
+	children := el.Children()
+	limited := len(children) > 0
+	for i, x := range children {
+		ch := outerHint(x)
+		if i > 0 {
+			h.Min[m] += b.Spacing
+			h.Pref[m] += b.Spacing
+			h.Max[m] += b.Spacing
+		}
+		h.Min[m] += ch.Min[m]
+		h.Pref[m] += ch.Pref[m]
+		h.Max[m] += ch.Max[m]
+		limited = limited && ch.Max[m] > 0
+		if ch.Min[c] > h.Min[c] {
+			h.Min[c] = ch.Min[c]
+		}
+		if ch.Pref[c] > h.Pref[c] {
+			h.Pref[c] = ch.Pref[c]
+		}
+	}
+	if !limited {
+		h.Max[m] = 0
+	}
+	return h.grow(el.Padding().size())
+}
+
+// Arrange set bounds of children of el
+func (b *Box) Arrange(el IElem) {
+	m, c := b.axes()
+	children := el.Children()
+	n := len(children)
+	if n == 0 {
+		return
+	}
+	rc := contentRect(el)
+	hints := make([]SizeHint, n)
+	sizes := make([]float32, n)
+	mins := make([]float32, n)
+	maxs := make([]float32, n)
+	grow := make([]float32, n)
+	for i, x := range children {
+		hints[i] = outerHint(x)
+		sizes[i] = hints[i].Pref[m]
+		mins[i] = hints[i].Min[m]
+		maxs[i] = hints[i].Max[m]
+		grow[i] = b.flex[x]
+	}
+	distribute(sizes, mins, maxs, grow, rc[m+2]-rc[m]-b.Spacing*float32(n-1))
+	pos := rc[m]
+	for i, x := range children {
+		var r Rect
+		r[m], r[m+2] = pos, pos+sizes[i]
+		r[c], r[c+2] = align(rc[c], rc[c+2], hints[i].clamp(c, rc[c+2]-rc[c]))
+		place(x, r)
+		pos += sizes[i] + b.Spacing
+	}
+}
+
+// Grid arranges children in cells of Cols columns, row by row.
+// columns and rows take their preferred size, then share the extra space equally.
+type Grid struct {
+	Cols    int
+	Spacing Vec2 // space between columns and rows
+}
+
+// NewGrid returns a grid layout of cols columns
+func NewGrid(cols int, spacing float32) *Grid {
+	return &Grid{Cols: cols, Spacing: Vec2{spacing, spacing}}
+}
+
+// tracks returns min and preferred sizes of columns (i = 0) or rows (i = 1)
+func (g *Grid) tracks(el IElem, i int) (mins, prefs []float32) {
+	cols := g.Cols
+	if cols < 1 {
+		cols = 1
+	}
+	children := el.Children()
+	n := cols
+	if i == 1 {
+		n = (len(children) + cols - 1) / cols
+	}
+	mins, prefs = make([]float32, n), make([]float32, n)
+	for k, x := range children {
+		t := k % cols
+		if i == 1 {
+			t = k / cols
+		}
+		h := outerHint(x)
+		if h.Min[i] > mins[t] {
+			mins[t] = h.Min[i]
+		}
+		if h.Pref[i] > prefs[t] {
+			prefs[t] = h.Pref[i]
+		}
+	}
+	return
+}
+
+// SizeHint reports size range of el with its children arranged
+func (g *Grid) SizeHint(el IElem) (h SizeHint) {
+	for i := 0; i < 2; i++ {
+		mins, prefs := g.tracks(el, i)
+		for k := range mins {
+			if k > 0 {
+				h.Min[i] += g.Spacing[i]
+				h.Pref[i] += g.Spacing[i]
+			}
+			h.Min[i] += mins[k]
+			h.Pref[i] += prefs[k]
+		}
+	}
+	return h.grow(el.Padding().size())
+}
+
+// Arrange set bounds of children of el
+func (g *Grid) Arrange(el IElem) {
+	children := el.Children()
+	if len(children) == 0 {
+		return
+	}
+	rc := contentRect(el)
+	var starts, sizes [2][]float32
+	for i := 0; i < 2; i++ {
+		mins, prefs := g.tracks(el, i)
+		n := len(prefs)
+		grow := make([]float32, n)
+		for k := range grow {
+			grow[k] = 1
+		}
+		distribute(prefs, mins, make([]float32, n), grow, rc[i+2]-rc[i]-g.Spacing[i]*float32(n-1))
+		starts[i] = make([]float32, n)
+		pos := rc[i]
+		for k, s := range prefs {
+			starts[i][k] = pos
+			pos += s + g.Spacing[i]
+		}
+		sizes[i] = prefs
+	}
+	cols := len(sizes[0])
+	for k, x := range children {
+		h := outerHint(x)
+		col, row := k%cols, k/cols
+		var r Rect
+		for i, t := range [2]int{col, row} {
+			r[i], r[i+2] = align(starts[i][t], starts[i][t]+sizes[i][t], h.clamp(i, sizes[i][t]))
+		}
+		place(x, r)
+	}
+}
+
+// Anchor places a child relative to its parent.
+// Min and Max are fractions of parent for left-top and right-bottom edges, Offset is added to the edges.
+// where Min equals Max on an axis, the child keeps preferred size, aligned at the fraction, i.e. 0.5 centers it.
+type Anchor struct {
+	Min, Max Vec2
+	Offset   Rect
+}
+
+// Common anchors
+var (
+	AnchorFill   = Anchor{Max: Vec2{1, 1}}
+	AnchorCenter = Anchor{Min: Vec2{0.5, 0.5}, Max: Vec2{0.5, 0.5}}
+)
+
+// AnchorLayout places each child by its Anchor, children without anchor fill the parent
+type AnchorLayout struct {
+	anchors map[IElem]Anchor
+}
+
+// NewAnchorLayout returns an anchor layout
+func NewAnchorLayout() *AnchorLayout {
+	return &AnchorLayout{anchors: make(map[IElem]Anchor)}
+}
+
+// SetAnchor set anchor of child c
+func (al *AnchorLayout) SetAnchor(c IElem, a Anchor) {
+	al.anchors[c] = a
+	if p := c.Parent(); p != nil {
+		p.Relayout()
+	}
+}
+
+func (al *AnchorLayout) forget(c IElem) {
+	delete(al.anchors, c)
+}
+
+// Anchor reports anchor of child c
+func (al *AnchorLayout) Anchor(c IElem) Anchor {
+	if a, ok := al.anchors[c]; ok {
+		return a
+	}
+	return AnchorFill
+}
+
+// SizeHint reports size range of el with its children arranged
+func (al *AnchorLayout) SizeHint(el IElem) (h SizeHint) {
+	for _, x := range el.Children() {
+		ch := outerHint(x)
+		for i := 0; i < 2; i++ {
+			if ch.Min[i] > h.Min[i] {
+				h.Min[i] = ch.Min[i]
+			}
+			if ch.Pref[i] > h.Pref[i] {
+				h.Pref[i] = ch.Pref[i]
+			}
+		}
+	}
+	return h.grow(el.Padding().size())
+}
+
+// Arrange set bounds of children of el
+func (al *AnchorLayout) Arrange(el IElem) {
+	rc := contentRect(el)
+	for _, x := range el.Children() {
+		a := al.Anchor(x)
+		h := outerHint(x)
+		var r Rect
+		for i := 0; i < 2; i++ {
+			size := rc[i+2] - rc[i]
+			lo := rc[i] + size*a.Min[i] + a.Offset[i]
+			hi := rc[i] + size*a.Max[i] + a.Offset[i+2]
+			if a.Min[i] == a.Max[i] {
+				lo -= h.Pref[i] * a.Min[i]
+				hi = lo + h.Pref[i]
+			}
+			r[i], r[i+2] = align(lo, hi, h.clamp(i, hi-lo))
+		}
+		place(x, r)
+	}
+}
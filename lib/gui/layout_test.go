@@ -0,0 +1,77 @@
+//go:build headless
+// +build headless
+
+package gui
+
+import "testing"
+
+// sized returns an element with preferred size w, h
+func sized(w, h float32) IElem {
+	el := NewElem()
+	el.SetSizeHint(SizeHint{Pref: Vec2{w, h}})
+	return el
+}
+
+func TestLayouts(t *testing.T) {
+	check := func(name string, el IElem, want []Rect) {
+		t.Helper()
+		for i, c := range el.Children() {
+			if got := c.Bounds(); got != want[i] {
+				t.Errorf("%s: bounds of child %d = %v, want %v", name, i, got, want[i])
+			}
+		}
+	}
+
+	// extra space goes to flexible children, c stops at its max
+	box := NewElem()
+	box.SetBounds(Rect{0, 0, 300, 100})
+	box.SetPadding(UniformMargins(5))
+	hb := HBox(10)
+	a, b := sized(50, 20), sized(50, 20)
+	c := NewElem()
+	c.SetSizeHint(SizeHint{Min: Vec2{20, 20}, Pref: Vec2{50, 20}, Max: Vec2{60, 60}})
+	hb.SetFlex(b, 1)
+	hb.SetFlex(c, 1)
+	box.SetLayout(hb)
+	box.Insert(-1, a)
+	box.Insert(-1, b)
+	box.Insert(-1, c)
+	check("HBox", box, []Rect{{5, 5, 55, 95}, {65, 5, 225, 95}, {235, 20, 295, 80}})
+	if h := box.SizeHint(); h.Pref != (Vec2{180, 30}) {
+		t.Errorf("HBox: preferred size = %v, want [180 30]", h.Pref)
+	}
+
+	// children are rearranged on resize
+	box.SetBounds(Rect{0, 0, 200, 50})
+	check("HBox resized", box, []Rect{{5, 5, 55, 45}, {65, 5, 125, 45}, {135, 5, 195, 45}})
+
+	// flex factors of removed children are forgotten
+	box.Remove(box.Index(c))
+	box.RemoveAll()
+	if len(hb.flex) != 0 {
+		t.Errorf("HBox: flex after removing children = %v, want empty", hb.flex)
+	}
+
+	grid := NewElem()
+	grid.SetBounds(Rect{0, 0, 100, 40})
+	grid.SetLayout(NewGrid(2, 0))
+	for i := 0; i < 4; i++ {
+		grid.Insert(-1, sized(40, 10))
+	}
+	check("Grid", grid, []Rect{{0, 0, 50, 20}, {50, 0, 100, 20}, {0, 20, 50, 40}, {50, 20, 100, 40}})
+
+	anchor := NewElem()
+	anchor.SetBounds(Rect{0, 0, 100, 40})
+	al := NewAnchorLayout()
+	anchor.SetLayout(al)
+	x, y := sized(40, 10), sized(40, 10)
+	anchor.Insert(-1, x)
+	anchor.Insert(-1, y)
+	al.SetAnchor(x, AnchorCenter)
+	al.SetAnchor(y, Anchor{Min: Vec2{1, 0}, Max: Vec2{1, 1}, Offset: Rect{-5, 5, -5, -5}})
+	check("Anchor", anchor, []Rect{{30, 15, 70, 25}, {55, 5, 95, 35}})
+	anchor.Remove(anchor.Index(x))
+	if len(al.anchors) != 1 {
+		t.Errorf("Anchor: anchors after removing a child = %v, want 1", al.anchors)
+	}
+}
@@ -1,5 +1,8 @@
 package gui
 
+// minPaneSize is the default minimum width and height of pane, in UI units
+const minPaneSize = 40
+
 // Pane is compound widget typically use as split area in window.
 type Pane struct {
 	Widget
@@ -19,3 +22,22 @@ func (pn *Pane) SetState(data []byte) error {
 func (pn *Pane) Is3D() bool {
 	return false
 }
+
+// AcceptDrop reports whether files can be dropped at x, y, in pane coordinates
+func (pn *Pane) AcceptDrop(x, y float32) bool {
+	return false
+}
+
+// OnDrop event handler, paths are local files dropped at x, y, in pane coordinates
+func (pn *Pane) OnDrop(paths []string, x, y float32) {
+}
+
+// MinSize reports minimum size of pane, splitters can't be dragged to make it smaller
+func (pn *Pane) MinSize() (width, height float32) {
+	return minPaneSize, minPaneSize
+}
+
+// MaxSize reports maximum size of pane, 0 for no limit
+func (pn *Pane) MaxSize() (width, height float32) {
+	return 0, 0
+}
@@ -56,6 +56,37 @@ func (pn *Pane3D) LookAt(eye Vec3, lookAt Vec3, up Vec3) {
 	pn.MatV = geom.Mat4LookAt(eye, lookAt, up)
 }
 
+// CaptureMouse keep receiving mouse events when dragging out of window, i.e. orbit the camera
+func (pn *Pane3D) CaptureMouse() bool {
+	w := pn.Window()
+	if w == nil {
+		return false
+	}
+	return w.GrabMouse()
+}
+
+// ReleaseMouse release the mouse captured by CaptureMouse
+func (pn *Pane3D) ReleaseMouse() {
+	if w := pn.Window(); w != nil {
+		w.UngrabMouse()
+	}
+}
+
+// SetMouseLook enable relative mouse mode for FPS-style camera, the pane receives OnMouseDelta instead of OnMouseMove
+func (pn *Pane3D) SetMouseLook(enable bool) bool {
+	w := pn.Window()
+	if w == nil {
+		return false
+	}
+	if enable {
+		return w.SetMouseLook(pn.Self.(IElem))
+	}
+	if w.MouseLook() == pn.Self.(IElem) {
+		return w.SetMouseLook(nil)
+	}
+	return true
+}
+
 // Render the pane
 func (pn *Pane3D) Render() {
 	viewportBak := glman.GetViewport()
@@ -12,6 +12,9 @@ type TestPane struct {
 func (pn *TestPane) Init() {
 	pn.btn = NewButton()
 	pn.btn.SetFont(glman.LoadFont("WQY-ZenHei", 20))
+	al := NewAnchorLayout()
+	al.SetAnchor(pn.btn, AnchorCenter)
+	pn.SetLayout(al)
 	pn.Insert(-1, pn.btn)
 }
 
@@ -1,5 +1,11 @@
 package gui
 
+import (
+	"path/filepath"
+	"strings"
+	"tetra/lib/dbg"
+)
+
 // TestPane3D is a pane for 3D scene
 type TestPane3D struct {
 	Pane3D
@@ -21,3 +27,18 @@ func (pn *TestPane3D) SetState(data []byte) error {
 	//pn.btn.SetText(string(data))
 	return nil
 }
+
+// AcceptDrop reports whether files can be dropped at x, y, in pane coordinates
+func (pn *TestPane3D) AcceptDrop(x, y float32) bool {
+	return true
+}
+
+// OnDrop event handler, logs dropped model files
+func (pn *TestPane3D) OnDrop(paths []string, x, y float32) {
+	for _, p := range paths {
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".obj", ".dae":
+			dbg.Logf("TestPane3D.OnDrop(%q, %f, %f)\n", p, x, y)
+		}
+	}
+}
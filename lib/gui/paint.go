@@ -0,0 +1,165 @@
+package gui
+
+import (
+	"math"
+	"tetra/internal/gl"
+	"tetra/lib/geom"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+)
+
+// backBuffer keeps content of window between repaints, so only damaged area is redrawn,
+// then the whole is copied to window, content of window's own back buffer is undefined after swap.
+type backBuffer struct {
+	fbo, rbo      uint32
+	width, height int32
+	failed        bool // framebuffer is not supported, window is redrawn entirely
+}
+
+// bind the framebuffer of size width, height in pixels for drawing, returns false if it's not available.
+// fresh is true if it's created or resized, its content is undefined.
+func (bb *backBuffer) bind(width, height int32) (ok, fresh bool) {
+	if bb.failed || width <= 0 || height <= 0 {
+		return false, false
+	}
+	if bb.fbo == 0 {
+		gl.GenFramebuffers(1, &bb.fbo)
+		gl.GenRenderbuffers(1, &bb.rbo)
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, bb.fbo)
+	if width != bb.width || height != bb.height {
+		gl.BindRenderbuffer(gl.RENDERBUFFER, bb.rbo)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA8, width, height)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, bb.rbo)
+		if gl.CheckFramebufferStatus(gl.FRAMEBUFFER) != gl.FRAMEBUFFER_COMPLETE {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			bb.release()
+			bb.failed = true
+			return false, false
+		}
+		bb.width, bb.height = width, height
+		fresh = true
+	}
+	return true, fresh
+}
+
+// blit copies the content to window's back buffer, and binds it for drawing
+func (bb *backBuffer) blit() {
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, bb.fbo)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, 0)
+	gl.BlitFramebuffer(0, 0, bb.width, bb.height, 0, 0, bb.width, bb.height, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// release the framebuffer, context of window must be current
+func (bb *backBuffer) release() {
+	if bb.fbo != 0 {
+		gl.DeleteFramebuffers(1, &bb.fbo)
+		gl.DeleteRenderbuffers(1, &bb.rbo)
+	}
+	*bb = backBuffer{}
+}
+
+// clientRect returns client area of window in UI units
+func (w *Window) clientRect() Rect {
+	width, height := w.Size()
+	s := w.ContentScale()
+	return Rect{0, 0, width / s, height / s}
+}
+
+// Invalidate marks rc in UI units to be repainted by the next expose event, empty rc for the whole window
+func (w *Window) Invalidate(rc Rect) {
+	if rc.IsEmpty() {
+		rc = w.clientRect()
+	}
+	// damage is accumulated and repainted at once
+	w.damage = w.damage.Union(rc)
+	s := w.ContentScale()
+	x0, y0 := float32(math.Floor(float64(rc[0]*s))), float32(math.Floor(float64(rc[1]*s)))
+	x1, y1 := float32(math.Ceil(float64(rc[2]*s))), float32(math.Ceil(float64(rc[3]*s)))
+	w.Expose(x0, y0, x1-x0, y1-y0)
+}
+
+// Damage reports area waiting to be repainted, in UI units
+func (w *Window) Damage() Rect {
+	return w.damage
+}
+
+// Render repaints the whole window
+func (w *Window) Render() {
+	w.damage = w.clientRect()
+	w.paint()
+}
+
+// paint repaints damaged area, drawing is clipped to it by scissor test
+func (w *Window) paint() {
+	client := w.clientRect()
+	damage := w.damage.Intersect(client)
+	w.damage = Rect{}
+	if damage.IsEmpty() {
+		return
+	}
+
+	w.MakeCurrent()
+	width, height := w.Size()
+	ok, fresh := w.back.bind(int32(width), int32(height))
+	if !ok || fresh {
+		damage = client
+	}
+
+	glman.StackMatM.Push()
+	glman.StackMatM.Load(geom.Mat4Ident())
+	glman.StackMatV.Push()
+	glman.StackMatV.Load(w.matView)
+	glman.StackMatP.Push()
+	glman.StackMatP.Load(w.matProj)
+	glman.StackClip2D.Push()
+	glman.StackClip2D.Load(damage)
+
+	defer func() {
+		glman.StackClip2D.Pop()
+		glman.StackMatM.Pop()
+		glman.StackMatV.Pop()
+		glman.StackMatP.Pop()
+	}()
+
+	//dbg.Logln("func (w *Window) paint()")
+	glman.SetContentScale(w.ContentScale())
+	// scissor is in pixels, Y-UP
+	s := w.ContentScale()
+	x0, x1 := int32(math.Floor(float64(damage[0]*s))), int32(math.Ceil(float64(damage[2]*s)))
+	y0, y1 := int32(math.Floor(float64(height-damage[3]*s))), int32(math.Ceil(float64(height-damage[1]*s)))
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(x0, y0, x1-x0, y1-y0)
+	//gl.ClearColor(0.8, 0.8, 0.9, 1.0)
+	gl.ClearColor(0, 0, 0, 0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+
+	if w.layout != nil {
+		// panes out of damaged area are skipped
+		dirty := func(pn IPane) bool {
+			return !pn.Bounds().Intersect(damage).IsEmpty()
+		}
+		w.layout.Render(func(pn IPane) bool { return pn.Is3D() && dirty(pn) })
+		w.layout.Render(func(pn IPane) bool { return !pn.Is3D() && dirty(pn) })
+	}
+	w.renderDock()
+
+	glman.DynDrawRect(Rect{10, 10, 300, 300}, Color{0, 0, 1, 0.5}, 3)
+	glman.DynDrawText("ASDF", Rect{10, 60, 300, 300}, glman.LoadFont("WQY-ZenHei", 20), Color{0, 0, 1, 1}, 0)
+
+	if len(w.dialogs) > 0 {
+		glman.DynFillRect(client, skin.Get().Color(skin.RoleShade))
+		for _, dlg := range w.dialogs {
+			dlg.Render()
+		}
+	}
+	gl.Disable(gl.SCISSOR_TEST)
+
+	if ok {
+		w.back.blit()
+	}
+	w.Present()
+	glman.Routine()
+}
@@ -1,50 +1,348 @@
 package gui
 
 import (
-	"tetra/lib/dbg"
+	"tetra/internal/winl"
 	"tetra/lib/glman"
+	"tetra/lib/skin"
 )
 
-// Button is button widget
+// Button is push button widget, with text and optional icon.
+// it's clicked by mouse, or Space and Enter key when focused.
+// a checkable button toggles its checked state by click.
 type Button struct {
 	Widget
-	fnt glman.Font
-	txt glman.MText
+	fnt       glman.Font // "" for font of skin
+	text      string
+	icon      string // name of glman icon, "" for none
+	checkable bool
+	checked   bool
+	hover     bool // mouse is over it
+	pressed   bool // pressed by mouse, looks pressed while mouse is over it
+	keyDown   bool // pressed by Space
+	onClick   []func()
 }
 
-// Font returns current font
+// Font returns current font, the font of skin if not set
 func (btn *Button) Font() glman.Font {
+	if btn.fnt == "" {
+		return skin.Get().Font()
+	}
 	return btn.fnt
 }
 
-// SetFont set the font
+// SetFont set the font, "" for the font of skin
 func (btn *Button) SetFont(f glman.Font) {
 	btn.fnt = f
-	s := btn.Text()
-	btn.txt = nil
-	btn.SetText(s)
+	btn.changed()
 }
 
 // Text label on the button
 func (btn *Button) Text() string {
-	if btn.txt == nil {
-		return ""
-	}
-	return btn.txt.Text()
+	return btn.text
 }
 
 // SetText set the text label on the button
 func (btn *Button) SetText(s string) {
-	if s == btn.Text() {
+	if s == btn.text {
+		return
+	}
+	btn.text = s
+	btn.changed()
+}
+
+// Icon reports name of icon shown before text, "" for none
+func (btn *Button) Icon() string {
+	return btn.icon
+}
+
+// SetIcon set name of glman icon shown before text, "" for none
+func (btn *Button) SetIcon(name string) {
+	if name == btn.icon {
+		return
+	}
+	btn.icon = name
+	btn.changed()
+}
+
+// Checkable reports whether the button toggles checked state by click
+func (btn *Button) Checkable() bool {
+	return btn.checkable
+}
+
+// SetCheckable set whether the button toggles checked state by click
+func (btn *Button) SetCheckable(b bool) {
+	btn.checkable = b
+	if !b {
+		btn.SetChecked(false)
+	}
+}
+
+// Checked reports whether the button is checked
+func (btn *Button) Checked() bool {
+	return btn.checked
+}
+
+// SetChecked set checked state, without calling click handlers
+func (btn *Button) SetChecked(b bool) {
+	if b != btn.checked {
+		btn.checked = b
+		btn.Invalidate(Rect{})
+	}
+}
+
+// Toggle changes checked state as it's clicked
+func (btn *Button) Toggle() {
+	btn.Self.(IButton).SetChecked(!btn.checked)
+}
+
+// OnClick adds f to handlers called when the button is clicked, after checked state is toggled
+func (btn *Button) OnClick(f func()) {
+	btn.onClick = append(btn.onClick, f)
+}
+
+// Click the button as by user, does nothing if it's disabled
+func (btn *Button) Click() {
+	if !btn.Enabled() {
+		return
+	}
+	if btn.checkable {
+		btn.Self.(IButton).Toggle()
+	}
+	for _, f := range btn.onClick {
+		f()
+	}
+}
+
+// changed relayout and redraw after content is changed
+func (btn *Button) changed() {
+	if p := btn.Parent(); p != nil {
+		p.Relayout()
+	}
+	btn.Invalidate(Rect{})
+}
+
+// state reports state of the button for skin drawing
+func (btn *Button) state() (st skin.State) {
+	if !btn.Enabled() {
+		return skin.StateDisabled
+	}
+	if btn.hover {
+		st |= skin.StateHover
+	}
+	if btn.pressed && btn.hover || btn.keyDown {
+		st |= skin.StatePressed
+	}
+	if btn.HasFocus() {
+		st |= skin.StateFocus
+	}
+	if btn.checked {
+		st |= skin.StateChecked
+	}
+	return
+}
+
+// setPressed changes pressed state by mouse, or by key if key, and redraw
+func (btn *Button) setPressed(b, key bool) {
+	p := &btn.pressed
+	if key {
+		p = &btn.keyDown
+	}
+	if b != *p {
+		*p = b
+		btn.Invalidate(Rect{})
+	}
+}
+
+// setHover changes hover state and redraw
+func (btn *Button) setHover(b bool) {
+	if b != btn.hover {
+		btn.hover = b
+		btn.Invalidate(Rect{})
+	}
+}
+
+// Focusable reports whether the widget accepts keyboard focus, a disabled button doesn't
+func (btn *Button) Focusable() bool {
+	return btn.Enabled()
+}
+
+// OnFocusIn event handler, redraw to show focus
+func (btn *Button) OnFocusIn() {
+	btn.Invalidate(Rect{})
+}
+
+// OnFocusOut event handler, cancels press by Space
+func (btn *Button) OnFocusOut() {
+	btn.keyDown = false
+	btn.Invalidate(Rect{})
+}
+
+// OnMouseEnter event handler
+func (btn *Button) OnMouseEnter() {
+	btn.setHover(true)
+}
+
+// OnMouseLeave event handler
+func (btn *Button) OnMouseLeave() {
+	btn.setHover(false)
+}
+
+// OnMouseDown event handler, left button presses it
+func (btn *Button) OnMouseDown(b int, x, y float32) bool {
+	if b != winl.MouseLeft || !btn.Enabled() {
+		return false
+	}
+	btn.setPressed(true, false)
+	return true
+}
+
+// inside reports whether x, y in local coordinates is in bounds
+func (btn *Button) inside(x, y float32) bool {
+	return x >= 0 && y >= 0 && x < btn.bounds.Width() && y < btn.bounds.Height()
+}
+
+// OnMouseMove event handler, pressed button tracks whether mouse is still over it
+func (btn *Button) OnMouseMove(x, y float32) bool {
+	if !btn.pressed {
+		return false
+	}
+	btn.setHover(btn.inside(x, y))
+	return true
+}
+
+// OnMouseUp event handler, clicked if released over the button
+func (btn *Button) OnMouseUp(b int, x, y float32) bool {
+	if b != winl.MouseLeft || !btn.pressed {
+		return false
+	}
+	btn.setPressed(false, false)
+	if btn.inside(x, y) {
+		btn.Click()
+	}
+	return true
+}
+
+// OnKeyDown event handler, Space presses the button until released, Enter clicks it
+func (btn *Button) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	if !btn.Enabled() || mods&(winl.ModControl|winl.ModAlt) != 0 {
+		return false
+	}
+	switch key {
+	case winl.KeySpace:
+		btn.setPressed(true, true)
+		return true
+	case winl.KeyEnter, winl.KeyKPEnter:
+		if !repeat {
+			btn.Click()
+		}
+		return true
+	}
+	return false
+}
+
+// OnKeyUp event handler, releasing Space clicks the button pressed by it
+func (btn *Button) OnKeyUp(key winl.Key, mods winl.Mod) bool {
+	if key != winl.KeySpace || !btn.keyDown {
+		return false
+	}
+	btn.setPressed(false, true)
+	btn.Click()
+	return true
+}
+
+// contentSize reports size of icon and text, and size of icon which is height of text line
+func (btn *Button) contentSize() (w, h, szIcon float32) {
+	fnt := btn.Font()
+	w, h = glman.MeasureText(btn.text, fnt)
+	if btn.icon == "" {
 		return
 	}
-	btn.txt = btn.fnt.MkMText(s, btn.Bounds().Width(), btn.Bounds().Height(), 0)
+	_, szIcon = glman.MeasureText("", fnt)
+	if btn.text != "" {
+		w += skin.Get().SizePadding() / 2
+	}
+	w += szIcon
+	if szIcon > h {
+		h = szIcon
+	}
+	return
+}
+
+// renderContent draws icon and text in rc, centered if center, or at left
+func (btn *Button) renderContent(rc Rect, st skin.State, center bool) {
+	w, h, szIcon := btn.contentSize()
+	x := rc[0]
+	if center {
+		x = round(rc[0] + (rc.Width()-w)*0.5)
+	}
+	y := round(rc[1] + (rc.Height()-h)*0.5)
+	color := skin.Get().TextColor(st)
+	if btn.icon != "" {
+		glman.DynDrawIcon(btn.icon, Rect{x, y, x + szIcon, y + h}, color)
+		x += szIcon
+		if btn.text != "" {
+			x += skin.Get().SizePadding() / 2
+		}
+	}
+	if btn.text != "" {
+		glman.DynDrawText(btn.text, Rect{x, y, rc[2], y + h}, btn.Font(), color, glman.DtSingleLine|glman.DtVCenter)
+	}
+}
+
+// indicatorSize reports size of the box of check box or circle of radio button, which is height of text line
+func (btn *Button) indicatorSize() float32 {
+	_, h := glman.MeasureText("", btn.Font())
+	return h
+}
+
+// indicatorHint reports size hint of button with an indicator at left of its content
+func (btn *Button) indicatorHint() SizeHint {
+	if btn.hint != nil {
+		return *btn.hint
+	}
+	sz := btn.indicatorSize()
+	w, h, _ := btn.contentSize()
+	if w > 0 {
+		w += skin.Get().SizePadding() / 2
+	}
+	if h < sz {
+		h = sz
+	}
+	hint := Vec2{sz + w, h}
+	return SizeHint{Min: hint, Pref: hint}
+}
+
+// renderIndicator draws indicator by draw at left, then content of the button
+func (btn *Button) renderIndicator(draw func(rc Rect, st skin.State)) {
+	st := btn.state()
+	rc := btn.bounds
+	sz := btn.indicatorSize()
+	y := round(rc[1] + (rc.Height()-sz)*0.5)
+	draw(Rect{rc[0], y, rc[0] + sz, y + sz}, st)
+	glman.StackClip2D.Push()
+	glman.StackClip2D.Load(glman.StackClip2D.Peek().Intersect(btn.WindowBounds()))
+	rc[0] += sz + skin.Get().SizePadding()/2
+	btn.renderContent(rc, st, false)
+	glman.StackClip2D.Pop()
+}
+
+// SizeHint reports size of content with padding as preferred size
+func (btn *Button) SizeHint() SizeHint {
+	if btn.hint != nil {
+		return *btn.hint
+	}
+	pad := skin.Get().SizePadding()
+	w, h, _ := btn.contentSize()
+	sz := Vec2{w + pad*2, h + pad}
+	return SizeHint{Min: sz, Pref: sz}
 }
 
 // Render the element
 func (btn *Button) Render() {
-	dbg.Logf("func (btn *Button) Render()\n")
-	if btn.txt != nil {
-		btn.txt.Render()
-	}
+	st := btn.state()
+	skin.Get().DrawButton(btn.bounds, st)
+	glman.StackClip2D.Push()
+	glman.StackClip2D.Load(glman.StackClip2D.Peek().Intersect(btn.WindowBounds()))
+	btn.renderContent(btn.bounds, st, true)
+	glman.StackClip2D.Pop()
 }
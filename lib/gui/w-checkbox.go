@@ -0,0 +1,24 @@
+package gui
+
+import "tetra/lib/skin"
+
+// CheckBox is a checkable button drawn as a box with check mark, followed by text
+type CheckBox struct {
+	Button
+}
+
+// Init a new object
+func (cb *CheckBox) Init() {
+	cb.Button.Init()
+	cb.checkable = true
+}
+
+// SizeHint reports size of box and text
+func (cb *CheckBox) SizeHint() SizeHint {
+	return cb.indicatorHint()
+}
+
+// Render the element
+func (cb *CheckBox) Render() {
+	cb.renderIndicator(skin.Get().DrawCheckBox)
+}
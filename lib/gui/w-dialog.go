@@ -0,0 +1,322 @@
+package gui
+
+import (
+	"strings"
+	"tetra/internal/winl"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+)
+
+// rows of list shown in dialog
+const dialogListRows = 10
+
+// Dialog is modal box shown over window content, with message,
+// optional input line and list, and a row of buttons.
+// it's shown by Window.ShowDialog, user input of the window goes to the top most dialog.
+type Dialog struct {
+	Widget
+
+	title string
+	msg   []string // lines of message
+	def   int      // button activated by Enter, -1 for none
+	esc   int      // button activated by Escape, -1 for none
+
+	// children in Tab order
+	edit *LineEdit   // input line, nil for none
+	sv   *ScrollView // scrolls list, nil for none
+	list *ListBox
+	btns []*Button
+
+	// rects in window coordinates, calc by Center
+	rcTitle Rect
+	rcMsg   Rect
+
+	done     func(btn int)
+	onSelect func(i int)
+	onEnter  func(i int) bool
+}
+
+// Init a new object
+func (dl *Dialog) Init() {
+	dl.def, dl.esc = -1, -1
+}
+
+// Title of the dialog
+func (dl *Dialog) Title() string {
+	return dl.title
+}
+
+// SetTitle set title of the dialog
+func (dl *Dialog) SetTitle(s string) {
+	dl.title = s
+}
+
+// SetMessage set message of the dialog, lines are separated by '\n'
+func (dl *Dialog) SetMessage(s string) {
+	if s == "" {
+		dl.msg = nil
+	} else {
+		dl.msg = strings.Split(s, "\n")
+	}
+}
+
+// SetButtons set buttons, def is activated by Enter and esc by Escape, -1 for none
+func (dl *Dialog) SetButtons(def, esc int, buttons []string) {
+	for range dl.btns {
+		dl.Remove(len(dl.Children()) - 1)
+	}
+	dl.btns = make([]*Button, len(buttons))
+	for i, s := range buttons {
+		i := i
+		btn := NewButton()
+		btn.SetText(s)
+		btn.OnClick(func() { dl.Close(i) })
+		dl.btns[i] = btn
+		dl.Insert(-1, btn)
+	}
+	dl.def, dl.esc = def, esc
+}
+
+// SetInput show input line with initial text, it takes focus when the dialog is shown
+func (dl *Dialog) SetInput(text string) {
+	if dl.edit == nil {
+		dl.edit = NewLineEdit()
+		dl.Insert(0, dl.edit)
+	}
+	dl.edit.SetText(text)
+}
+
+// Input returns text of the input line
+func (dl *Dialog) Input() string {
+	if dl.edit == nil {
+		return ""
+	}
+	return dl.edit.Text()
+}
+
+// SetList show a list of entries, the first one is selected
+func (dl *Dialog) SetList(entries []string) {
+	if dl.list == nil {
+		dl.list = NewListBox()
+		dl.list.OnSelect(func(i int) {
+			if dl.onSelect != nil {
+				dl.onSelect(i)
+			}
+		})
+		dl.list.OnActivate(func(int) { dl.enter() })
+		dl.sv = NewScrollView()
+		dl.sv.SetContent(dl.list)
+		i := 0
+		if dl.edit != nil {
+			i = 1
+		}
+		dl.Insert(i, dl.sv)
+	}
+	dl.list.SetItems(entries)
+	dl.sv.SetScrollPos(Vec2{})
+	if len(entries) > 0 {
+		dl.list.SetSelected(0)
+	}
+}
+
+// Selected returns index of selected entry of list, -1 for none
+func (dl *Dialog) Selected() int {
+	if dl.list == nil {
+		return -1
+	}
+	return dl.list.Selected()
+}
+
+// OnDone set the function called with index of button when dialog is closed
+func (dl *Dialog) OnDone(f func(btn int)) {
+	dl.done = f
+}
+
+// OnSelect set the function called when selection of list is changed
+func (dl *Dialog) OnSelect(f func(i int)) {
+	dl.onSelect = f
+}
+
+// OnEnter set the function called when list entry is double-clicked or Enter is pressed, returns true if handled
+func (dl *Dialog) OnEnter(f func(i int) bool) {
+	dl.onEnter = f
+}
+
+// Close the dialog as if button btn is pressed
+func (dl *Dialog) Close(btn int) {
+	if w := dl.Window(); w != nil {
+		w.CloseDialog(dl.Self.(IDialog))
+	}
+	if dl.done != nil {
+		dl.done(btn)
+	}
+}
+
+// Center calc layout of dialog, in the center of window of size width and height
+func (dl *Dialog) Center(width, height float32) {
+	sk := skin.Get()
+	pad := sk.SizePadding()
+	fnt := sk.Font()
+	_, lh := glman.MeasureText("M", fnt)
+
+	// width fits the content
+	w := float32(320)
+	if dl.list != nil {
+		w = 480
+	}
+	if tw, _ := glman.MeasureText(dl.title, fnt); tw+pad*2 > w {
+		w = tw + pad*2
+	}
+	for _, s := range dl.msg {
+		if tw, _ := glman.MeasureText(s, fnt); tw+pad*2 > w {
+			w = tw + pad*2
+		}
+	}
+	var btnsW, btnH float32
+	btnW := make([]float32, len(dl.btns))
+	for i, btn := range dl.btns {
+		sz := btn.SizeHint().Pref
+		btnW[i] = sz[0] + pad*2
+		if btnW[i] < 80 {
+			btnW[i] = 80
+		}
+		btnsW += btnW[i] + pad
+		if sz[1] > btnH {
+			btnH = sz[1]
+		}
+	}
+	if btnsW+pad > w {
+		w = btnsW + pad
+	}
+	if w > width-pad*2 {
+		w = width - pad*2
+	}
+
+	// height of parts from top to bottom
+	h := lh + pad + pad
+	if len(dl.msg) > 0 {
+		h += lh*float32(len(dl.msg)) + pad
+	}
+	if dl.edit != nil {
+		h += lh + pad + pad
+	}
+	var listH float32
+	if dl.list != nil {
+		listH = dl.list.rowHeight()*dialogListRows + 2
+		h += listH + pad
+	}
+	h += btnH + pad
+
+	x0 := round((width - w) / 2)
+	y0 := round((height - h) / 3)
+	if y0 < 0 {
+		y0 = 0
+	}
+	dl.SetBounds(Rect{x0, y0, x0 + w, y0 + h})
+
+	dl.rcTitle = Rect{x0, y0, x0 + w, y0 + lh + pad}
+	// children are in coordinates of the dialog
+	y := lh + pad + pad
+	if len(dl.msg) > 0 {
+		dl.rcMsg = Rect{x0 + pad, y0 + y, x0 + w - pad, y0 + y + lh*float32(len(dl.msg))}
+		y += lh*float32(len(dl.msg)) + pad
+	}
+	if dl.edit != nil {
+		dl.edit.SetBounds(Rect{pad, y, w - pad, y + lh + pad})
+		y += lh + pad + pad
+	}
+	if dl.list != nil {
+		// inside the frame drawn by Render
+		dl.sv.SetBounds(Rect{pad + 1, y + 1, w - pad - 1, y + listH - 1})
+		y += listH + pad
+	}
+	// buttons are right aligned
+	x := w - pad
+	for i := len(dl.btns) - 1; i >= 0; i-- {
+		dl.btns[i].SetBounds(Rect{x - btnW[i], y, x, y + btnH})
+		x -= btnW[i] + pad
+	}
+}
+
+func (dl *Dialog) enter() {
+	if sel := dl.Selected(); sel >= 0 && dl.onEnter != nil && dl.onEnter(sel) {
+		return
+	}
+	if dl.def >= 0 {
+		dl.Close(dl.def)
+	}
+}
+
+// OnKeyDown event handler, keys not handled by the focused child come here, list keys move its selection
+func (dl *Dialog) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	switch key {
+	case winl.KeyEnter, winl.KeyKPEnter:
+		dl.enter()
+	case winl.KeyEscape:
+		if dl.esc >= 0 {
+			dl.Close(dl.esc)
+		}
+	case winl.KeyUp, winl.KeyDown, winl.KeyPageUp, winl.KeyPageDown, winl.KeyHome, winl.KeyEnd:
+		return dl.list != nil && dl.list.OnKeyDown(key, mods, repeat)
+	default:
+		return false
+	}
+	return true
+}
+
+// OnMouseMove event handler, mouse doesn't go through the dialog
+func (dl *Dialog) OnMouseMove(x, y float32) bool {
+	return true
+}
+
+// OnMouseDown event handler, mouse doesn't go through the dialog
+func (dl *Dialog) OnMouseDown(btn int, x, y float32) bool {
+	return true
+}
+
+// OnMouseUp event handler, mouse doesn't go through the dialog
+func (dl *Dialog) OnMouseUp(btn int, x, y float32) bool {
+	return true
+}
+
+// OnMouseWheel event handler, mouse doesn't go through the dialog
+func (dl *Dialog) OnMouseWheel(vert bool, dz float32) bool {
+	return true
+}
+
+// Render the dialog
+func (dl *Dialog) Render() {
+	sk := skin.Get()
+	pad := sk.SizePadding()
+	fnt := sk.Font()
+	text := sk.Color(skin.RoleText)
+
+	glman.DynFillRect(dl.bounds, sk.Color(skin.RoleWindow))
+	glman.DynDrawRect(dl.bounds, sk.Color(skin.RoleFrame), 1)
+
+	glman.DynFillRect(dl.rcTitle, sk.Color(skin.RoleHighlight))
+	glman.DynDrawText(dl.title, inset(dl.rcTitle, pad, pad/2), fnt, sk.Color(skin.RoleHighlightText), glman.DtSingleLine)
+
+	_, lh := glman.MeasureText("M", fnt)
+	for i, s := range dl.msg {
+		y := dl.rcMsg[1] + lh*float32(i)
+		glman.DynDrawText(s, Rect{dl.rcMsg[0], y, dl.rcMsg[2], y + lh}, fnt, text, glman.DtSingleLine)
+	}
+
+	if dl.sv != nil {
+		// frame of the list, as of a text entry
+		var st skin.State
+		if dl.list.HasFocus() {
+			st = skin.StateFocus
+		}
+		sk.DrawEdit(inset(dl.sv.WindowBounds(), -1, -1), st)
+	}
+
+	// input line, list and buttons
+	dl.Widget.Render()
+}
+
+// inset returns rc shrinked by dx at left and right, dy at top and bottom
+func inset(rc Rect, dx, dy float32) Rect {
+	return Rect{rc[0] + dx, rc[1] + dy, rc[2] - dx, rc[3] - dy}
+}
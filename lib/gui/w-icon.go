@@ -0,0 +1,73 @@
+package gui
+
+import (
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+)
+
+// Icon is a widget shows vector icon registered by glman.RegisterIcon, it's sharp at any size
+type Icon struct {
+	Widget
+	name    string
+	size    float32 // preferred size, 0 for height of skin font
+	color   Color
+	colored bool // color is set by SetColor, otherwise text color of skin
+}
+
+// Name reports name of the icon
+func (ic *Icon) Name() string {
+	return ic.name
+}
+
+// SetName set the icon by name, such as "check", "close", "arrow-down"
+func (ic *Icon) SetName(name string) {
+	ic.name = name
+	ic.Invalidate(Rect{})
+}
+
+// IconSize reports preferred size of the icon
+func (ic *Icon) IconSize() float32 {
+	if ic.size <= 0 {
+		_, h := glman.MeasureText("", skin.Get().Font())
+		return h
+	}
+	return ic.size
+}
+
+// SetIconSize set preferred size of the icon, 0 for height of skin font
+func (ic *Icon) SetIconSize(size float32) {
+	ic.size = size
+	if p := ic.Parent(); p != nil {
+		p.Relayout()
+	}
+}
+
+// Color reports color of the icon, the text color of skin if not set
+func (ic *Icon) Color() Color {
+	if !ic.colored {
+		return skin.Get().Color(skin.RoleText)
+	}
+	return ic.color
+}
+
+// SetColor set color of the icon
+func (ic *Icon) SetColor(c Color) {
+	ic.color, ic.colored = c, true
+	ic.Invalidate(Rect{})
+}
+
+// SizeHint reports a square of IconSize
+func (ic *Icon) SizeHint() SizeHint {
+	if ic.hint != nil {
+		return *ic.hint
+	}
+	s := ic.IconSize()
+	return SizeHint{Pref: Vec2{s, s}}
+}
+
+// Render the icon, in the largest square fits bounds
+func (ic *Icon) Render() {
+	if ic.name != "" {
+		glman.DynDrawIcon(ic.name, ic.bounds, ic.Color())
+	}
+}
@@ -0,0 +1,121 @@
+package gui
+
+import (
+	"image"
+	"tetra/lib/dbg"
+	"tetra/lib/glman"
+)
+
+// ImageFit is how image is placed in bounds of Image widget
+type ImageFit int
+
+// image fits
+const (
+	ImageStretch ImageFit = iota // fills bounds
+	ImageContain                 // scaled to fit in bounds, keeping aspect ratio
+	ImageCenter                  // natural size, centered
+)
+
+// Image is a widget shows picture, stored in pack textures of glman
+type Image struct {
+	Widget
+	src  image.Image
+	img  *glman.Image // uploaded from src when rendered
+	fit  ImageFit
+	tint Color
+}
+
+// Init a new object
+func (im *Image) Init() {
+	im.tint = Color{1, 1, 1, 1}
+}
+
+// Source reports the picture shown
+func (im *Image) Source() image.Image {
+	return im.src
+}
+
+// SetSource set the picture to show, nil for none, texture space of the old one is released
+func (im *Image) SetSource(src image.Image) {
+	if im.img != nil {
+		im.img.Release()
+	}
+	im.src, im.img = src, nil
+	if p := im.Parent(); p != nil {
+		p.Relayout()
+	}
+	im.Invalidate(Rect{})
+}
+
+// Fit reports how the picture is placed in bounds
+func (im *Image) Fit() ImageFit {
+	return im.fit
+}
+
+// SetFit set how the picture is placed in bounds
+func (im *Image) SetFit(fit ImageFit) {
+	im.fit = fit
+	im.Invalidate(Rect{})
+}
+
+// Tint reports color multiplies the picture
+func (im *Image) Tint() Color {
+	return im.tint
+}
+
+// SetTint set color multiplies the picture, opaque white shows it as is
+func (im *Image) SetTint(c Color) {
+	im.tint = c
+	im.Invalidate(Rect{})
+}
+
+// SizeHint reports size of the picture as preferred size, a pixel is an unit
+func (im *Image) SizeHint() SizeHint {
+	if im.hint != nil {
+		return *im.hint
+	}
+	if im.src == nil {
+		return SizeHint{}
+	}
+	b := im.src.Bounds()
+	return SizeHint{Pref: Vec2{float32(b.Dx()), float32(b.Dy())}}
+}
+
+// imageRect returns where the picture of size w, h is drawn in rc
+func imageRect(rc Rect, w, h float32, fit ImageFit) Rect {
+	switch fit {
+	case ImageContain:
+		k := rc.Width() / w
+		if kh := rc.Height() / h; kh < k {
+			k = kh
+		}
+		w, h = w*k, h*k
+	case ImageCenter:
+	default:
+		return rc
+	}
+	x0 := round(rc[0] + (rc.Width()-w)*0.5)
+	y0 := round(rc[1] + (rc.Height()-h)*0.5)
+	return Rect{x0, y0, x0 + w, y0 + h}
+}
+
+// Render the picture, clipped by bounds
+func (im *Image) Render() {
+	if im.src == nil {
+		return
+	}
+	if im.img == nil {
+		img, err := glman.NewImage(im.src)
+		if err != nil {
+			dbg.Logf("Image.Render: %v\n", err)
+			im.src = nil
+			return
+		}
+		im.img = img
+	}
+	w, h := im.img.Size()
+	glman.StackClip2D.Push()
+	glman.StackClip2D.Load(glman.StackClip2D.Peek().Intersect(im.WindowBounds()))
+	glman.DynDrawImage(im.img, imageRect(im.bounds, float32(w), float32(h), im.fit), im.tint)
+	glman.StackClip2D.Pop()
+}
@@ -0,0 +1,154 @@
+package gui
+
+import (
+	"strings"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+)
+
+// alignMask are options of glman.DynDrawText a label can be aligned by
+const alignMask = glman.DtCenter | glman.DtRight | glman.DtVCenter | glman.DtBottom
+
+// Label is a widget shows text, it can be aligned, wrapped, or elided with ellipsis
+type Label struct {
+	Widget
+	text    string
+	fnt     glman.Font // "" for font of skin
+	color   Color
+	colored bool // color is set by SetColor, otherwise text color of skin
+	align   glman.OptionDrawText
+	wrap    bool
+	elide   bool
+}
+
+// Text reports the text of label
+func (l *Label) Text() string {
+	return l.text
+}
+
+// SetText set the text of label, '\n' breaks lines
+func (l *Label) SetText(s string) {
+	if s != l.text {
+		l.text = s
+		l.changed()
+	}
+}
+
+// Font reports font of the text, the font of skin if not set
+func (l *Label) Font() glman.Font {
+	if l.fnt == "" {
+		return skin.Get().Font()
+	}
+	return l.fnt
+}
+
+// SetFont set font of the text, "" for the font of skin
+func (l *Label) SetFont(f glman.Font) {
+	l.fnt = f
+	l.changed()
+}
+
+// Color reports color of the text, the text color of skin if not set
+func (l *Label) Color() Color {
+	if !l.colored {
+		return skin.Get().Color(skin.RoleText)
+	}
+	return l.color
+}
+
+// SetColor set color of the text
+func (l *Label) SetColor(c Color) {
+	l.color, l.colored = c, true
+	l.Invalidate(Rect{})
+}
+
+// Align reports alignment, options of glman.DynDrawText
+func (l *Label) Align() glman.OptionDrawText {
+	return l.align
+}
+
+// SetAlign set alignment by glman.DtCenter, DtRight, DtVCenter and DtBottom, it's top left by default
+func (l *Label) SetAlign(align glman.OptionDrawText) {
+	l.align = align & alignMask
+	l.Invalidate(Rect{})
+}
+
+// Wrap reports whether lines are wrapped at width of label
+func (l *Label) Wrap() bool {
+	return l.wrap
+}
+
+// SetWrap set whether lines are wrapped at width of label
+func (l *Label) SetWrap(wrap bool) {
+	l.wrap = wrap
+	l.changed()
+}
+
+// Elide reports whether lines wider than label end with ellipsis
+func (l *Label) Elide() bool {
+	return l.elide
+}
+
+// SetElide set whether lines wider than label end with ellipsis
+func (l *Label) SetElide(elide bool) {
+	l.elide = elide
+	l.changed()
+}
+
+// options returns options to draw the text
+func (l *Label) options() glman.OptionDrawText {
+	opt := l.align
+	if l.wrap {
+		opt |= glman.DtWordBreak
+	}
+	if l.elide {
+		opt |= glman.DtEndEllipsis
+	}
+	return opt
+}
+
+// changed relayout and repaint after the size of text is changed
+func (l *Label) changed() {
+	if p := l.Parent(); p != nil {
+		p.Relayout()
+	}
+	l.Invalidate(Rect{})
+}
+
+// SizeHint reports size of text as preferred size, wrapped text takes height for current width
+func (l *Label) SizeHint() SizeHint {
+	if l.hint != nil {
+		return *l.hint
+	}
+	fnt := l.Font()
+	var h SizeHint
+	lines := strings.Split(l.text, "\n")
+	for _, s := range lines {
+		if w, _ := glman.MeasureText(s, fnt); w > h.Pref[0] {
+			h.Pref[0] = w
+		}
+	}
+	_, lh := glman.MeasureText("", fnt)
+	h.Min[0] = h.Pref[0]
+	if l.elide {
+		h.Min[0], _ = glman.MeasureText("…", fnt)
+	}
+	if width := l.bounds.Width(); l.wrap && width > 0 {
+		lines = glman.LayoutText(l.text, width, fnt, l.options())
+		h.Min[0] = lh
+	}
+	h.Pref[1] = lh * float32(len(lines))
+	h.Min[1] = h.Pref[1]
+	return h
+}
+
+// Render the label, text out of bounds is clipped
+func (l *Label) Render() {
+	if l.text == "" {
+		return
+	}
+	glman.StackClip2D.Push()
+	glman.StackClip2D.Load(glman.StackClip2D.Peek().Intersect(l.WindowBounds()))
+	glman.DynDrawText(l.text, l.bounds, l.Font(), l.Color(), l.options())
+	glman.StackClip2D.Pop()
+}
@@ -0,0 +1,58 @@
+package gui
+
+import (
+	"tetra/internal/winl"
+	"tetra/lib/passwd"
+)
+
+// LineEdit is single line text editor widget, line breaks in pasted text become spaces.
+// in password mode, text is masked, and it should be stored as hash by Passwd.
+type LineEdit struct {
+	TextEdit
+	onEnter []func(text string)
+}
+
+// Init a new object
+func (le *LineEdit) Init() {
+	le.TextEdit.Init()
+	le.buf.multiline = false
+}
+
+// Password reports whether it's in password mode
+func (le *LineEdit) Password() bool {
+	return le.mask
+}
+
+// SetPassword set password mode, text is shown as '*' and can't be copied
+func (le *LineEdit) SetPassword(b bool) {
+	le.mask = b
+	le.remeasure()
+	le.caretMoved()
+}
+
+// Passwd returns hash of text with a new salt by lib/passwd, to store the password instead of text
+func (le *LineEdit) Passwd() (passwd.Passwd, passwd.Salt) {
+	return passwd.Encrypt(le.Text())
+}
+
+// CheckPasswd reports whether text is the password of encrypted and salt returned by Passwd
+func (le *LineEdit) CheckPasswd(encrypted passwd.Passwd, salt passwd.Salt) bool {
+	return passwd.Validate(le.Text(), encrypted, salt)
+}
+
+// OnEnter adds f to handlers called with text when Enter is pressed
+func (le *LineEdit) OnEnter(f func(text string)) {
+	le.onEnter = append(le.onEnter, f)
+}
+
+// OnKeyDown event handler, Enter calls enter handlers, or bubbles to parent if there is none
+func (le *LineEdit) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	if (key == winl.KeyEnter || key == winl.KeyKPEnter) && le.Enabled() && len(le.onEnter) > 0 {
+		s := le.Text()
+		for _, f := range le.onEnter {
+			f(s)
+		}
+		return true
+	}
+	return le.TextEdit.OnKeyDown(key, mods, repeat)
+}
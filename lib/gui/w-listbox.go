@@ -0,0 +1,264 @@
+package gui
+
+import (
+	"tetra/internal/winl"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+	"time"
+)
+
+// interval between two clicks of double-click
+const doubleClickTime = 400 * time.Millisecond
+
+// ListBox is a widget shows a list of text entries, one of them is selected by mouse or keys.
+// it's put in a ScrollView for long lists, which scrolls to keep the selection in view.
+type ListBox struct {
+	Widget
+	fnt        glman.Font // "" for font of skin
+	items      []string
+	sel        int // selected entry, -1 for none
+	lastClick  time.Time
+	onSelect   []func(i int)
+	onActivate []func(i int)
+}
+
+// Init a new object
+func (lb *ListBox) Init() {
+	lb.sel = -1
+}
+
+// Font returns current font, the font of skin if not set
+func (lb *ListBox) Font() glman.Font {
+	if lb.fnt == "" {
+		return skin.Get().Font()
+	}
+	return lb.fnt
+}
+
+// SetFont set the font, "" for the font of skin
+func (lb *ListBox) SetFont(f glman.Font) {
+	lb.fnt = f
+	lb.changed()
+}
+
+// Items reports entries of the list
+func (lb *ListBox) Items() []string {
+	return lb.items
+}
+
+// SetItems set entries of the list, selection is cleared
+func (lb *ListBox) SetItems(items []string) {
+	lb.items = items
+	lb.sel = -1
+	lb.changed()
+}
+
+// Selected reports index of selected entry, -1 for none
+func (lb *ListBox) Selected() int {
+	return lb.sel
+}
+
+// SetSelected selects entry i and scrolls to it, -1 for none, without calling select handlers
+func (lb *ListBox) SetSelected(i int) {
+	if i < -1 || i >= len(lb.items) {
+		i = -1
+	}
+	lb.sel = i
+	if i >= 0 {
+		if sv, ok := lb.Parent().(IScrollView); ok && sv.Content() == lb.Self {
+			sv.ScrollTo(lb.rowRect(i))
+		}
+	}
+	lb.Invalidate(Rect{})
+}
+
+// OnSelect adds f to handlers called when selection is changed by user
+func (lb *ListBox) OnSelect(f func(i int)) {
+	lb.onSelect = append(lb.onSelect, f)
+}
+
+// OnActivate adds f to handlers called when an entry is double-clicked or Enter is pressed on it
+func (lb *ListBox) OnActivate(f func(i int)) {
+	lb.onActivate = append(lb.onActivate, f)
+}
+
+// changed relayout and redraw after content is changed
+func (lb *ListBox) changed() {
+	if p := lb.Parent(); p != nil {
+		p.Relayout()
+	}
+	lb.Invalidate(Rect{})
+}
+
+// rowHeight reports height of an entry
+func (lb *ListBox) rowHeight() float32 {
+	_, h := glman.MeasureText("M", lb.Font())
+	return h + skin.Get().SizePadding()/2
+}
+
+// rowRect returns rect of entry i in local coordinates
+func (lb *ListBox) rowRect(i int) Rect {
+	h := lb.rowHeight()
+	return Rect{0, h * float32(i), lb.bounds.Width(), h * float32(i+1)}
+}
+
+// pageRows reports entries shown at once, in view of the scroll view containing it
+func (lb *ListBox) pageRows() int {
+	h := lb.bounds.Height()
+	if sv, ok := lb.Parent().(IScrollView); ok {
+		h = sv.Bounds().Height()
+	}
+	if n := int(h / lb.rowHeight()); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// choose selects entry i limited in the list, and calls select handlers if it's changed
+func (lb *ListBox) choose(i int) {
+	if len(lb.items) == 0 {
+		return
+	}
+	if i >= len(lb.items) {
+		i = len(lb.items) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	changed := i != lb.sel
+	lb.Self.(IListBox).SetSelected(i)
+	if changed {
+		for _, f := range lb.onSelect {
+			f(i)
+		}
+	}
+}
+
+// activate calls activate handlers with selected entry
+func (lb *ListBox) activate() {
+	if lb.sel < 0 {
+		return
+	}
+	for _, f := range lb.onActivate {
+		f(lb.sel)
+	}
+}
+
+// Focusable reports whether the widget accepts keyboard focus, a disabled list doesn't
+func (lb *ListBox) Focusable() bool {
+	return lb.Enabled()
+}
+
+// OnFocusIn event handler, redraw to show focus
+func (lb *ListBox) OnFocusIn() {
+	lb.Invalidate(Rect{})
+}
+
+// OnFocusOut event handler, redraw to hide focus
+func (lb *ListBox) OnFocusOut() {
+	lb.Invalidate(Rect{})
+}
+
+// OnKeyDown event handler, arrows, pages, Home and End move selection, Enter activates it
+func (lb *ListBox) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	if !lb.Enabled() || mods&(winl.ModControl|winl.ModAlt) != 0 {
+		return false
+	}
+	switch key {
+	case winl.KeyUp:
+		lb.choose(lb.sel - 1)
+	case winl.KeyDown:
+		lb.choose(lb.sel + 1)
+	case winl.KeyPageUp:
+		lb.choose(lb.sel - lb.pageRows())
+	case winl.KeyPageDown:
+		lb.choose(lb.sel + lb.pageRows())
+	case winl.KeyHome:
+		lb.choose(0)
+	case winl.KeyEnd:
+		lb.choose(len(lb.items) - 1)
+	case winl.KeyEnter, winl.KeyKPEnter:
+		if lb.sel < 0 || len(lb.onActivate) == 0 {
+			return false
+		}
+		if !repeat {
+			lb.activate()
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// OnMouseDown event handler, left button selects the entry, double-click activates it
+func (lb *ListBox) OnMouseDown(btn int, x, y float32) bool {
+	if btn != winl.MouseLeft || !lb.Enabled() {
+		return false
+	}
+	i := int(y / lb.rowHeight())
+	if i < 0 || i >= len(lb.items) {
+		return true
+	}
+	now := time.Now()
+	if i == lb.sel && now.Sub(lb.lastClick) < doubleClickTime {
+		lb.lastClick = time.Time{}
+		lb.activate()
+		return true
+	}
+	lb.lastClick = now
+	lb.choose(i)
+	return true
+}
+
+// SizeHint reports size of all entries as preferred size
+func (lb *ListBox) SizeHint() SizeHint {
+	if lb.hint != nil {
+		return *lb.hint
+	}
+	pad := skin.Get().SizePadding()
+	var w float32
+	for _, s := range lb.items {
+		if tw, _ := glman.MeasureText(s, lb.Font()); tw > w {
+			w = tw
+		}
+	}
+	sz := Vec2{w + pad, lb.rowHeight() * float32(len(lb.items))}
+	return SizeHint{Pref: sz}
+}
+
+// Render the element, only entries in clip rect are drawn
+func (lb *ListBox) Render() {
+	sk := skin.Get()
+	pad := sk.SizePadding()
+	fnt := lb.Font()
+	rc := lb.bounds
+	glman.DynFillRect(rc, sk.Color(skin.RoleBase))
+
+	var st skin.State
+	if !lb.Enabled() {
+		st = skin.StateDisabled
+	}
+	text := sk.TextColor(st)
+	hl, hlText := sk.Color(skin.RoleHighlight), sk.Color(skin.RoleHighlightText)
+
+	glman.StackClip2D.Push()
+	clip := glman.StackClip2D.Peek().Intersect(lb.WindowBounds())
+	glman.StackClip2D.Load(clip)
+	wb := lb.WindowBounds()
+	h := lb.rowHeight()
+	first := int((clip[1] - wb[1]) / h)
+	if first < 0 {
+		first = 0
+	}
+	for i := first; i < len(lb.items) && wb[1]+h*float32(i) < clip[3]; i++ {
+		y := rc[1] + h*float32(i)
+		row := Rect{rc[0], y, rc[2], y + h}
+		c := text
+		if i == lb.sel {
+			glman.DynFillRect(row, hl)
+			c = hlText
+		}
+		glman.DynDrawText(lb.items[i], inset(row, pad/2, pad/4), fnt, c, glman.DtSingleLine)
+	}
+	glman.StackClip2D.Pop()
+}
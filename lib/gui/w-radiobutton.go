@@ -0,0 +1,44 @@
+package gui
+
+import "tetra/lib/skin"
+
+// RadioButton is a checkable button drawn as a circle followed by text,
+// radio buttons of the same parent are exclusive, checking one unchecks others.
+type RadioButton struct {
+	Button
+}
+
+// Init a new object
+func (rb *RadioButton) Init() {
+	rb.Button.Init()
+	rb.checkable = true
+}
+
+// SetChecked set checked state, other radio buttons of the same parent are unchecked if b
+func (rb *RadioButton) SetChecked(b bool) {
+	rb.Button.SetChecked(b)
+	p := rb.Parent()
+	if !b || p == nil {
+		return
+	}
+	for _, c := range p.Children() {
+		if x, ok := c.(IRadioButton); ok && x != rb.Self {
+			x.SetChecked(false)
+		}
+	}
+}
+
+// Toggle checks the radio button, clicking a checked one doesn't uncheck it
+func (rb *RadioButton) Toggle() {
+	rb.Self.(IButton).SetChecked(true)
+}
+
+// SizeHint reports size of circle and text
+func (rb *RadioButton) SizeHint() SizeHint {
+	return rb.indicatorHint()
+}
+
+// Render the element
+func (rb *RadioButton) Render() {
+	rb.renderIndicator(skin.Get().DrawRadio)
+}
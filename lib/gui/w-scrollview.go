@@ -0,0 +1,462 @@
+package gui
+
+import (
+	"math"
+	"tetra/internal/winl"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+	"time"
+)
+
+const (
+	// scrollSpeed is rate of smooth scrolling, offset moves by 1-e^(-scrollSpeed*dt) of the rest distance in dt seconds
+	scrollSpeed = 15
+	// scrollFling is seconds content keeps moving at release speed of dragging
+	scrollFling = 0.3
+	// scrollWheelLines is lines of text scrolled by a notch of mouse wheel
+	scrollWheelLines = 3
+)
+
+// parts of ScrollView under mouse or dragged
+const (
+	scrollNone    = iota
+	scrollBarH    // horizontal scroll bar
+	scrollBarV    // vertical scroll bar
+	scrollContent // content dragged by mouse
+)
+
+// ScrollView is a container shows content larger than its bounds, scrolled by scroll bars,
+// mouse wheel of both axes, and dragging content by mouse. children out of view are not rendered.
+type ScrollView struct {
+	Widget
+	content IElem
+	offset  Vec2    // scroll position, top left of content shown at top left of view
+	target  Vec2    // where smooth scrolling goes
+	size    Vec2    // size of content
+	view    Rect    // area showing content, in local coordinates, without scroll bars
+	bars    [2]bool // horizontal and vertical bars are shown
+
+	ticker   *winl.Ticker // of smooth scrolling
+	lastTick time.Time
+
+	hot      int  // part under mouse
+	drag     int  // part dragged
+	grab     Vec2 // mouse position at the start of dragging, relative to thumb for scroll bars
+	grabOff  Vec2 // offset at the start of dragging
+	velocity Vec2 // of dragging content, in units per second
+	lastMove time.Time
+}
+
+// scrollLayout arranges content of ScrollView by its size hint and scroll offset
+type scrollLayout struct {
+	sv *ScrollView
+}
+
+// SizeHint reports size range of scroll view, it can be small as scroll bars
+func (l scrollLayout) SizeHint(el IElem) SizeHint {
+	bar := skin.Get().SizeScrollBar()
+	h := SizeHint{Min: Vec2{bar * 3, bar * 3}}
+	if l.sv.content != nil {
+		h.Pref = outerHint(l.sv.content).Pref
+	}
+	return h
+}
+
+// Arrange places content in view
+func (l scrollLayout) Arrange(el IElem) {
+	l.sv.arrange()
+}
+
+// Init a new object
+func (sv *ScrollView) Init() {
+	sv.layout = scrollLayout{sv}
+}
+
+// Content reports the element scrolled
+func (sv *ScrollView) Content() IElem {
+	return sv.content
+}
+
+// SetContent set the element scrolled, it's sized by its size hint, and fills the view if it's smaller
+func (sv *ScrollView) SetContent(el IElem) {
+	sv.RemoveAll()
+	sv.content = el
+	sv.offset, sv.target = Vec2{}, Vec2{}
+	if el != nil {
+		sv.Insert(-1, el)
+	}
+}
+
+// ScrollPos reports scroll position, the point of content shown at top left of view
+func (sv *ScrollView) ScrollPos() Vec2 {
+	return sv.offset
+}
+
+// SetScrollPos scrolls to v immediately, it's limited in content
+func (sv *ScrollView) SetScrollPos(v Vec2) {
+	sv.stopAnimation()
+	sv.setOffset(v)
+	sv.target = sv.offset
+}
+
+// ScrollTo scrolls the least to show rc of content, top left of rc is shown if it's larger than view
+func (sv *ScrollView) ScrollTo(rc Rect) {
+	v := sv.target
+	for i := 0; i < 2; i++ {
+		size := sv.view[i+2] - sv.view[i]
+		if rc[i+2] > v[i]+size {
+			v[i] = rc[i+2] - size
+		}
+		if rc[i] < v[i] {
+			v[i] = rc[i]
+		}
+	}
+	sv.SetScrollPos(v)
+}
+
+// scrollIntoView scrolls every ScrollView containing el to show it
+func scrollIntoView(el IElem) {
+	for p := el.Parent(); p != nil; p = p.Parent() {
+		sv, ok := p.(IScrollView)
+		if !ok || sv.Content() == nil {
+			continue
+		}
+		rc, cb := el.WindowBounds(), sv.Content().WindowBounds()
+		sv.ScrollTo(Rect{rc[0] - cb[0], rc[1] - cb[1], rc[2] - cb[0], rc[3] - cb[1]})
+	}
+}
+
+// maxOffset reports the largest scroll position
+func (sv *ScrollView) maxOffset() (v Vec2) {
+	for i := 0; i < 2; i++ {
+		if v[i] = sv.size[i] - (sv.view[i+2] - sv.view[i]); v[i] < 0 {
+			v[i] = 0
+		}
+	}
+	return
+}
+
+// clampOffset limits v in content
+func (sv *ScrollView) clampOffset(v Vec2) Vec2 {
+	max := sv.maxOffset()
+	for i := 0; i < 2; i++ {
+		if v[i] > max[i] {
+			v[i] = max[i]
+		}
+		if v[i] < 0 {
+			v[i] = 0
+		}
+	}
+	return v
+}
+
+// setOffset moves content to scroll position v, target of smooth scrolling is kept
+func (sv *ScrollView) setOffset(v Vec2) {
+	v = sv.clampOffset(v)
+	if v == sv.offset {
+		return
+	}
+	sv.offset = v
+	sv.placeContent()
+	sv.Invalidate(Rect{})
+}
+
+// arrange sizes content and view, scroll bars are shown on axes content doesn't fit
+func (sv *ScrollView) arrange() {
+	bar := skin.Get().SizeScrollBar()
+	w, h := sv.bounds.Width(), sv.bounds.Height()
+	var hint SizeHint
+	if sv.content != nil {
+		hint = outerHint(sv.content)
+	}
+	// vertical bar takes width, then horizontal bar may be needed, which takes height
+	sv.bars = [2]bool{}
+	sv.bars[1] = hint.Pref[1] > h
+	if sv.bars[1] {
+		w -= bar
+	}
+	sv.bars[0] = hint.Pref[0] > w
+	if sv.bars[0] {
+		h -= bar
+		if !sv.bars[1] && hint.Pref[1] > h {
+			sv.bars[1] = true
+			w -= bar
+		}
+	}
+	sv.view = Rect{0, 0, w, h}
+	for i, view := range [2]float32{w, h} {
+		sv.size[i] = hint.Pref[i]
+		if sv.size[i] < view {
+			sv.size[i] = hint.clamp(i, view)
+		}
+	}
+	sv.offset = sv.clampOffset(sv.offset)
+	sv.target = sv.clampOffset(sv.target)
+	sv.placeContent()
+}
+
+// placeContent set bounds of content by scroll position
+func (sv *ScrollView) placeContent() {
+	if sv.content != nil {
+		x, y := sv.view[0]-sv.offset[0], sv.view[1]-sv.offset[1]
+		place(sv.content, Rect{x, y, x + sv.size[0], y + sv.size[1]})
+	}
+}
+
+// barRects returns track and thumb of scroll bar of axis i, 0 for horizontal, in local coordinates
+func (sv *ScrollView) barRects(i int) (track, thumb Rect) {
+	bar := skin.Get().SizeScrollBar()
+	if i == 0 {
+		track = Rect{sv.view[0], sv.view[3], sv.view[2], sv.view[3] + bar}
+	} else {
+		track = Rect{sv.view[2], sv.view[1], sv.view[2] + bar, sv.view[3]}
+	}
+	length := track[i+2] - track[i]
+	view := sv.view[i+2] - sv.view[i]
+	size := length
+	if sv.size[i] > 0 {
+		size = length * view / sv.size[i]
+	}
+	if size < bar*2 {
+		size = bar * 2
+	}
+	if size > length {
+		size = length
+	}
+	pos := track[i]
+	if max := sv.maxOffset()[i]; max > 0 {
+		pos += (length - size) * sv.offset[i] / max
+	}
+	thumb = track
+	thumb[i], thumb[i+2] = pos, pos+size
+	return
+}
+
+// partAt reports part of scroll view at x, y in local coordinates
+func (sv *ScrollView) partAt(x, y float32) int {
+	for i, part := range [2]int{scrollBarH, scrollBarV} {
+		if !sv.bars[i] {
+			continue
+		}
+		if track, _ := sv.barRects(i); track.Contains(x, y) {
+			return part
+		}
+	}
+	if sv.view.Contains(x, y) {
+		return scrollContent
+	}
+	return scrollNone
+}
+
+// HitTest returns the deepest element contains x, y in coordinates of parent, scroll bars and area out of view belong to the scroll view
+func (sv *ScrollView) HitTest(x, y float32) IElem {
+	if !sv.bounds.Contains(x, y) {
+		return nil
+	}
+	if sv.partAt(x-sv.bounds[0], y-sv.bounds[1]) != scrollContent {
+		return sv.Self.(IElem)
+	}
+	return sv.Elem.HitTest(x, y)
+}
+
+// scrollBy moves target of smooth scrolling by d, returns false if it can't be moved
+func (sv *ScrollView) scrollBy(d Vec2) bool {
+	v := sv.clampOffset(Vec2{sv.target[0] + d[0], sv.target[1] + d[1]})
+	if v == sv.target {
+		return false
+	}
+	sv.target = v
+	sv.startAnimation()
+	return true
+}
+
+// startAnimation starts smooth scrolling to target
+func (sv *ScrollView) startAnimation() {
+	if sv.ticker == nil {
+		sv.lastTick = time.Now()
+		sv.ticker = winl.NewTicker(time.Second/60, sv.tick)
+	}
+}
+
+// stopAnimation stops smooth scrolling
+func (sv *ScrollView) stopAnimation() {
+	if sv.ticker != nil {
+		sv.ticker.Stop()
+		sv.ticker = nil
+	}
+}
+
+// SetWindow set the owner window, smooth scrolling stops when it's detached
+func (sv *ScrollView) SetWindow(w IWindow) {
+	if w == nil {
+		sv.stopAnimation()
+	}
+	sv.Elem.SetWindow(w)
+}
+
+// tick moves offset every frame of smooth scrolling
+func (sv *ScrollView) tick() {
+	now := time.Now()
+	dt := float32(now.Sub(sv.lastTick).Seconds())
+	sv.lastTick = now
+	if sv.Window() == nil || !sv.animate(dt) {
+		sv.stopAnimation()
+	}
+}
+
+// animate moves offset toward target by dt seconds, returns false when target is reached
+func (sv *ScrollView) animate(dt float32) bool {
+	k := 1 - float32(math.Exp(-float64(dt*scrollSpeed)))
+	v, moving := sv.offset, false
+	for i := 0; i < 2; i++ {
+		if d := sv.target[i] - v[i]; d > 0.5 || d < -0.5 {
+			v[i] += d * k
+			moving = true
+		} else {
+			v[i] = sv.target[i]
+		}
+	}
+	sv.setOffset(v)
+	return moving
+}
+
+// OnMouseWheel event handler, scrolls smoothly, Shift scrolls vertical wheel horizontally, bubbles to parent if it can't scroll
+func (sv *ScrollView) OnMouseWheel(vert bool, dz float32) bool {
+	axis := 0
+	if w := sv.Window(); vert && (w == nil || w.Mods()&winl.ModShift == 0) {
+		axis = 1
+	}
+	_, lh := glman.MeasureText("", skin.Get().Font())
+	var d Vec2
+	d[axis] = -dz * lh * scrollWheelLines
+	return sv.scrollBy(d)
+}
+
+// OnMouseDown event handler, drags thumb of scroll bar, pages by clicking track, or drags content
+func (sv *ScrollView) OnMouseDown(btn int, x, y float32) bool {
+	if btn != winl.MouseLeft && btn != winl.MouseMiddle {
+		return false
+	}
+	part := sv.partAt(x, y)
+	sv.grab, sv.grabOff = Vec2{x, y}, sv.offset
+	switch part {
+	case scrollBarH, scrollBarV:
+		i := part - scrollBarH
+		_, thumb := sv.barRects(i)
+		if !thumb.Contains(x, y) {
+			// page toward mouse
+			var d Vec2
+			d[i] = sv.view[i+2] - sv.view[i]
+			if sv.grab[i] < thumb[i] {
+				d[i] = -d[i]
+			}
+			sv.scrollBy(d)
+			return true
+		}
+		sv.grab[i] -= thumb[i]
+	case scrollContent:
+		sv.velocity, sv.lastMove = Vec2{}, time.Now()
+	default:
+		return false
+	}
+	sv.stopAnimation()
+	sv.target = sv.offset
+	sv.drag = part
+	sv.Invalidate(Rect{})
+	return true
+}
+
+// OnMouseMove event handler, moves dragged thumb or content
+func (sv *ScrollView) OnMouseMove(x, y float32) bool {
+	switch sv.drag {
+	case scrollBarH, scrollBarV:
+		i := sv.drag - scrollBarH
+		track, thumb := sv.barRects(i)
+		room := (track[i+2] - track[i]) - (thumb[i+2] - thumb[i])
+		v := sv.offset
+		if room > 0 {
+			pos := Vec2{x, y}[i] - sv.grab[i] - track[i]
+			v[i] = pos / room * sv.maxOffset()[i]
+		}
+		sv.SetScrollPos(v)
+	case scrollContent:
+		v := Vec2{sv.grabOff[0] - (x - sv.grab[0]), sv.grabOff[1] - (y - sv.grab[1])}
+		now := time.Now()
+		if dt := float32(now.Sub(sv.lastMove).Seconds()); dt > 0 {
+			for i := 0; i < 2; i++ {
+				sv.velocity[i] = sv.velocity[i]*0.2 + (v[i]-sv.offset[i])/dt*0.8
+			}
+		}
+		sv.lastMove = now
+		sv.SetScrollPos(v)
+	default:
+		if hot := sv.partAt(x, y); hot != sv.hot {
+			sv.hot = hot
+			sv.Invalidate(Rect{})
+		}
+		return false
+	}
+	return true
+}
+
+// OnMouseUp event handler, released content keeps moving at speed of dragging
+func (sv *ScrollView) OnMouseUp(btn int, x, y float32) bool {
+	if sv.drag == scrollNone {
+		return false
+	}
+	if sv.drag == scrollContent && time.Since(sv.lastMove) < 100*time.Millisecond {
+		sv.scrollBy(Vec2{sv.velocity[0] * scrollFling, sv.velocity[1] * scrollFling})
+	}
+	sv.drag = scrollNone
+	sv.Invalidate(Rect{})
+	return true
+}
+
+// OnMouseLeave event handler
+func (sv *ScrollView) OnMouseLeave() {
+	if sv.hot != scrollNone {
+		sv.hot = scrollNone
+		sv.Invalidate(Rect{})
+	}
+}
+
+// Render the element, content is clipped by view and its children out of view are skipped, then scroll bars are drawn
+func (sv *ScrollView) Render() {
+	wb := sv.WindowBounds()
+	view := Rect{wb[0] + sv.view[0], wb[1] + sv.view[1], wb[0] + sv.view[2], wb[1] + sv.view[3]}
+	if !renderView.IsEmpty() {
+		// nested in another ScrollView
+		view = view.Intersect(renderView)
+	}
+	if !view.IsEmpty() {
+		saved := renderView
+		renderView = view
+		glman.StackClip2D.Push()
+		glman.StackClip2D.Load(glman.StackClip2D.Peek().Intersect(view))
+		sv.Elem.Render()
+		glman.StackClip2D.Pop()
+		renderView = saved
+	}
+
+	sk := skin.Get()
+	ox, oy := sv.bounds[0], sv.bounds[1]
+	for i, part := range [2]int{scrollBarH, scrollBarV} {
+		if !sv.bars[i] {
+			continue
+		}
+		var st skin.State
+		if sv.drag == part {
+			st |= skin.StatePressed
+		}
+		if sv.hot == part {
+			st |= skin.StateHover
+		}
+		track, thumb := sv.barRects(i)
+		sk.DrawScrollBar(Rect{track[0] + ox, track[1] + oy, track[2] + ox, track[3] + oy},
+			Rect{thumb[0] + ox, thumb[1] + oy, thumb[2] + ox, thumb[3] + oy}, st)
+	}
+	if sv.bars[0] && sv.bars[1] {
+		// corner between bars
+		glman.DynFillRect(Rect{sv.view[2] + ox, sv.view[3] + oy, sv.bounds[2], sv.bounds[3]}, sk.Color(skin.RoleWindow))
+	}
+}
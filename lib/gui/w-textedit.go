@@ -0,0 +1,675 @@
+package gui
+
+import (
+	"strings"
+	"tetra/internal/winl"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+	"time"
+)
+
+// TextEdit is multi-line text editor widget, with selection, clipboard, undo and redo.
+// text area scrolls to keep the caret visible, and by mouse wheel.
+type TextEdit struct {
+	Widget
+	buf         editBuf
+	fnt         glman.Font // "" for font of skin
+	placeholder string     // shown when text is empty
+	mask        bool       // text is shown as '*', and can't be copied
+	scroll      Vec2       // offset of text shown at top left of text area
+	widths      []float32  // width of lines, -1 to measure again
+	width       float32    // width of the widest line, -1 to find again
+	goalX       float32    // x of caret kept by moving up and down, -1 to take it from caret
+	dragging    bool       // selecting by mouse
+	lastClick   time.Time
+	onChange    []func(text string)
+}
+
+// Init a new object
+func (te *TextEdit) Init() {
+	te.buf.multiline = true
+	te.buf.changed = te.linesChanged
+	te.goalX, te.width = -1, -1
+	te.buf.setText("")
+}
+
+// Text reports the text
+func (te *TextEdit) Text() string {
+	return te.buf.String()
+}
+
+// SetText replaces the text, caret is moved to the end, undo history is cleared
+func (te *TextEdit) SetText(s string) {
+	te.buf.setText(s)
+	te.scroll = Vec2{}
+	te.textChanged()
+}
+
+// Font reports font of the text, the font of skin if not set
+func (te *TextEdit) Font() glman.Font {
+	if te.fnt == "" {
+		return skin.Get().Font()
+	}
+	return te.fnt
+}
+
+// SetFont set font of the text, "" for the font of skin
+func (te *TextEdit) SetFont(f glman.Font) {
+	te.fnt = f
+	te.remeasure()
+	if p := te.Parent(); p != nil {
+		p.Relayout()
+	}
+	te.caretMoved()
+}
+
+// Placeholder reports text shown when it's empty
+func (te *TextEdit) Placeholder() string {
+	return te.placeholder
+}
+
+// SetPlaceholder set text shown when it's empty, as a hint of what to input
+func (te *TextEdit) SetPlaceholder(s string) {
+	te.placeholder = s
+	te.Invalidate(Rect{})
+}
+
+// SetValidator set f to check text, edits by user making text rejected by f are discarded, nil for no check
+func (te *TextEdit) SetValidator(f func(text string) bool) {
+	te.buf.valid = f
+}
+
+// OnChange adds f to handlers called after text is changed
+func (te *TextEdit) OnChange(f func(text string)) {
+	te.onChange = append(te.onChange, f)
+}
+
+// Selection reports start and end of selected text, in runes, they are equal to caret if nothing is selected
+func (te *TextEdit) Selection() (start, end int) {
+	return te.buf.selection()
+}
+
+// SetSelection selects runes from start to end, caret is at end
+func (te *TextEdit) SetSelection(start, end int) {
+	te.buf.setCaret(start, false)
+	te.buf.setCaret(end, true)
+	te.caretMoved()
+}
+
+// SelectedText returns the selected text
+func (te *TextEdit) SelectedText() string {
+	return te.buf.selectedText()
+}
+
+// SelectAll selects the whole text
+func (te *TextEdit) SelectAll() {
+	te.SetSelection(0, len(te.buf.text))
+}
+
+// ReplaceSelection replaces the selection by s as typed by user, returns false if it's rejected by validator
+func (te *TextEdit) ReplaceSelection(s string) bool {
+	return te.edit(te.buf.insert(s, editOther))
+}
+
+// Copy puts selected text to clipboard, masked text can't be copied
+func (te *TextEdit) Copy() {
+	if s := te.buf.selectedText(); s != "" && !te.mask {
+		winl.SetClipboardText(s)
+	}
+}
+
+// Cut moves selected text to clipboard, masked text can't be cut
+func (te *TextEdit) Cut() {
+	if te.mask {
+		return
+	}
+	te.Copy()
+	te.edit(te.buf.deleteTo(te.buf.caret))
+}
+
+// Paste replaces selection by text in clipboard
+func (te *TextEdit) Paste() {
+	if s := winl.ClipboardText(); s != "" {
+		te.ReplaceSelection(s)
+	}
+}
+
+// CanUndo reports whether there are edits to undo
+func (te *TextEdit) CanUndo() bool {
+	return len(te.buf.undo) > 0
+}
+
+// Undo the last edit
+func (te *TextEdit) Undo() {
+	te.edit(te.buf.undoEdit())
+}
+
+// CanRedo reports whether there are undone edits to redo
+func (te *TextEdit) CanRedo() bool {
+	return len(te.buf.redo) > 0
+}
+
+// Redo the last undone edit
+func (te *TextEdit) Redo() {
+	te.edit(te.buf.redoEdit())
+}
+
+// edit notifies change of text if changed, returns changed
+func (te *TextEdit) edit(changed bool) bool {
+	if changed {
+		te.textChanged()
+	}
+	return changed
+}
+
+// textChanged calls change handlers and redraw
+func (te *TextEdit) textChanged() {
+	te.caretMoved()
+	if len(te.onChange) == 0 {
+		return
+	}
+	s := te.buf.String()
+	for _, f := range te.onChange {
+		f(s)
+	}
+}
+
+// linesChanged updates widths after lines from l are replaced, removed lines by added ones.
+// only added lines are measured, or none if all lines are to be measured by contentSize.
+func (te *TextEdit) linesChanged(l, removed, added int) {
+	for _, w := range te.widths[l : l+removed] {
+		if w >= te.width {
+			// the widest line may be removed
+			te.width = -1
+		}
+	}
+	ws := make([]float32, added)
+	fnt := te.Font()
+	for i := range ws {
+		ws[i] = -1
+		if te.width >= 0 {
+			ws[i], _ = glman.MeasureText(te.lineText(l+i), fnt)
+			if ws[i] > te.width {
+				te.width = ws[i]
+			}
+		}
+	}
+	n, k := len(te.widths), added-removed
+	if k > 0 {
+		te.widths = append(te.widths, ws[:k]...)
+	}
+	copy(te.widths[l+added:], te.widths[l+removed:n])
+	copy(te.widths[l:], ws)
+	te.widths = te.widths[:n+k]
+}
+
+// remeasure measures all lines again when they are shown differently, i.e. font is changed
+func (te *TextEdit) remeasure() {
+	for i := range te.widths {
+		te.widths[i] = -1
+	}
+	te.width = -1
+}
+
+// caretMoved scrolls to caret and redraw
+func (te *TextEdit) caretMoved() {
+	te.goalX = -1
+	te.ensureVisible()
+	te.updateInputRect()
+	te.Invalidate(Rect{})
+}
+
+// updateInputRect places candidate window of input method at caret, if it has focus
+func (te *TextEdit) updateInputRect() {
+	w := te.Window()
+	if w == nil || !te.HasFocus() {
+		return
+	}
+	x, y := te.caretPos(te.buf.caret)
+	wb := te.WindowBounds()
+	s := w.ContentScale()
+	w.SetTextInputRect((wb[0]+x)*s, (wb[1]+y)*s, s, te.lineHeight()*s)
+}
+
+// lineHeight reports height of a line of text
+func (te *TextEdit) lineHeight() float32 {
+	_, h := glman.MeasureText("", te.Font())
+	return h
+}
+
+// textRect returns area of text in local coordinates, inside padding
+func (te *TextEdit) textRect() Rect {
+	pad := skin.Get().SizePadding() / 2
+	return Rect{pad, pad, te.bounds.Width() - pad, te.bounds.Height() - pad}
+}
+
+// lineText returns text of line l as shown, masked ones are shown as '*'
+func (te *TextEdit) lineText(l int) string {
+	start, end := te.buf.line(l)
+	if te.mask {
+		return strings.Repeat("*", end-start)
+	}
+	return string(te.buf.text[start:end])
+}
+
+// lineY returns y of top of line l in local coordinates
+func (te *TextEdit) lineY(l int) float32 {
+	tr := te.textRect()
+	lh := te.lineHeight()
+	if !te.buf.multiline {
+		// single line is centered vertically
+		return round(tr[1] + (tr.Height()-lh)*0.5)
+	}
+	return tr[1] + lh*float32(l) - te.scroll[1]
+}
+
+// caretPos returns position of top of caret before rune i, in local coordinates
+func (te *TextEdit) caretPos(i int) (x, y float32) {
+	l := te.buf.lineOf(i)
+	start, _ := te.buf.line(l)
+	offs := glman.TextOffsets(te.lineText(l), te.Font())
+	return te.textRect()[0] + offs[i-start] - te.scroll[0], te.lineY(l)
+}
+
+// posAt returns index of rune the caret is placed before by clicking at x, y in local coordinates
+func (te *TextEdit) posAt(x, y float32) int {
+	l := 0
+	if te.buf.multiline {
+		l = int((y - te.lineY(0)) / te.lineHeight())
+		if l < 0 {
+			l = 0
+		}
+		if l >= len(te.buf.lines) {
+			l = len(te.buf.lines) - 1
+		}
+	}
+	start, _ := te.buf.line(l)
+	return start + nearestOffset(glman.TextOffsets(te.lineText(l), te.Font()), x-te.textRect()[0]+te.scroll[0])
+}
+
+// nearestOffset returns index of offs nearest to x
+func nearestOffset(offs []float32, x float32) int {
+	for i := 1; i < len(offs); i++ {
+		if x < (offs[i-1]+offs[i])*0.5 {
+			return i - 1
+		}
+	}
+	return len(offs) - 1
+}
+
+// contentSize reports size of the whole text
+func (te *TextEdit) contentSize() (w, h float32) {
+	if te.width < 0 {
+		fnt := te.Font()
+		te.width = 0
+		for l, lw := range te.widths {
+			if lw < 0 {
+				lw, _ = glman.MeasureText(te.lineText(l), fnt)
+				te.widths[l] = lw
+			}
+			if lw > te.width {
+				te.width = lw
+			}
+		}
+	}
+	return te.width, te.lineHeight() * float32(len(te.buf.lines))
+}
+
+// setScroll scrolls text to offset v, limited in content
+func (te *TextEdit) setScroll(v Vec2) {
+	tr := te.textRect()
+	w, h := te.contentSize()
+	limit := Vec2{w - tr.Width(), h - tr.Height()}
+	if !te.buf.multiline {
+		limit[1] = 0
+	}
+	for i := range v {
+		if v[i] > limit[i] {
+			v[i] = limit[i]
+		}
+		if v[i] < 0 {
+			v[i] = 0
+		}
+	}
+	if v != te.scroll {
+		te.scroll = v
+		te.updateInputRect()
+		te.Invalidate(Rect{})
+	}
+}
+
+// ensureVisible scrolls to make caret visible
+func (te *TextEdit) ensureVisible() {
+	tr := te.textRect()
+	if tr.Width() <= 0 || tr.Height() <= 0 {
+		return
+	}
+	x, y := te.caretPos(te.buf.caret)
+	v := te.scroll
+	if x < tr[0] {
+		v[0] -= tr[0] - x
+	} else if x+1 > tr[2] {
+		v[0] += x + 1 - tr[2]
+	}
+	if te.buf.multiline {
+		if lh := te.lineHeight(); y < tr[1] {
+			v[1] -= tr[1] - y
+		} else if y+lh > tr[3] {
+			v[1] += y + lh - tr[3]
+		}
+	}
+	te.setScroll(v)
+}
+
+// moveCaret moves caret to i, extends selection if extend, and scrolls to it
+func (te *TextEdit) moveCaret(i int, extend bool) {
+	te.buf.setCaret(i, extend)
+	te.caretMoved()
+}
+
+// moveLines moves caret by n lines up or down, keeping its x
+func (te *TextEdit) moveLines(n int, extend bool) {
+	x, _ := te.caretPos(te.buf.caret)
+	if te.goalX >= 0 {
+		x = te.goalX
+	}
+	l := te.buf.lineOf(te.buf.caret) + n
+	var i int
+	switch {
+	case l < 0:
+		i = 0
+	case l >= len(te.buf.lines):
+		i = len(te.buf.text)
+	default:
+		i = te.posAt(x, te.lineY(l))
+	}
+	te.moveCaret(i, extend)
+	te.goalX = x
+}
+
+// state reports state of the editor for skin drawing
+func (te *TextEdit) state() (st skin.State) {
+	if !te.Enabled() {
+		return skin.StateDisabled
+	}
+	if te.HasFocus() {
+		st |= skin.StateFocus
+	}
+	return
+}
+
+// Focusable reports whether the widget accepts keyboard focus, a disabled editor doesn't
+func (te *TextEdit) Focusable() bool {
+	return te.Enabled()
+}
+
+// OnFocusIn event handler, redraw to show caret, and input method shows candidates at it
+func (te *TextEdit) OnFocusIn() {
+	te.updateInputRect()
+	te.Invalidate(Rect{})
+}
+
+// OnFocusOut event handler, redraw to hide caret
+func (te *TextEdit) OnFocusOut() {
+	te.dragging = false
+	te.Invalidate(Rect{})
+}
+
+// OnMouseDown event handler, left button places caret, double click selects word, middle button pastes primary selection
+func (te *TextEdit) OnMouseDown(btn int, x, y float32) bool {
+	if !te.Enabled() {
+		return false
+	}
+	i := te.posAt(x, y)
+	switch btn {
+	case winl.MouseLeft:
+		now := time.Now()
+		if now.Sub(te.lastClick) < doubleClickTime && !te.mask {
+			te.lastClick = time.Time{}
+			start, end := te.buf.wordAt(i)
+			te.SetSelection(start, end)
+			return true
+		}
+		te.lastClick = now
+		te.dragging = true
+		te.moveCaret(i, te.shiftDown())
+	case winl.MouseMiddle:
+		if s := winl.PrimarySelection(); s != "" {
+			te.buf.setCaret(i, false)
+			te.ReplaceSelection(s)
+		}
+	}
+	return true
+}
+
+// shiftDown reports whether Shift is pressed, click with it extends selection
+func (te *TextEdit) shiftDown() bool {
+	w := te.Window()
+	return w != nil && w.Mods()&winl.ModShift != 0
+}
+
+// OnMouseMove event handler, extends selection while dragging
+func (te *TextEdit) OnMouseMove(x, y float32) bool {
+	if !te.dragging {
+		return false
+	}
+	te.moveCaret(te.posAt(x, y), true)
+	return true
+}
+
+// OnMouseUp event handler, selected text becomes primary selection
+func (te *TextEdit) OnMouseUp(btn int, x, y float32) bool {
+	if btn != winl.MouseLeft || !te.dragging {
+		return false
+	}
+	te.dragging = false
+	if s := te.buf.selectedText(); s != "" && !te.mask {
+		winl.SetPrimarySelection(s)
+	}
+	return true
+}
+
+// OnMouseWheel event handler, scrolls text by 3 lines a notch
+func (te *TextEdit) OnMouseWheel(vert bool, dz float32) bool {
+	d := dz * te.lineHeight() * scrollWheelLines
+	if vert && te.buf.multiline {
+		te.setScroll(Vec2{te.scroll[0], te.scroll[1] - d})
+	} else if !vert {
+		te.setScroll(Vec2{te.scroll[0] - d, te.scroll[1]})
+	} else {
+		return false
+	}
+	return true
+}
+
+// OnKeyDown event handler, moves caret by words with Ctrl, extends selection with Shift, edits and shortcuts of clipboard and undo
+func (te *TextEdit) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	if !te.Enabled() || mods&winl.ModAlt != 0 {
+		return false
+	}
+	ctrl := mods&(winl.ModControl|winl.ModSuper) != 0
+	shift := mods&winl.ModShift != 0
+	b := &te.buf
+	start, end := b.selection()
+	// masked text doesn't reveal words
+	word := ctrl && !te.mask
+	switch key {
+	case winl.KeyLeft:
+		switch {
+		case start != end && !shift:
+			te.moveCaret(start, false)
+		case word:
+			te.moveCaret(b.wordLeft(b.caret), shift)
+		default:
+			te.moveCaret(b.caret-1, shift)
+		}
+	case winl.KeyRight:
+		switch {
+		case start != end && !shift:
+			te.moveCaret(end, false)
+		case word:
+			te.moveCaret(b.wordRight(b.caret), shift)
+		default:
+			te.moveCaret(b.caret+1, shift)
+		}
+	case winl.KeyUp, winl.KeyDown, winl.KeyPageUp, winl.KeyPageDown:
+		if !b.multiline {
+			return false
+		}
+		n := 1
+		if key == winl.KeyPageUp || key == winl.KeyPageDown {
+			if n = int(te.textRect().Height() / te.lineHeight()); n < 1 {
+				n = 1
+			}
+		}
+		if key == winl.KeyUp || key == winl.KeyPageUp {
+			n = -n
+		}
+		te.moveLines(n, shift)
+	case winl.KeyHome:
+		if ctrl {
+			te.moveCaret(0, shift)
+		} else {
+			s, _ := b.line(b.lineOf(b.caret))
+			te.moveCaret(s, shift)
+		}
+	case winl.KeyEnd:
+		if ctrl {
+			te.moveCaret(len(b.text), shift)
+		} else {
+			_, e := b.line(b.lineOf(b.caret))
+			te.moveCaret(e, shift)
+		}
+	case winl.KeyBackspace:
+		i := b.caret - 1
+		if word {
+			i = b.wordLeft(b.caret)
+		}
+		te.edit(b.deleteTo(i))
+	case winl.KeyDelete:
+		i := b.caret + 1
+		if word {
+			i = b.wordRight(b.caret)
+		}
+		te.edit(b.deleteTo(i))
+	case winl.KeyEnter, winl.KeyKPEnter:
+		if !b.multiline {
+			return false
+		}
+		te.edit(b.insert("\n", editOther))
+	case winl.KeyA, winl.KeyC, winl.KeyX, winl.KeyV, winl.KeyY, winl.KeyZ:
+		if !ctrl {
+			return false
+		}
+		switch {
+		case key == winl.KeyA:
+			te.SelectAll()
+		case key == winl.KeyC:
+			te.Copy()
+		case key == winl.KeyX:
+			te.Cut()
+		case key == winl.KeyV:
+			te.Paste()
+		case key == winl.KeyY || key == winl.KeyZ && shift:
+			te.Redo()
+		default:
+			te.Undo()
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// OnTextInput event handler, typed text replaces selection
+func (te *TextEdit) OnTextInput(text string) bool {
+	if !te.Enabled() {
+		return false
+	}
+	te.edit(te.buf.insert(text, editType))
+	return true
+}
+
+// SizeHint reports a few lines of text as preferred size
+func (te *TextEdit) SizeHint() SizeHint {
+	if te.hint != nil {
+		return *te.hint
+	}
+	lh := te.lineHeight()
+	pad := skin.Get().SizePadding()
+	h := SizeHint{Min: Vec2{lh * 2, lh + pad}, Pref: Vec2{lh * 20, lh*6 + pad}}
+	if !te.buf.multiline {
+		h.Pref = Vec2{lh * 10, lh + pad}
+	}
+	return h
+}
+
+// Render the element, only visible lines are drawn
+func (te *TextEdit) Render() {
+	sk := skin.Get()
+	st := te.state()
+	sk.DrawEdit(te.bounds, st)
+
+	tr := te.textRect()
+	wb := te.WindowBounds()
+	glman.StackClip2D.Push()
+	glman.StackClip2D.Load(glman.StackClip2D.Peek().Intersect(Rect{wb[0] + tr[0], wb[1] + tr[1], wb[0] + tr[2], wb[1] + tr[3]}))
+	defer glman.StackClip2D.Pop()
+
+	fnt := te.Font()
+	lh := te.lineHeight()
+	// text is drawn in coordinates of parent
+	ox, oy := te.bounds[0], te.bounds[1]
+	x0 := ox + tr[0] - te.scroll[0]
+	if len(te.buf.text) == 0 {
+		if te.placeholder != "" {
+			glman.DynDrawText(te.placeholder, Rect{ox + tr[0], oy + te.lineY(0), ox + tr[2], oy + te.lineY(0) + lh},
+				fnt, sk.Color(skin.RoleTextDisabled), glman.DtSingleLine)
+		}
+	} else {
+		text, hl, hlText := sk.TextColor(st), sk.Color(skin.RoleHighlight), sk.Color(skin.RoleHighlightText)
+		start, end := te.buf.selection()
+		first, last := 0, 0
+		if te.buf.multiline {
+			first = int(te.scroll[1] / lh)
+			last = int((te.scroll[1] + tr.Height()) / lh)
+			if last >= len(te.buf.lines) {
+				last = len(te.buf.lines) - 1
+			}
+		}
+		for l := first; l <= last; l++ {
+			y := oy + te.lineY(l)
+			s := []rune(te.lineText(l))
+			ls, _ := te.buf.line(l)
+			// selected part of line
+			a, b := start-ls, end-ls
+			if a < 0 {
+				a = 0
+			}
+			if b > len(s) {
+				b = len(s)
+			}
+			if a >= b {
+				glman.DynDrawText(string(s), Rect{x0, y, x0 + 99999, y + lh}, fnt, text, glman.DtSingleLine)
+				continue
+			}
+			offs := glman.TextOffsets(string(s), fnt)
+			glman.DynFillRect(Rect{x0 + offs[a], y, x0 + offs[b], y + lh}, hl)
+			for _, seg := range [3][2]int{{0, a}, {a, b}, {b, len(s)}} {
+				c := text
+				if seg[0] == a && seg[1] == b {
+					c = hlText
+				}
+				if seg[0] < seg[1] {
+					x := x0 + offs[seg[0]]
+					glman.DynDrawText(string(s[seg[0]:seg[1]]), Rect{x, y, x + 99999, y + lh}, fnt, c, glman.DtSingleLine)
+				}
+			}
+		}
+	}
+	if te.HasFocus() {
+		x, y := te.caretPos(te.buf.caret)
+		x = round(ox + x)
+		glman.DynFillRect(Rect{x, oy + y, x + 1, oy + y + lh}, sk.TextColor(st))
+	}
+}
@@ -3,4 +3,59 @@ package gui
 // Widget is gui control which can handle user input.
 type Widget struct {
 	Elem
+	tabIndex int
+	disabled bool
+}
+
+// Focusable reports whether the widget accepts keyboard focus
+func (wd *Widget) Focusable() bool {
+	return false
+}
+
+// TabIndex reports order of the widget in Tab navigation, see SetTabIndex
+func (wd *Widget) TabIndex() int {
+	return wd.tabIndex
+}
+
+// SetTabIndex set order in Tab navigation, positive ones come first in ascending order, then 0 in tree order, negative is skipped
+func (wd *Widget) SetTabIndex(i int) {
+	wd.tabIndex = i
+}
+
+// Enabled reports whether the widget accepts user input
+func (wd *Widget) Enabled() bool {
+	return !wd.disabled
+}
+
+// SetEnabled enables or disables user input of the widget, a disabled widget loses focus
+func (wd *Widget) SetEnabled(b bool) {
+	if b == !wd.disabled {
+		return
+	}
+	wd.disabled = !b
+	if !b && wd.HasFocus() {
+		wd.Window().SetFocusOwner(nil)
+	}
+	wd.Invalidate(Rect{})
+}
+
+// HasFocus reports whether the widget is the focus owner of its window
+func (wd *Widget) HasFocus() bool {
+	w := wd.Window()
+	return w != nil && w.FocusOwner() == wd.Self
+}
+
+// SetFocus makes the widget focus owner of its window
+func (wd *Widget) SetFocus() {
+	if w := wd.Window(); w != nil {
+		w.SetFocusOwner(wd.Self.(IWidget))
+	}
+}
+
+// OnFocusIn event handler, called when the widget becomes focus owner
+func (wd *Widget) OnFocusIn() {
+}
+
+// OnFocusOut event handler, called when the widget loses focus
+func (wd *Widget) OnFocusOut() {
 }
@@ -0,0 +1,577 @@
+//go:build headless
+// +build headless
+
+package gui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tetra/internal/gl"
+	"tetra/internal/winl"
+	"tetra/lib/glman"
+	"tetra/lib/skin"
+)
+
+// withPane runs f with a pane filling a shown window of 320x240
+func withPane(t *testing.T, f func(w *Window, pn IPane)) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		w.Show()
+		pn := NewPane()
+		if err := w.SetLayout(&WndLayout{Pane: pn}); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+		f(w, pn)
+	})
+}
+
+// pixelAt reads color of window at x, y in UI units
+func pixelAt(w *Window, x, y float32) (px [4]uint8) {
+	s := w.ContentScale()
+	_, h := w.Size()
+	gl.ReadPixels(int32(x*s), int32(h-y*s-1), 1, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(&px[0]))
+	return
+}
+
+func TestTextLayout(t *testing.T) {
+	withPane(t, func(w *Window, pn IPane) {
+		fnt := glman.LoadFont("SquaresBold", 16)
+		width, lh := glman.MeasureText("hello world", fnt)
+		if got := glman.WrapText("hello world foo", width+1, fnt); strings.Join(got, "|") != "hello world|foo" {
+			t.Errorf("WrapText = %q", got)
+		}
+		// a word longer than width is broken anywhere
+		got := glman.WrapText("helloworld", width/2, fnt)
+		for _, line := range got {
+			if lw, _ := glman.MeasureText(line, fnt); lw > width/2 {
+				t.Errorf("WrapText of long word = %q, %q is wider than %g", got, line, width/2)
+			}
+		}
+		if len(got) < 2 || strings.Join(got, "") != "helloworld" {
+			t.Errorf("WrapText of long word = %q", got)
+		}
+		if got := glman.WrapText("a\n\nb", width, fnt); len(got) != 3 {
+			t.Errorf("WrapText keeps empty lines = %q", got)
+		}
+		half, _ := glman.MeasureText("hello", fnt)
+		elided := glman.ElideText("hello world", half, fnt)
+		if gw, _ := glman.MeasureText(elided, fnt); !strings.HasSuffix(elided, "…") || gw > half {
+			t.Errorf("ElideText = %q of width %g, want ending with ellipsis within %g", elided, gw, half)
+		}
+		if got := glman.ElideText("hello", half, fnt); got != "hello" {
+			t.Errorf("ElideText = %q, want hello", got)
+		}
+
+		l := NewLabel()
+		l.SetFont(fnt)
+		l.SetText("hello world foo")
+		full, _ := glman.MeasureText("hello world foo", fnt)
+		if h := l.SizeHint(); h.Pref != (Vec2{full, lh}) {
+			t.Errorf("SizeHint().Pref = %v, want %v", h.Pref, Vec2{full, lh})
+		}
+		// wrapped label takes more lines as it gets narrower
+		l.SetWrap(true)
+		l.SetBounds(Rect{0, 0, width + 1, lh})
+		if h := l.SizeHint(); h.Pref[1] != lh*2 {
+			t.Errorf("SizeHint().Pref of wrapped label = %v, want height %g", h.Pref, lh*2)
+		}
+		l.SetWrap(false)
+		l.SetElide(true)
+		if h := l.SizeHint(); h.Min[0] >= half || h.Pref[0] != full {
+			t.Errorf("SizeHint() of elided label = %v", h)
+		}
+	})
+}
+
+func TestImageAndIcon(t *testing.T) {
+	withPane(t, func(w *Window, pn IPane) {
+		red := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		draw.Draw(red, red.Bounds(), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+		im := NewImage()
+		im.SetSource(red)
+		if h := im.SizeHint(); h.Pref != (Vec2{8, 8}) {
+			t.Errorf("Image.SizeHint().Pref = %v, want [8 8]", h.Pref)
+		}
+		im.SetBounds(Rect{100, 100, 140, 140})
+		pn.Insert(-1, im)
+
+		ic := NewIcon()
+		ic.SetName("dot")
+		ic.SetColor(Color{0, 1, 0, 1})
+		ic.SetBounds(Rect{150, 100, 198, 148})
+		pn.Insert(-1, ic)
+
+		w.Render()
+		if px := pixelAt(w, 120, 120); px[0] < 200 || px[1] > 50 {
+			t.Errorf("pixel of image = %v, want red", px)
+		}
+		if px := pixelAt(w, 174, 124); px[1] < 200 || px[0] > 50 {
+			t.Errorf("pixel at center of icon = %v, want green", px)
+		}
+		if px := pixelAt(w, 152, 102); px[1] > 50 {
+			t.Errorf("pixel at corner of icon = %v, want not green", px)
+		}
+
+		// contained image keeps aspect ratio, out of it is not drawn
+		im.SetFit(ImageContain)
+		im.SetBounds(Rect{100, 100, 140, 120})
+		w.Render()
+		if px := pixelAt(w, 105, 110); px[0] > 50 {
+			t.Errorf("pixel beside contained image = %v, want not red", px)
+		}
+		if px := pixelAt(w, 120, 110); px[0] < 200 {
+			t.Errorf("pixel of contained image = %v, want red", px)
+		}
+
+		// the old picture is released, the new one is uploaded
+		blue := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		draw.Draw(blue, blue.Bounds(), image.NewUniform(color.RGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+		im.SetSource(blue)
+		w.Render()
+		if px := pixelAt(w, 120, 110); px[2] < 200 || px[0] > 50 {
+			t.Errorf("pixel of new image = %v, want blue", px)
+		}
+	})
+}
+
+func TestButtons(t *testing.T) {
+	withPane(t, func(w *Window, pn IPane) {
+		s := w.ContentScale()
+		clicks := 0
+		btn := NewButton()
+		btn.SetText("OK")
+		btn.SetIcon("check")
+		btn.OnClick(func() { clicks++ })
+		btn.SetBounds(Rect{20, 20, 120, 60})
+		pn.Insert(-1, btn)
+
+		// click by mouse, released out of button cancels it
+		w.InjectMouseMove(50*s, 40*s)
+		w.InjectMousePress(winl.MouseLeft, 50*s, 40*s)
+		if !btn.HasFocus() || btn.state()&skin.StatePressed == 0 {
+			t.Errorf("pressed button state = %v, focus = %v", btn.state(), btn.HasFocus())
+		}
+		w.InjectMouseRelease(winl.MouseLeft, 50*s, 40*s)
+		w.InjectMousePress(winl.MouseLeft, 50*s, 40*s)
+		w.InjectMouseMove(200*s, 200*s)
+		if btn.state()&skin.StatePressed != 0 {
+			t.Errorf("button looks pressed after mouse moved out")
+		}
+		w.InjectMouseRelease(winl.MouseLeft, 200*s, 200*s)
+		if clicks != 1 {
+			t.Errorf("clicks by mouse = %d, want 1", clicks)
+		}
+
+		// Space clicks on release, Enter on press
+		w.InjectKey(winl.KeySpace, 0, true)
+		if clicks != 1 {
+			t.Errorf("clicked by pressing Space")
+		}
+		w.InjectKey(winl.KeySpace, 0, false)
+		w.InjectKey(winl.KeyEnter, 0, true)
+		w.InjectKey(winl.KeyEnter, 0, false)
+		if clicks != 3 {
+			t.Errorf("clicks by keys = %d, want 3", clicks)
+		}
+
+		// disabled button loses focus and ignores clicks
+		btn.SetEnabled(false)
+		btn.Click()
+		if clicks != 3 || btn.HasFocus() || btn.Focusable() {
+			t.Errorf("disabled button: clicks = %d, HasFocus() = %v, Focusable() = %v", clicks, btn.HasFocus(), btn.Focusable())
+		}
+		btn.SetEnabled(true)
+
+		btn.SetCheckable(true)
+		btn.Click()
+		btn.Click()
+		btn.Click()
+		if !btn.Checked() {
+			t.Errorf("toggle button clicked 3 times is not checked")
+		}
+
+		cb := NewCheckBox()
+		cb.SetText("check")
+		cb.Click()
+		if !cb.Checked() {
+			t.Errorf("check box is not checked by click")
+		}
+		if h := cb.SizeHint(); h.Pref[0] <= h.Pref[1] {
+			t.Errorf("CheckBox.SizeHint() = %v, want wider than box", h)
+		}
+
+		var radios []*RadioButton
+		for i := 0; i < 3; i++ {
+			rb := NewRadioButton()
+			pn.Insert(-1, rb)
+			radios = append(radios, rb)
+		}
+		radios[0].Click()
+		radios[2].Click()
+		radios[2].Click()
+		if radios[0].Checked() || radios[1].Checked() || !radios[2].Checked() {
+			t.Errorf("radio buttons checked = %v %v %v, want only the last", radios[0].Checked(), radios[1].Checked(), radios[2].Checked())
+		}
+
+		btn.SetCheckable(false)
+		w.InjectMouseMove(300*s, 200*s)
+		w.Render()
+		want := skin.Get().Color(skin.RoleButton)
+		if px := pixelAt(w, 22, 58); absDiff(px[0], uint8(want[0]*255)) > 2 {
+			t.Errorf("pixel of button background = %v, want %v", px, want)
+		}
+	})
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func TestLineEdit(t *testing.T) {
+	withPane(t, func(w *Window, pn IPane) {
+		le := NewLineEdit()
+		le.SetBounds(Rect{10, 10, 210, 40})
+		pn.Insert(-1, le)
+		le.SetFocus()
+
+		w.InjectTextInput("hello")
+		w.InjectTextInput(" world")
+		// candidate window of input method follows caret
+		s := w.ContentScale()
+		cx, cy := le.caretPos(le.buf.caret)
+		if x, y, _, _ := winl.TextInputRect(&w.Window); x != (10+cx)*s || y != (10+cy)*s || cx < 20 {
+			t.Errorf("TextInputRect() = %g, %g, want caret at %g, %g", x, y, (10+cx)*s, (10+cy)*s)
+		}
+		key := func(k winl.Key, mods winl.Mod) {
+			w.InjectKey(k, mods, true)
+			w.InjectKey(k, mods, false)
+		}
+		key(winl.KeyLeft, winl.ModControl)
+		key(winl.KeyEnd, winl.ModShift)
+		if s := le.SelectedText(); s != "world" {
+			t.Errorf("selected %q by Ctrl+Left and Shift+End, want world", s)
+		}
+		key(winl.KeyC, winl.ModControl)
+		if s := winl.ClipboardText(); s != "world" {
+			t.Errorf("clipboard = %q, want world", s)
+		}
+		key(winl.KeyBackspace, 0)
+		key(winl.KeyBackspace, winl.ModControl)
+		if s := le.Text(); s != "" {
+			t.Errorf("text after deleting = %q, want empty", s)
+		}
+		// successive deletes are undone at once
+		key(winl.KeyZ, winl.ModControl)
+		if s := le.Text(); s != "hello world" {
+			t.Errorf("text after undo = %q, want hello world", s)
+		}
+		// typing is undone at once
+		key(winl.KeyZ, winl.ModControl)
+		if s := le.Text(); s != "" || !le.CanRedo() {
+			t.Errorf("text after undo typing = %q, want empty", s)
+		}
+		key(winl.KeyY, winl.ModControl)
+		if s := le.Text(); s != "hello world" {
+			t.Errorf("text after redo = %q, want hello world", s)
+		}
+
+		// pasted line breaks become spaces
+		winl.SetClipboardText("a\nb")
+		key(winl.KeyA, winl.ModControl)
+		key(winl.KeyV, winl.ModControl)
+		if s := le.Text(); s != "a b" {
+			t.Errorf("text after paste = %q, want \"a b\"", s)
+		}
+
+		var entered string
+		le.OnEnter(func(s string) { entered = s })
+		key(winl.KeyEnter, 0)
+		if entered != "a b" {
+			t.Errorf("entered %q, want \"a b\"", entered)
+		}
+
+		le.SetText("")
+		le.SetValidator(func(s string) bool { return strings.Trim(s, "0123456789") == "" })
+		w.InjectTextInput("12")
+		w.InjectTextInput("a")
+		if s := le.Text(); s != "12" {
+			t.Errorf("validated text = %q, want 12", s)
+		}
+		le.SetValidator(nil)
+
+		le.SetPassword(true)
+		le.SetText("secret")
+		enc, salt := le.Passwd()
+		if !le.CheckPasswd(enc, salt) {
+			t.Errorf("CheckPasswd of own hash is false")
+		}
+		winl.SetClipboardText("")
+		le.SelectAll()
+		le.Copy()
+		if s := winl.ClipboardText(); s != "" {
+			t.Errorf("password is copied to clipboard: %q", s)
+		}
+
+		le.SetPassword(false)
+		le.SetText("")
+		le.SetPlaceholder("name")
+		w.Render()
+
+		le.SetText("MMMM")
+		le.SelectAll()
+		w.Render()
+		x := 10 + le.textRect()[0] + 1
+		hl := skin.Get().Color(skin.RoleHighlight)
+		if px := pixelAt(w, x, 10+le.lineY(0)+1); absDiff(px[2], uint8(hl[2]*255)) > 2 || absDiff(px[0], uint8(hl[0]*255)) > 2 {
+			t.Errorf("pixel of selection = %v, want %v", px, hl)
+		}
+	})
+}
+
+func TestTextEdit(t *testing.T) {
+	withPane(t, func(w *Window, pn IPane) {
+		te := NewTextEdit()
+		te.SetBounds(Rect{10, 10, 210, 110})
+		pn.Insert(-1, te)
+		te.SetFocus()
+
+		var lines []string
+		for i := 0; i < 100; i++ {
+			lines = append(lines, "line")
+		}
+		te.SetText(strings.Join(lines, "\n"))
+		if te.scroll[1] <= 0 {
+			t.Errorf("not scrolled to caret at end, scroll = %v", te.scroll)
+		}
+		w.InjectKey(winl.KeyHome, winl.ModControl, true)
+		if start, _ := te.Selection(); start != 0 || te.scroll[1] != 0 {
+			t.Errorf("after Ctrl+Home, caret = %d, scroll = %v", start, te.scroll)
+		}
+		w.InjectKey(winl.KeyRight, 0, true)
+		w.InjectKey(winl.KeyDown, 0, true)
+		if start, _ := te.Selection(); start != 6 {
+			t.Errorf("after Right and Down, caret = %d, want 6", start)
+		}
+		w.InjectKey(winl.KeyEnter, 0, true)
+		if !strings.HasPrefix(te.Text(), "line\nl\nine\n") {
+			t.Errorf("Enter doesn't break line, text begins with %q", te.Text()[:12])
+		}
+
+		s := w.ContentScale()
+		w.InjectMouseMove(100*s, 50*s)
+		w.InjectMouseWheel(true, -1)
+		if te.scroll[1] <= 0 {
+			t.Errorf("not scrolled by wheel, scroll = %v", te.scroll)
+		}
+
+		// click at start of the first visible line
+		tr := te.textRect()
+		first := int(te.scroll[1]/te.lineHeight()) + 1
+		y := 10 + te.lineY(first) + 1
+		w.InjectMouseMove(12*s, y*s)
+		w.InjectMousePress(winl.MouseLeft, (10+tr[0])*s, y*s)
+		w.InjectMouseRelease(winl.MouseLeft, (10+tr[0])*s, y*s)
+		if start, _ := te.Selection(); te.buf.lineOf(start) != first || te.buf.lines[first] != start {
+			t.Errorf("clicked at line %d, caret = %d at line %d", first, start, te.buf.lineOf(start))
+		}
+		w.Render()
+
+		// edits update lines and widths in place, lines not edited are not measured again
+		te.contentSize()
+		te.widths[len(te.widths)-10] = 0.5
+		te.SetSelection(2, 2)
+		w.InjectTextInput("a much longer line than others")
+		te.ReplaceSelection("x\ny\nz")
+		te.SetSelection(1, 20)
+		w.InjectKey(winl.KeyDelete, 0, true)
+		te.Undo()
+		te.Undo()
+		te.Redo()
+		if te.widths[len(te.widths)-10] != 0.5 {
+			t.Errorf("line not edited is measured again")
+		}
+		check := NewTextEdit()
+		check.SetText(te.Text())
+		cw, _ := check.contentSize()
+		te.widths[len(te.widths)-10] = check.widths[len(te.widths)-10]
+		if w, _ := te.contentSize(); w != cw || !reflect.DeepEqual(te.buf.lines, check.buf.lines) || !reflect.DeepEqual(te.widths, check.widths) {
+			t.Errorf("after edits, width = %g, lines = %v, want %g, %v", w, te.buf.lines, cw, check.buf.lines)
+		}
+		if te.Text() != "lia much longer line than othersx\ny\nzne\nl\nine\n"+strings.Join(lines[2:], "\n") {
+			t.Errorf("text after undo and redo = %q", te.Text()[:40])
+		}
+	})
+}
+
+func TestScrollView(t *testing.T) {
+	withPane(t, func(w *Window, pn IPane) {
+		s := w.ContentScale()
+		sv := NewScrollView()
+		sv.SetBounds(Rect{10, 10, 210, 110})
+		pn.Insert(-1, sv)
+		content := NewElem()
+		content.SetLayout(VBox(0))
+		var rows []*countElem
+		for i := 0; i < 20; i++ {
+			c := &countElem{}
+			c.Self = c
+			c.SetSizeHint(SizeHint{Pref: Vec2{300, 30}})
+			content.Insert(-1, c)
+			rows = append(rows, c)
+		}
+		sv.SetContent(content)
+		bar := skin.Get().SizeScrollBar()
+		if !sv.bars[0] || !sv.bars[1] || sv.view != (Rect{0, 0, 200 - bar, 100 - bar}) {
+			t.Errorf("bars = %v, view = %v", sv.bars, sv.view)
+		}
+
+		// rows out of view are not rendered
+		w.Render()
+		for i, c := range rows {
+			if visible := float32(i*30) < sv.view[3]; (c.renders > 0) != visible {
+				t.Errorf("row %d rendered %d times, visible = %v", i, c.renders, visible)
+			}
+		}
+
+		// wheel scrolls smoothly on both axes
+		w.InjectMouseMove(100*s, 50*s)
+		w.InjectMouseWheel(true, -1)
+		w.InjectMouseWheel(false, -1)
+		if pos := sv.ScrollPos(); pos != (Vec2{}) || sv.target[0] <= 0 || sv.target[1] <= 0 {
+			t.Errorf("after wheel, ScrollPos() = %v, target = %v", pos, sv.target)
+		}
+		sv.animate(0.01)
+		if pos := sv.ScrollPos(); pos[1] <= 0 || pos[1] >= sv.target[1] {
+			t.Errorf("smooth scrolling ScrollPos() = %v, want between 0 and %v", pos, sv.target)
+		}
+		for sv.animate(0.05) {
+		}
+		if pos := sv.ScrollPos(); pos != sv.target {
+			t.Errorf("ScrollPos() = %v, want target %v", pos, sv.target)
+		}
+
+		sv.ScrollTo(rows[19].Bounds())
+		if pos := sv.ScrollPos(); pos[1] != 600-sv.view.Height() {
+			t.Errorf("ScrollTo the last row, ScrollPos() = %v", pos)
+		}
+
+		// drag content
+		sv.SetScrollPos(Vec2{})
+		w.InjectMousePress(winl.MouseLeft, 100*s, 50*s)
+		w.InjectMouseMove(100*s, 20*s)
+		if pos := sv.ScrollPos(); pos[1] != 30 {
+			t.Errorf("dragged content by 30, ScrollPos() = %v", pos)
+		}
+		w.InjectMouseRelease(winl.MouseLeft, 100*s, 20*s)
+
+		// drag thumb to the end
+		sv.SetScrollPos(Vec2{})
+		track, thumb := sv.barRects(1)
+		x, y := 10+(thumb[0]+thumb[2])/2, 10+(thumb[1]+thumb[3])/2
+		room := track.Height() - thumb.Height()
+		w.InjectMouseMove(x*s, y*s)
+		w.InjectMousePress(winl.MouseLeft, x*s, y*s)
+		w.InjectMouseMove(x*s, (y+room)*s)
+		w.InjectMouseRelease(winl.MouseLeft, x*s, (y+room)*s)
+		if pos := sv.ScrollPos(); pos[1] != sv.maxOffset()[1] {
+			t.Errorf("dragged thumb to the end, ScrollPos() = %v, want %v", pos, sv.maxOffset())
+		}
+
+		// focused widget is scrolled into view
+		btn := NewButton()
+		btn.SetText("last")
+		content.Insert(-1, btn)
+		sv.Relayout()
+		sv.SetScrollPos(Vec2{})
+		btn.SetFocus()
+		wb, vb := btn.WindowBounds(), sv.WindowBounds()
+		if wb[1] < vb[1] || wb[3] > vb[1]+sv.view[3] {
+			t.Errorf("focused button at %v, out of view %v", wb, vb)
+		}
+
+		// smooth scrolling stops when removed
+		sv.SetScrollPos(Vec2{})
+		w.InjectMouseMove(100*s, 50*s)
+		w.InjectMouseWheel(true, -1)
+		if sv.ticker == nil {
+			t.Error("wheel starts no smooth scrolling")
+		}
+		pn.Remove(pn.Index(sv))
+		if sv.ticker != nil || sv.Window() != nil || btn.Window() != nil {
+			t.Errorf("after Remove, ticker = %v, Window() = %v, want stopped and detached", sv.ticker, sv.Window())
+		}
+	})
+}
+
+func TestListBox(t *testing.T) {
+	withPane(t, func(w *Window, pn IPane) {
+		s := w.ContentScale()
+		sv := NewScrollView()
+		sv.SetBounds(Rect{10, 10, 210, 110})
+		pn.Insert(-1, sv)
+		lb := NewListBox()
+		sv.SetContent(lb)
+		var items []string
+		for i := 0; i < 30; i++ {
+			items = append(items, fmt.Sprint("item ", i))
+		}
+		lb.SetItems(items)
+		var selected, activated []int
+		lb.OnSelect(func(i int) { selected = append(selected, i) })
+		lb.OnActivate(func(i int) { activated = append(activated, i) })
+		rowH := lb.rowHeight()
+		if h := lb.Bounds().Height(); h != rowH*30 {
+			t.Errorf("height of content = %g, want %g", h, rowH*30)
+		}
+
+		// click selects, double-click activates
+		y := 10 + rowH*1.5
+		w.InjectMousePress(winl.MouseLeft, 50*s, y*s)
+		w.InjectMouseRelease(winl.MouseLeft, 50*s, y*s)
+		w.InjectMousePress(winl.MouseLeft, 50*s, y*s)
+		w.InjectMouseRelease(winl.MouseLeft, 50*s, y*s)
+		if lb.Selected() != 1 || !reflect.DeepEqual(selected, []int{1}) || !reflect.DeepEqual(activated, []int{1}) {
+			t.Errorf("double-click, Selected() = %d, selected %v, activated %v", lb.Selected(), selected, activated)
+		}
+
+		// keys move selection, which is scrolled into view
+		lb.SetFocus()
+		w.InjectKey(winl.KeyEnd, 0, true)
+		if lb.Selected() != 29 || sv.ScrollPos()[1] != sv.maxOffset()[1] {
+			t.Errorf("End, Selected() = %d, ScrollPos() = %v", lb.Selected(), sv.ScrollPos())
+		}
+		w.InjectKey(winl.KeyHome, 0, true)
+		w.InjectKey(winl.KeyDown, 0, true)
+		w.InjectKey(winl.KeyEnter, 0, true)
+		if lb.Selected() != 1 || sv.ScrollPos()[1] != 0 || !reflect.DeepEqual(activated, []int{1, 1}) {
+			t.Errorf("Home, Down and Enter, Selected() = %d, ScrollPos() = %v, activated %v", lb.Selected(), sv.ScrollPos(), activated)
+		}
+
+		// SetSelected doesn't call handlers
+		selected = nil
+		lb.SetSelected(5)
+		if lb.Selected() != 5 || selected != nil {
+			t.Errorf("SetSelected(5), Selected() = %d, selected %v", lb.Selected(), selected)
+		}
+		lb.SetItems(nil)
+		if lb.Selected() != -1 {
+			t.Errorf("SetItems(nil), Selected() = %d, want -1", lb.Selected())
+		}
+	})
+}
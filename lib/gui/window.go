@@ -1,7 +1,8 @@
 package gui
 
 import (
-	"runtime"
+	"encoding/json"
+	"sort"
 	"tetra/internal/winl"
 	"tetra/lib/dbg"
 	"tetra/lib/geom"
@@ -30,8 +31,30 @@ type Window struct {
 
 	objID string
 
-	layout  *WndLayout
-	szSplit float32
+	layout     *WndLayout
+	szSplit    float32
+	splitHover *WndLayout // node whose splitter is under mouse
+	splitDrag  *WndLayout // node whose splitter is being dragged
+
+	dockPane   IPane // pane being dragged to dock, see BeginDock
+	dockTarget IPane // pane under mouse while docking
+	dockZone   DockZone
+
+	dialogs  []IDialog // modal dialogs, the last one is on top
+	dlgFocus []IWidget // focus owner before each dialog was shown
+
+	hover       IElem   // deepest element under mouse
+	capture     IElem   // receives mouse events regardless of position
+	autoCapture bool    // capture is set by mouse press, released with all buttons
+	mouseLook   IElem   // receives mouse deltas in relative mouse mode, see Pane3D.SetMouseLook
+	buttons     int     // bits of mouse buttons pressed
+	mx, my      float32 // last mouse position in UI units
+
+	focus IWidget  // receives keyboard events
+	mods  winl.Mod // modifier keys pressed
+
+	damage Rect       // area to repaint in UI units, see Invalidate
+	back   backBuffer // keeps content between repaints
 
 	matProj Mat4
 	matView Mat4
@@ -39,6 +62,15 @@ type Window struct {
 	vbo uint32
 }
 
+// wndState is saved geometry and layout of Window
+type wndState struct {
+	X      float32         `json:"x"`
+	Y      float32         `json:"y"`
+	Width  float32         `json:"width"`
+	Height float32         `json:"height"`
+	Layout json.RawMessage `json:"layout,omitempty"`
+}
+
 // OnSkin handle the skin change event
 func (w *Window) OnSkin() {
 	dbg.Logf("OnSkin()\n")
@@ -91,21 +123,37 @@ func (w *Window) OnCreate() {
 // OnDestroy event handler
 func (w *Window) OnDestroy() {
 	dbg.Logf("OnDestroy()\n")
+	// objects can't be shared are destroyed with context of the window
+	w.MakeCurrent()
+	w.back.release()
+	glman.ForgetContext(w.Context())
+	// detached elements stop their timers
+	if w.layout != nil {
+		w.layout.walk(func(pn IPane) {
+			pn.SetWindow(nil)
+		})
+	}
+	for _, dlg := range w.dialogs {
+		dlg.SetWindow(nil)
+	}
 	w.Window.OnDestroy()
 }
 
-// OnResize event handler
+// OnResize event handler, width and height are in pixels
 func (w *Window) OnResize(width, height float32) {
 	dbg.Logf("OnResize(%f, %f)\n", width, height)
 	w.Window.OnResize(width, height)
 
-	if w.layout != nil {
-		w.layout.rc = Rect{0, 0, width, height}
-		w.layout.CalcLayout(w.szSplit)
+	// layout and drawing are in UI units, scaled to pixels by projection
+	s := w.ContentScale()
+	uw, uh := width/s, height/s
+	w.calcLayout()
+	for _, dlg := range w.dialogs {
+		dlg.Center(uw, uh)
 	}
-	w.matProj = geom.Mat4Ortho(0, width, height, 0, -1, 1)
+	w.matProj = geom.Mat4Ortho(0, uw, uh, 0, -1, 1)
 	// move origin form center to top-left
-	w.matProj = w.matProj.Mult(geom.Mat4Trans(-width/2, -height/2, 0))
+	w.matProj = w.matProj.Mult(geom.Mat4Trans(-uw/2, -uh/2, 0))
 	//w.MakeCurrent()
 	gl.Viewport(0, 0, int32(width), int32(height))
 	if w.IsVisible() {
@@ -114,45 +162,559 @@ func (w *Window) OnResize(width, height float32) {
 	}
 }
 
+// OnFrame event handler, animated window with HintVideo renders every frame
+func (w *Window) OnFrame(dt float32) {
+	w.Render()
+}
+
+// OnScaleChanged event handler, relayout in new UI units
+func (w *Window) OnScaleChanged(scale float32) {
+	dbg.Logf("OnScaleChanged(%f)\n", scale)
+	w.MakeCurrent()
+	width, height := w.Size()
+	w.Self.OnResize(width, height)
+}
+
 // OnMouseEnter event handler
 func (w *Window) OnMouseEnter(x, y float32) {
 	dbg.Logf("OnMouseEnter(%f, %f)\n", x, y)
+	w.pointerAt(x, y)
 }
 
 // OnMouseLeave event handler
 func (w *Window) OnMouseLeave(x, y float32) {
 	dbg.Logf("OnMouseLeave(%f, %f)\n", x, y)
+	if w.capture == nil {
+		w.setHover(nil)
+	}
+	if w.splitDrag == nil {
+		w.hoverSplitter(nil)
+	}
 }
 
-// OnMouseMove event handler
+// OnMouseMove event handler, routes to the element under mouse or the capture
 func (w *Window) OnMouseMove(x, y float32) {
 	//dbg.Logf("OnMouseMove(%f, %f)\n", x, y)
+	if w.dockPane != nil {
+		s := w.ContentScale()
+		w.mx, w.my = x/s, y/s
+		w.dockDrag()
+		return
+	}
+	if w.splitDrag != nil {
+		s := w.ContentScale()
+		w.mx, w.my = x/s, y/s
+		pos := w.mx
+		if w.splitDrag.Vert {
+			pos = w.my
+		}
+		w.splitDrag.DragSplitter(pos, w.szSplit)
+		w.Invalidate(Rect{})
+		return
+	}
+	w.pointerAt(x, y)
+	w.hoverSplitter(w.splitterAt())
+	w.bubble(w.pointerTarget(), func(el IElem, x, y float32) bool {
+		return el.OnMouseMove(x, y)
+	})
 }
 
-// OnMousePress event handler
+// OnMousePress event handler, the element handling it captures mouse until all buttons are released
 func (w *Window) OnMousePress(btn int, x, y float32) {
 	dbg.Logf("OnMousePress(%d, %f, %f)\n", btn, x, y)
-	w.Render()
+	w.pointerAt(x, y)
+	if w.dockPane != nil {
+		return
+	}
+	if btn == winl.MouseLeft && w.mods&dockMods == dockMods && w.capture == nil && w.Dialog() == nil {
+		if pn := w.layout.PaneAt(w.mx, w.my); pn != nil {
+			w.BeginDock(pn)
+			return
+		}
+	}
+	if sp := w.splitterAt(); sp != nil && btn == winl.MouseLeft {
+		w.hoverSplitter(sp)
+		w.splitDrag = sp
+		w.GrabMouse()
+		return
+	}
+	w.buttons |= 1 << uint(btn)
+	for el := w.pointerTarget(); el != nil; el = el.Parent() {
+		if wd, ok := el.(IWidget); ok && wd.Focusable() {
+			w.SetFocusOwner(wd)
+			break
+		}
+	}
+	el := w.bubble(w.pointerTarget(), func(el IElem, x, y float32) bool {
+		return el.OnMouseDown(btn, x, y)
+	})
+	if el != nil && w.capture == nil {
+		w.SetCapture(el)
+		w.autoCapture = true
+	}
 }
 
-// OnMouseRelease event handler
+// OnMouseRelease event handler, routes to the element under mouse or the capture
 func (w *Window) OnMouseRelease(btn int, x, y float32) {
 	dbg.Logf("OnMouseRelease(%d, %f, %f)\n", btn, x, y)
+	if w.dockPane != nil {
+		if btn == winl.MouseLeft {
+			s := w.ContentScale()
+			w.mx, w.my = x/s, y/s
+			w.dockDrag()
+			pn, target, zone := w.dockPane, w.dockTarget, w.dockZone
+			w.endDock()
+			w.DockPane(pn, target, zone)
+		}
+		return
+	}
+	if w.splitDrag != nil {
+		if btn == winl.MouseLeft {
+			w.splitDrag = nil
+			w.UngrabMouse()
+			w.pointerAt(x, y)
+			w.hoverSplitter(w.splitterAt())
+		}
+		return
+	}
+	w.pointerAt(x, y)
+	w.buttons &^= 1 << uint(btn)
+	w.bubble(w.pointerTarget(), func(el IElem, x, y float32) bool {
+		return el.OnMouseUp(btn, x, y)
+	})
+	if w.autoCapture && w.buttons == 0 {
+		w.ReleaseCapture()
+	}
 }
 
-// OnMouseWheel event handler
+// OnMouseWheel event handler, routes to the element under mouse or the capture
 func (w *Window) OnMouseWheel(vert bool, dz float32) {
 	if vert {
 		dbg.Logf("OnMouseWheel(vert, %f)\n", dz)
 	} else {
 		dbg.Logf("OnMouseWheel(horz, %f)\n", dz)
 	}
+	w.bubble(w.pointerTarget(), func(el IElem, x, y float32) bool {
+		return el.OnMouseWheel(vert, dz)
+	})
+}
+
+// OnMouseDelta event handler, routes to the pane of mouse look, the capture or the element under mouse
+func (w *Window) OnMouseDelta(dx, dy float32) {
+	el := w.mouseLook
+	if el == nil {
+		el = w.pointerTarget()
+	}
+	w.bubble(el, func(el IElem, x, y float32) bool {
+		return el.OnMouseDelta(dx, dy)
+	})
+}
+
+// splitterAt returns the layout node whose splitter is under mouse, nil if mouse is taken by element or dialog
+func (w *Window) splitterAt() *WndLayout {
+	if w.capture != nil || w.Dialog() != nil {
+		return nil
+	}
+	return w.layout.SplitterAt(w.mx, w.my)
+}
+
+// hoverSplitter shows resize cursor over splitter of sp, or arrow if sp is nil
+func (w *Window) hoverSplitter(sp *WndLayout) {
+	if sp == w.splitHover {
+		return
+	}
+	w.splitHover = sp
+	switch {
+	case sp == nil:
+		w.SetCursor(winl.CursorArrow)
+	case sp.Vert:
+		w.SetCursor(winl.CursorVResize)
+	default:
+		w.SetCursor(winl.CursorHResize)
+	}
+}
+
+// pointerAt saves mouse position x, y in pixels, and updates hover unless mouse is captured
+func (w *Window) pointerAt(x, y float32) {
+	s := w.ContentScale()
+	w.mx, w.my = x/s, y/s
+	if w.capture == nil {
+		w.setHover(w.HitTest(w.mx, w.my))
+	}
+}
+
+// pointerTarget returns the element receiving mouse events
+func (w *Window) pointerTarget() IElem {
+	if w.capture != nil {
+		return w.capture
+	}
+	return w.hover
+}
+
+// bubble calls f with el and its ancestors in turn, until f returns true.
+// x, y passed to f are mouse position in local coordinates, returns the element handled it.
+func (w *Window) bubble(el IElem, f func(el IElem, x, y float32) bool) IElem {
+	for ; el != nil; el = el.Parent() {
+		x, y := el.ToLocal(w.mx, w.my)
+		if f(el, x, y) {
+			return el
+		}
+	}
+	return nil
+}
+
+// contains reports whether x is el or one of its ancestors
+func contains(x, el IElem) bool {
+	for ; el != nil; el = el.Parent() {
+		if el == x {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetElem drops mouse and keyboard state referring to el and its descendants, which are removed from window
+func (w *Window) forgetElem(el IElem) {
+	if w.focus != nil && contains(el, w.focus) {
+		w.SetFocusOwner(nil)
+	}
+	for i, f := range w.dlgFocus {
+		if f != nil && contains(el, f) {
+			w.dlgFocus[i] = nil
+		}
+	}
+	if w.capture != nil && contains(el, w.capture) {
+		w.capture, w.autoCapture = nil, false
+		w.UngrabMouse()
+	}
+	if w.mouseLook != nil && contains(el, w.mouseLook) {
+		w.SetMouseLook(nil)
+	}
+	if w.hover != nil && contains(el, w.hover) {
+		// mouse is still over the parent
+		w.setHover(el.Parent())
+	}
+	if w.dockPane != nil && (contains(el, w.dockPane) || contains(el, w.dockTarget)) {
+		w.endDock()
+	}
+}
+
+// setHover changes the element under mouse, OnMouseLeave is called from inner to outer,
+// then OnMouseEnter from outer to inner, on elements which are not ancestors of both.
+func (w *Window) setHover(el IElem) {
+	old := w.hover
+	if el == old {
+		return
+	}
+	w.hover = el
+	for x := old; x != nil; x = x.Parent() {
+		if !contains(x, el) {
+			x.OnMouseLeave()
+		}
+	}
+	var enter []IElem
+	for x := el; x != nil; x = x.Parent() {
+		if !contains(x, old) {
+			enter = append(enter, x)
+		}
+	}
+	for i := len(enter) - 1; i >= 0; i-- {
+		enter[i].OnMouseEnter()
+	}
 }
 
-// OnExpose event handler
+// HitTest returns the deepest element at x, y in UI units, the top most dialog takes all if any
+func (w *Window) HitTest(x, y float32) IElem {
+	if dlg := w.Dialog(); dlg != nil {
+		if el := dlg.HitTest(x, y); el != nil {
+			return el
+		}
+		return dlg
+	}
+	if pn := w.layout.PaneAt(x, y); pn != nil {
+		return pn.HitTest(x, y)
+	}
+	return nil
+}
+
+// Hover returns the deepest element under mouse, nil if none
+func (w *Window) Hover() IElem {
+	return w.hover
+}
+
+// SetCapture routes mouse events to el regardless of mouse position, until ReleaseCapture
+func (w *Window) SetCapture(el IElem) {
+	w.capture, w.autoCapture = el, false
+	w.GrabMouse()
+}
+
+// ReleaseCapture stops routing mouse events to the element set by SetCapture
+func (w *Window) ReleaseCapture() {
+	if w.capture != nil {
+		w.capture, w.autoCapture = nil, false
+		w.UngrabMouse()
+	}
+	w.setHover(w.HitTest(w.mx, w.my))
+}
+
+// Capture returns the element capturing mouse, nil if none
+func (w *Window) Capture() IElem {
+	return w.capture
+}
+
+// SetMouseLook enable relative mouse mode, mouse deltas are routed to el, nil to disable it
+func (w *Window) SetMouseLook(el IElem) bool {
+	if !w.SetRelativeMouse(el != nil) {
+		return false
+	}
+	w.mouseLook = el
+	return true
+}
+
+// MouseLook returns the element receiving mouse deltas in relative mouse mode, nil if none
+func (w *Window) MouseLook() IElem {
+	return w.mouseLook
+}
+
+// Mods reports modifier keys pressed
+func (w *Window) Mods() winl.Mod {
+	return w.mods
+}
+
+// OnKeyPress event handler, routes to the focus owner and its ancestors, then OnKeyDown of window
+func (w *Window) OnKeyPress(key winl.Key, mods winl.Mod, repeat bool) {
+	dbg.Logf("OnKeyPress(%v, %v, %v)\n", key, mods, repeat)
+	w.mods = mods
+	if w.dockPane != nil {
+		if key == winl.KeyEscape {
+			w.endDock()
+		}
+		return
+	}
+	for el := w.keyTarget(); el != nil; el = el.Parent() {
+		if el.OnKeyDown(key, mods, repeat) {
+			return
+		}
+	}
+	w.Self.(IWindow).OnKeyDown(key, mods, repeat)
+}
+
+// OnKeyRelease event handler, routes to the focus owner and its ancestors
+func (w *Window) OnKeyRelease(key winl.Key, mods winl.Mod) {
+	dbg.Logf("OnKeyRelease(%v, %v)\n", key, mods)
+	w.mods = mods
+	for el := w.keyTarget(); el != nil; el = el.Parent() {
+		if el.OnKeyUp(key, mods) {
+			return
+		}
+	}
+}
+
+// OnTextInput event handler, routes to the focus owner and its ancestors
+func (w *Window) OnTextInput(text string) {
+	dbg.Logf("OnTextInput(%q)\n", text)
+	for el := w.keyTarget(); el != nil; el = el.Parent() {
+		if el.OnTextInput(text) {
+			return
+		}
+	}
+}
+
+// OnKeyDown handles key not handled by elements, Tab and Shift-Tab move focus, NumLock and CapsLock are ignored
+func (w *Window) OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool {
+	if key == winl.KeyTab && mods&(winl.ModControl|winl.ModAlt|winl.ModSuper) == 0 {
+		w.FocusNext(mods&winl.ModShift != 0)
+		return true
+	}
+	return false
+}
+
+// keyTarget returns the element receiving keyboard events, the top most dialog takes all if any
+func (w *Window) keyTarget() IElem {
+	var focus IElem
+	if w.focus != nil {
+		focus = w.focus
+	}
+	if dlg := w.Dialog(); dlg != nil && (focus == nil || !contains(dlg, focus)) {
+		return dlg
+	}
+	return focus
+}
+
+// FocusOwner returns the widget receiving keyboard events, nil if none
+func (w *Window) FocusOwner() IWidget {
+	return w.focus
+}
+
+// SetFocusOwner makes wd the focus owner, nil to clear focus, scroll views containing wd scroll to show it
+func (w *Window) SetFocusOwner(wd IWidget) {
+	old := w.focus
+	if wd == old {
+		return
+	}
+	w.focus = wd
+	if old != nil {
+		old.OnFocusOut()
+	}
+	if wd != nil {
+		wd.OnFocusIn()
+		scrollIntoView(wd)
+	}
+}
+
+// FocusNext moves focus to next widget in Tab order, or previous one if backward, returns false if none is focusable
+func (w *Window) FocusNext(backward bool) bool {
+	var roots []IElem
+	if dlg := w.Dialog(); dlg != nil {
+		roots = append(roots, dlg)
+	} else {
+		w.layout.walk(func(pn IPane) {
+			roots = append(roots, pn)
+		})
+	}
+	var order []IWidget
+	for _, el := range roots {
+		order = tabOrder(el, order)
+	}
+	// positive tab indexes first, then 0 in tree order
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i].TabIndex(), order[j].TabIndex()
+		return a > 0 && (b == 0 || a < b)
+	})
+	if len(order) == 0 {
+		return false
+	}
+	i := -1
+	for k, wd := range order {
+		if wd == w.focus {
+			i = k
+			break
+		}
+	}
+	switch {
+	case i < 0 && backward:
+		i = len(order) - 1
+	case i < 0:
+		i = 0
+	case backward:
+		i = (i + len(order) - 1) % len(order)
+	default:
+		i = (i + 1) % len(order)
+	}
+	w.SetFocusOwner(order[i])
+	return true
+}
+
+// tabOrder appends focusable widgets reachable by Tab in el and its descendants, in tree order
+func tabOrder(el IElem, order []IWidget) []IWidget {
+	if wd, ok := el.(IWidget); ok && wd.Focusable() && wd.TabIndex() >= 0 {
+		order = append(order, wd)
+	}
+	for _, c := range el.Children() {
+		order = tabOrder(c, order)
+	}
+	return order
+}
+
+// OnFocus event handler
+func (w *Window) OnFocus(focused bool) {
+	dbg.Logf("OnFocus(%v)\n", focused)
+	if !focused {
+		// releases of modifier keys are not reported to inactive window
+		w.mods = 0
+	}
+	// focus ring is drawn differently when inactive
+	w.Invalidate(Rect{})
+}
+
+// paneAt returns the pane under x, y in pixels, and x, y in pane coordinates
+func (w *Window) paneAt(x, y float32) (pn IPane, px, py float32) {
+	s := w.ContentScale()
+	x, y = x/s, y/s
+	if pn = w.layout.PaneAt(x, y); pn == nil {
+		return nil, 0, 0
+	}
+	rc := pn.Bounds()
+	return pn, x - rc[0], y - rc[1]
+}
+
+// OnDragEnter event handler
+func (w *Window) OnDragEnter(x, y float32) {
+	dbg.Logf("OnDragEnter(%f, %f)\n", x, y)
+}
+
+// OnDragOver event handler, routes to the pane under cursor
+func (w *Window) OnDragOver(x, y float32) bool {
+	if w.Dialog() != nil {
+		return false
+	}
+	pn, px, py := w.paneAt(x, y)
+	return pn != nil && pn.AcceptDrop(px, py)
+}
+
+// OnDragLeave event handler
+func (w *Window) OnDragLeave() {
+	dbg.Logf("OnDragLeave()\n")
+}
+
+// OnDrop event handler, delivers files to the pane under cursor
+func (w *Window) OnDrop(paths []string, x, y float32) {
+	dbg.Logf("OnDrop(%v, %f, %f)\n", paths, x, y)
+	if pn, px, py := w.paneAt(x, y); pn != nil && pn.AcceptDrop(px, py) {
+		pn.OnDrop(paths, px, py)
+	}
+}
+
+// OnExpose event handler, repaints area in pixels with damage accumulated by Invalidate
 func (w *Window) OnExpose(x, y, width, height float32) {
 	dbg.Logf("OnExpose(%g, %g, %g, %g)\n", x, y, width, height)
-	w.Render()
+	s := w.ContentScale()
+	w.damage = w.damage.Union(Rect{x / s, y / s, (x + width) / s, (y + height) / s})
+	w.paint()
+}
+
+// ShowDialog shows modal dialog over the content, it receives user input until closed
+func (w *Window) ShowDialog(dlg IDialog) {
+	dlg.SetWindow(w.Self.(IWindow))
+	w.dialogs = append(w.dialogs, dlg)
+	w.dlgFocus = append(w.dlgFocus, w.focus)
+	width, height := w.Size()
+	s := w.ContentScale()
+	dlg.Center(width/s, height/s)
+	// i.e. the input line
+	w.FocusNext(false)
+	// mouse is taken by the dialog
+	w.ReleaseCapture()
+	w.Invalidate(Rect{})
+}
+
+// CloseDialog removes dialog shown by ShowDialog
+func (w *Window) CloseDialog(dlg IDialog) {
+	for i, x := range w.dialogs {
+		if x == dlg {
+			focus := w.dlgFocus[i]
+			restore := w.focus != nil && contains(dlg, w.focus)
+			w.dialogs = append(w.dialogs[:i], w.dialogs[i+1:]...)
+			w.dlgFocus = append(w.dlgFocus[:i], w.dlgFocus[i+1:]...)
+			// focus, capture and hover inside the dialog are dropped, then hover goes to what is under mouse
+			w.forgetElem(dlg)
+			w.ReleaseCapture()
+			if restore {
+				w.SetFocusOwner(focus)
+			}
+			dlg.SetWindow(nil)
+			w.Invalidate(Rect{})
+			return
+		}
+	}
+}
+
+// Dialog returns the top most modal dialog, nil if none
+func (w *Window) Dialog() IDialog {
+	if len(w.dialogs) == 0 {
+		return nil
+	}
+	return w.dialogs[len(w.dialogs)-1]
 }
 
 // ObjID returns the object id
@@ -181,55 +743,47 @@ func (w *Window) SetLayout(wl *WndLayout) error {
 		return err
 	}
 	w.layout = wl
+	w.layoutChanged()
 	return nil
 }
 
-// State to string
+// State to string, include position, size and layout
 func (w *Window) State() ([]byte, error) {
-	return w.Layout().State()
+	var st wndState
+	st.X, st.Y = w.Position()
+	st.Width, st.Height = w.Size()
+	if w.layout != nil {
+		b, err := w.Layout().State()
+		if err != nil {
+			return nil, err
+		}
+		st.Layout = b
+	}
+	return json.MarshalIndent(&st, "", "  ")
 }
 
-// SetState from string
+// SetState from string, data without "width" and "height" is treated as layout only
 func (w *Window) SetState(data []byte) error {
+	var st wndState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	if st.Width <= 0 || st.Height <= 0 {
+		st.Layout = data
+	} else {
+		w.Resize(st.Width, st.Height)
+		// screen may be changed since saved
+		sw, sh := winl.ScreenSize()
+		if st.X < float32(sw) && st.Y < float32(sh) && st.X+st.Width > 0 && st.Y+st.Height > 0 {
+			w.Move(st.X, st.Y)
+		}
+		if st.Layout == nil {
+			return nil
+		}
+	}
 	wl := new(WndLayout)
-	if err := wl.SetState(data); err != nil {
+	if err := wl.SetState(st.Layout); err != nil {
 		return err
 	}
 	return w.SetLayout(wl)
 }
-
-// Render the scene
-func (w *Window) Render() {
-	glman.StackMatM.Push()
-	glman.StackMatM.Load(geom.Mat4Ident())
-	glman.StackMatV.Push()
-	glman.StackMatV.Load(w.matView)
-	glman.StackMatP.Push()
-	glman.StackMatP.Load(w.matProj)
-
-	defer func() {
-		glman.StackMatM.Pop()
-		glman.StackMatV.Pop()
-		glman.StackMatP.Pop()
-	}()
-
-	//dbg.Logln("func (w *Window) Render()")
-	w.MakeCurrent()
-	//gl.ClearColor(0.8, 0.8, 0.9, 1.0)
-	gl.ClearColor(0, 0, 0, 0)
-	gl.Clear(gl.COLOR_BUFFER_BIT)
-
-	if w.layout != nil {
-		w.layout.Render(func(pn IPane) bool { return pn.Is3D() })
-		w.layout.Render(func(pn IPane) bool { return !pn.Is3D() })
-	}
-
-	glman.DynDrawRect(Rect{10, 10, 300, 300}, Color{0, 0, 1, 0.5}, 3)
-	glman.DynDrawText("ASDF", Rect{10, 60, 300, 300}, glman.LoadFont("WQY-ZenHei", 20), Color{0, 0, 1, 1}, 0)
-
-	w.Present()
-
-	// TODO: move to proper location
-	runtime.GC()
-	glman.Routine()
-}
@@ -0,0 +1,425 @@
+//go:build headless
+// +build headless
+
+package gui
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"tetra/internal/gl"
+	"tetra/internal/winl"
+	"tetra/lib/glman"
+)
+
+// TestMain runs tests in background, while event loop is on main thread.
+// test must use winl.Call to access windows, and must not call t.Fatal in it.
+func TestMain(m *testing.M) {
+	// shaders and fonts are in testdata of top directory
+	if err := os.Chdir("../.."); err != nil {
+		panic(err)
+	}
+	code := 0
+	winl.Run(func() error {
+		// event loop exits without window
+		keep := NewWindow()
+		keep.Create(1, 1)
+		go func() {
+			code = m.Run()
+			winl.Post(func() { winl.Exit(nil) })
+		}()
+		return nil
+	}, nil)
+	os.Exit(code)
+}
+
+func TestWindowRender(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		w.SetHints(HintResizable)
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		w.Show()
+		if width, height := w.Size(); width != 320 || height != 240 {
+			t.Errorf("Size() = %g, %g, want 320, 240", width, height)
+		}
+
+		w.Render()
+		// the blue frame drawn at Rect{10, 10, 300, 300}, Y-UP in OpenGL
+		var px [4]uint8
+		gl.ReadPixels(11, 240-11, 1, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(&px[0]))
+		if px[2] == 0 {
+			t.Errorf("pixel at (11, 11) = %v, want blue", px)
+		}
+
+		w.Resize(400, 300)
+		if width, height := w.Size(); width != 400 || height != 300 {
+			t.Errorf("Size() after Resize = %g, %g, want 400, 300", width, height)
+		}
+	})
+}
+
+func TestRecordPlay(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		w.SetHints(HintResizable)
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		w.Show()
+
+		r := winl.NewRecorder(&w.Window)
+		w.InjectMouseMove(10, 20)
+		w.InjectMousePress(winl.MouseLeft, 10, 20)
+		w.InjectMouseRelease(winl.MouseLeft, 30, 20)
+		w.InjectKey(winl.KeyA, winl.ModControl, true)
+		w.InjectResize(200, 100)
+		w.InjectMouseEnter(5, 5)
+		w.InjectFocus(false)
+		w.InjectMouseDelta(3, -4)
+		w.InjectDrop([]string{"/a", "/b"}, 15, 25)
+		w.InjectMouseLeave(0, 0)
+		w.InjectFocus(true)
+		r.Stop()
+		w.InjectMouseMove(0, 0) // not recorded
+		w.InjectFocus(false)
+
+		data, err := json.Marshal(r.Recording())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var rec winl.Recording
+		if err := json.Unmarshal(data, &rec); err != nil {
+			t.Error(err)
+			return
+		}
+		if len(rec.Events) != 11 {
+			t.Errorf("len(Events) = %d, want 11", len(rec.Events))
+			return
+		}
+
+		r2 := winl.NewRecorder(&w.Window)
+		rec.Play(&w.Window)
+		r2.Stop()
+		got := r2.Recording().Events
+		if !w.HasFocus() {
+			t.Error("HasFocus() = false after replay")
+		}
+		if len(got) != 12 || got[0].Kind != winl.EventResize || got[0].X != 320 {
+			t.Errorf("Play() starts with %+v, want resize to 320x240", got[0])
+			return
+		}
+		for i, ev := range rec.Events {
+			ev.T, got[i+1].T = 0, 0
+			if ev != got[i+1] {
+				t.Errorf("event %d = %+v, want %+v", i, got[i+1], ev)
+			}
+		}
+		if width, height := w.Size(); width != 200 || height != 100 {
+			t.Errorf("Size() = %g, %g, want 200, 100", width, height)
+		}
+	})
+}
+
+func TestSharedContexts(t *testing.T) {
+	winl.Call(func() {
+		w1, w2 := NewWindow(), NewWindow()
+		if err := w1.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := w2.Create(320, 240); err != nil {
+			t.Error(err)
+			w1.Destroy()
+			return
+		}
+		defer w2.Destroy()
+		c1, c2 := w1.Context(), w2.Context()
+		if c1 == 0 || c2 == 0 || c1 == c2 {
+			t.Errorf("Context() = %x, %x, want distinct contexts", c1, c2)
+		}
+
+		// fonts and textures are shared, so both windows draw the same glyphs
+		w1.Render()
+		w2.Render()
+
+		setup := func() {}
+		w1.MakeCurrent()
+		a1 := glman.LoadVertexArray("test", setup)
+		if b1 := glman.LoadVertexArray("test", setup); b1.ID() != a1.ID() {
+			t.Errorf("LoadVertexArray() = %v, want cached %v", b1, a1)
+		}
+		w2.MakeCurrent()
+		if a2 := glman.LoadVertexArray("test", setup); a2 == nil || a2.Name() != "test" || winl.CurrentContext() != c2 {
+			t.Errorf("LoadVertexArray() in second context = %v", a2)
+		}
+
+		w1.Destroy()
+		if a1.ID() != 0 {
+			t.Errorf("vertex array of destroyed context = %v, want forgotten", a1)
+		}
+	})
+}
+
+func TestSplitterDrag(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		wl := &WndLayout{Sp: 0.5, L: &WndLayout{Pane: NewPane()}, R: &WndLayout{Pane: NewPane()}}
+		if err := w.SetLayout(wl); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+
+		s := w.ContentScale()
+		ss := w.szSplit
+		if sp := wl.SplitterAt(160, 100); sp != wl {
+			t.Errorf("SplitterAt(160, 100) = %v, want root", sp)
+		}
+		if sp := wl.SplitterAt(100, 100); sp != nil {
+			t.Errorf("SplitterAt(100, 100) = %v, want nil", sp)
+		}
+
+		w.InjectMouseMove(160*s, 100*s)
+		w.InjectMousePress(winl.MouseLeft, 160*s, 100*s)
+		w.InjectMouseMove(100*s, 100*s)
+		if got := wl.L.Pane.Bounds().Width(); got != 100-ss*0.5 {
+			t.Errorf("width of left pane = %g, want %g", got, 100-ss*0.5)
+		}
+		// left pane can't be smaller than its MinSize
+		w.InjectMouseMove(5*s, 100*s)
+		w.InjectMouseRelease(winl.MouseLeft, 5*s, 100*s)
+		if got := wl.L.Pane.Bounds().Width(); got != minPaneSize {
+			t.Errorf("width of left pane = %g, want %g", got, float32(minPaneSize))
+		}
+
+		data, err := w.State()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var st struct {
+			Layout WndLayout `json:"layout"`
+		}
+		if err := json.Unmarshal(data, &st); err != nil {
+			t.Error(err)
+			return
+		}
+		if st.Layout.Sp != wl.Sp || wl.Sp >= 0.5 {
+			t.Errorf("saved split = %g, want %g", st.Layout.Sp, wl.Sp)
+		}
+	})
+}
+
+func TestPaneEditing(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+		a := NewPane()
+		if err := w.SetLayout(&WndLayout{Pane: a}); err != nil {
+			t.Error(err)
+			return
+		}
+		w.InjectResize(320, 240)
+
+		if err := w.ClosePane(a); err != ErrBadParams {
+			t.Errorf("ClosePane of the last pane = %v, want %v", err, ErrBadParams)
+		}
+		b, err := w.SplitPane(a, false, "gui.TestPane")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if b.Class() != "gui.TestPane" || b.Window() == nil {
+			t.Errorf("new pane is %s, window %v", b.Class(), b.Window())
+		}
+		if ra, rb := a.Bounds(), b.Bounds(); ra[2] > rb[0] || ra[3] != rb[3] {
+			t.Errorf("bounds after split = %v, %v, want side by side", ra, rb)
+		}
+		// button of TestPane is centered by its layout
+		btn := b.Children()[0].Bounds()
+		rb := b.Bounds()
+		if cx := (btn[0] + btn[2]) * 0.5; cx < rb.Width()*0.5-1 || cx > rb.Width()*0.5+1 {
+			t.Errorf("button at %v, want centered in %v", btn, rb)
+		}
+
+		if err := w.SwapPanes(a, b); err != nil {
+			t.Error(err)
+		}
+		if w.layout.L.Pane != b || w.layout.R.Pane != a {
+			t.Errorf("panes are not swapped")
+		}
+
+		// dock a under b
+		if err := w.DockPane(a, b, DockBottom); err != nil {
+			t.Error(err)
+		}
+		if ra, rb := a.Bounds(), b.Bounds(); rb[3] > ra[1] || ra[0] != rb[0] {
+			t.Errorf("bounds after dock = %v, %v, want a under b", ra, rb)
+		}
+
+		data, err := w.State()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var st struct {
+			Layout WndLayout `json:"layout"`
+		}
+		if err := json.Unmarshal(data, &st); err != nil {
+			t.Error(err)
+			return
+		}
+		if st.Layout.L == nil || st.Layout.R == nil || st.Layout.L.Class != "gui.TestPane" || st.Layout.R.Class != "gui.Pane" {
+			t.Errorf("saved layout = %s", data)
+		}
+
+		if err := w.ClosePane(b); err != nil {
+			t.Error(err)
+		}
+		if w.layout.Pane != a || a.Bounds() != (Rect{0, 0, 320 / w.ContentScale(), 240 / w.ContentScale()}) {
+			t.Errorf("a should take the window after closing b, bounds %v", a.Bounds())
+		}
+		if err := w.ClosePane(b); err != ErrNotFound {
+			t.Errorf("ClosePane of removed pane = %v, want %v", err, ErrNotFound)
+		}
+	})
+}
+
+func TestWindowState(t *testing.T) {
+	winl.Call(func() {
+		w := NewWindow()
+		w.SetHints(HintResizable)
+		if err := w.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		defer w.Destroy()
+
+		// geometry is restored even without layout
+		w.Resize(400, 300)
+		data, err := w.State()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		w.Resize(320, 240)
+		if err := w.SetState(data); err != nil {
+			t.Errorf("SetState(%s) = %v", data, err)
+		}
+		if width, height := w.Size(); width != 400 || height != 300 {
+			t.Errorf("Size() after SetState = %g, %g, want 400, 300", width, height)
+		}
+		if w.layout != nil {
+			t.Errorf("layout after SetState = %+v, want nil", w.layout)
+		}
+
+		// layout only data of old versions
+		if err := w.SetState([]byte(`{"class": "gui.TestPane"}`)); err != nil {
+			t.Error(err)
+		}
+		if w.layout == nil || w.layout.Class != "gui.TestPane" {
+			t.Errorf("layout after SetState = %+v, want gui.TestPane", w.layout)
+		}
+		if width, height := w.Size(); width != 400 || height != 300 {
+			t.Errorf("Size() after SetState of layout = %g, %g, want 400, 300", width, height)
+		}
+	})
+}
+
+// countElem counts how many times it's rendered
+type countElem struct {
+	Elem
+	renders int
+}
+
+func (el *countElem) Render() {
+	el.renders++
+}
+
+func TestInvalidate(t *testing.T) {
+	var w *Window
+	var left, right *countElem
+	winl.Call(func() {
+		nw := NewWindow()
+		if err := nw.Create(320, 240); err != nil {
+			t.Error(err)
+			return
+		}
+		w = nw
+		w.Show()
+		wl := &WndLayout{Sp: 0.5, L: &WndLayout{Pane: NewPane()}, R: &WndLayout{Pane: NewPane()}}
+		if err := w.SetLayout(wl); err != nil {
+			t.Error(err)
+			w.Destroy()
+			w = nil
+			return
+		}
+		w.InjectResize(320, 240)
+		left, right = &countElem{}, &countElem{}
+		left.Self, right.Self = left, right
+		left.SetBounds(Rect{20, 20, 60, 40})
+		right.SetBounds(Rect{20, 20, 60, 40})
+		wl.L.Pane.Insert(-1, left)
+		wl.R.Pane.Insert(-1, right)
+	})
+	if w == nil {
+		return
+	}
+	// the whole window is exposed after layout is set
+	winl.Call(func() {
+		if got := w.Damage(); !got.IsEmpty() {
+			t.Errorf("Damage() after expose = %v, want empty", got)
+		}
+		left.renders, right.renders = 0, 0
+
+		// in coordinates of the element, clipped by its bounds
+		left.Invalidate(Rect{30, 0, 50, 10})
+		if got, want := w.Damage(), (Rect{50, 20, 60, 30}); got != want {
+			t.Errorf("Damage() = %v, want %v", got, want)
+		}
+		left.Invalidate(Rect{})
+		if got, want := w.Damage(), (Rect{20, 20, 60, 40}); got != want {
+			t.Errorf("Damage() = %v, want %v", got, want)
+		}
+	})
+	// expose is dispatched by event loop
+	winl.Call(func() {
+		defer w.Destroy()
+		if got := w.Damage(); !got.IsEmpty() {
+			t.Errorf("Damage() after expose = %v, want empty", got)
+		}
+		if left.renders != 1 || right.renders != 0 {
+			t.Errorf("renders = %d, %d, want 1, 0, the right pane is not damaged", left.renders, right.renders)
+		}
+
+		// the blue frame out of damaged area is kept
+		s := w.ContentScale()
+		w.Invalidate(Rect{200, 150, 220, 170})
+		w.paint()
+		var px [4]uint8
+		gl.ReadPixels(int32(11*s), int32(240-11*s), 1, 1, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(&px[0]))
+		if px[2] == 0 {
+			t.Errorf("pixel at (11, 11) = %v, want blue", px)
+		}
+		if left.renders != 1 || right.renders != 1 {
+			t.Errorf("renders = %d, %d, want 1, 1", left.renders, right.renders)
+		}
+	})
+}
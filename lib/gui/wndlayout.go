@@ -27,18 +27,17 @@ func (wl *WndLayout) IsLeaf() bool {
 
 func treeFixLoaded(wl *WndLayout, w IWindow) error {
 	if wl.IsLeaf() {
-		if wl.Pane != nil {
-			return nil
-		}
-		var ok bool
-		if ctor := factory.Get(wl.Class); ctor == nil {
-			dbg.Logf("factory method for \"%s\" not found, fallback to gui.Pane", wl.Class)
-			wl.Pane = NewPane()
-		} else if wl.Pane, ok = ctor().(IPane); !ok {
-			dbg.Logf("returns of factory method of \"%s\" is not a Pane, fallback to gui.Pane", wl.Class)
-			wl.Pane = NewPane()
+		if wl.Pane == nil {
+			var ok bool
+			if ctor := factory.Get(wl.Class); ctor == nil {
+				dbg.Logf("factory method for \"%s\" not found, fallback to gui.Pane", wl.Class)
+				wl.Pane = NewPane()
+			} else if wl.Pane, ok = ctor().(IPane); !ok {
+				dbg.Logf("returns of factory method of \"%s\" is not a Pane, fallback to gui.Pane", wl.Class)
+				wl.Pane = NewPane()
+			}
+			wl.Pane.SetState([]byte(wl.Param))
 		}
-		wl.Pane.SetState([]byte(wl.Param))
 		wl.Pane.SetWindow(w)
 	}
 
@@ -115,6 +114,199 @@ func (wl *WndLayout) CalcLayout(ss float32) {
 	wl.R.CalcLayout(ss)
 }
 
+// find returns the first node matches f in tree of wl, and its parent
+func (wl *WndLayout) find(f func(node *WndLayout) bool) (node, parent *WndLayout) {
+	if wl == nil {
+		return nil, nil
+	}
+	if f(wl) {
+		return wl, nil
+	}
+	for _, c := range [...]*WndLayout{wl.L, wl.R} {
+		if node, parent = c.find(f); node != nil {
+			if parent == nil {
+				parent = wl
+			}
+			return
+		}
+	}
+	return nil, nil
+}
+
+// Find returns the leaf of pane pn and its parent, nil if not found
+func (wl *WndLayout) Find(pn IPane) (node, parent *WndLayout) {
+	return wl.find(func(x *WndLayout) bool {
+		return x.IsLeaf() && x.Pane == pn
+	})
+}
+
+// split the leaf wl in halves, leaf is put at left (top) if first, otherwise at right (bottom)
+func (wl *WndLayout) split(vert, first bool, leaf *WndLayout) {
+	old := &WndLayout{Pane: wl.Pane, Class: wl.Class, Param: wl.Param}
+	*wl = WndLayout{rc: wl.rc, Vert: vert, Sp: 0.5, L: old, R: leaf}
+	if first {
+		wl.L, wl.R = leaf, old
+	}
+}
+
+// remove node from tree of wl, its sibling takes place of their parent.
+// nodes are moved, so pointers to nodes other than wl are invalid after that.
+func (wl *WndLayout) remove(node *WndLayout) bool {
+	_, parent := wl.find(func(x *WndLayout) bool { return x == node })
+	if parent == nil {
+		return false
+	}
+	sib := parent.L
+	if sib == node {
+		sib = parent.R
+	}
+	rc := parent.rc
+	if sib == nil {
+		// parent becomes empty, remove it too
+		*parent = WndLayout{rc: rc}
+		wl.remove(parent)
+		return true
+	}
+	*parent = *sib
+	parent.rc = rc
+	return true
+}
+
+// PaneAt returns pane of the leaf contains point x, y, or nil if it is on splitter
+func (wl *WndLayout) PaneAt(x, y float32) IPane {
+	if wl == nil || !wl.rc.Contains(x, y) {
+		return nil
+	}
+	if wl.IsLeaf() {
+		return wl.Pane
+	}
+	if pn := wl.L.PaneAt(x, y); pn != nil {
+		return pn
+	}
+	return wl.R.PaneAt(x, y)
+}
+
+// walk calls f with panes of leaves, from left (top) to right (bottom)
+func (wl *WndLayout) walk(f func(pn IPane)) {
+	if wl == nil {
+		return
+	}
+	if wl.IsLeaf() {
+		if wl.Pane != nil {
+			f(wl.Pane)
+		}
+		return
+	}
+	wl.L.walk(f)
+	wl.R.walk(f)
+}
+
+// isSplit reports whether wl is split into two children, with a splitter between them
+func (wl *WndLayout) isSplit() bool {
+	return wl.L != nil && wl.R != nil
+}
+
+// SplitterRect returns the gap between children in which splitter is dragged, empty if wl is not split
+func (wl *WndLayout) SplitterRect() Rect {
+	if !wl.isSplit() {
+		return Rect{}
+	}
+	if wl.Vert {
+		return Rect{wl.rc[0], wl.L.rc[3], wl.rc[2], wl.R.rc[1]}
+	}
+	return Rect{wl.L.rc[2], wl.rc[1], wl.R.rc[0], wl.rc[3]}
+}
+
+// SplitterAt returns the node whose splitter contains point x, y, or nil
+func (wl *WndLayout) SplitterAt(x, y float32) *WndLayout {
+	if wl == nil || !wl.isSplit() || !wl.rc.Contains(x, y) {
+		return nil
+	}
+	if wl.SplitterRect().Contains(x, y) {
+		return wl
+	}
+	if sp := wl.L.SplitterAt(x, y); sp != nil {
+		return sp
+	}
+	return wl.R.SplitterAt(x, y)
+}
+
+// limits reports min and max size of the subtree along split direction of vert, max is 0 for no limit
+func (wl *WndLayout) limits(vert bool, ss float32) (min, max float32) {
+	switch {
+	case wl == nil:
+		return 0, 0
+	case wl.L != nil && wl.R == nil:
+		return wl.L.limits(vert, ss)
+	case wl.L == nil && wl.R != nil:
+		return wl.R.limits(vert, ss)
+	case wl.IsLeaf():
+		if wl.Pane == nil {
+			return 0, 0
+		}
+		minW, minH := wl.Pane.MinSize()
+		maxW, maxH := wl.Pane.MaxSize()
+		if vert {
+			return minH, maxH
+		}
+		return minW, maxW
+	}
+	lmin, lmax := wl.L.limits(vert, ss)
+	rmin, rmax := wl.R.limits(vert, ss)
+	if wl.Vert == vert {
+		// children are side by side along the direction
+		min = lmin + ss + rmin
+		if lmax > 0 && rmax > 0 {
+			max = lmax + ss + rmax
+		}
+		return
+	}
+	min = lmin
+	if rmin > min {
+		min = rmin
+	}
+	max = lmax
+	if max == 0 || (rmax > 0 && rmax < max) {
+		max = rmax
+	}
+	return
+}
+
+// DragSplitter moves splitter of wl to pos, x or y in UI units by split direction,
+// Sp is updated within min and max size of children, and the subtree is relayout.
+func (wl *WndLayout) DragSplitter(pos, ss float32) {
+	if !wl.isSplit() {
+		return
+	}
+	x0, x1 := wl.rc[0], wl.rc[2]
+	if wl.Vert {
+		x0, x1 = wl.rc[1], wl.rc[3]
+	}
+	if x1-x0 <= 0 {
+		return
+	}
+	lmin, lmax := wl.L.limits(wl.Vert, ss)
+	rmin, rmax := wl.R.limits(wl.Vert, ss)
+	lo, hi := x0+ss*0.5+lmin, x1-ss*0.5-rmin
+	if lmax > 0 && x0+ss*0.5+lmax < hi {
+		hi = x0 + ss*0.5 + lmax
+	}
+	if rmax > 0 && x1-ss*0.5-rmax > lo {
+		lo = x1 - ss*0.5 - rmax
+	}
+	switch {
+	case lo > hi:
+		// too small to satisfy both, share the shortage
+		pos = (lo + hi) * 0.5
+	case pos < lo:
+		pos = lo
+	case pos > hi:
+		pos = hi
+	}
+	wl.Sp = (pos - x0) / (x1 - x0)
+	wl.CalcLayout(ss)
+}
+
 // Render the layout tree
 func (wl *WndLayout) Render(filter func(IPane) bool) {
 	if wl.L != nil {
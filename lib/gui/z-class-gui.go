@@ -3,9 +3,11 @@ package gui
 // Auto generated file, do NOT edit!
 
 import (
+	"image"
 	"tetra/internal/winl"
 	"tetra/lib/factory"
 	"tetra/lib/glman"
+	"tetra/lib/passwd"
 )
 
 var factoryRegisted bool
@@ -20,21 +22,51 @@ func FactoryRegister() {
 	factory.Register(`gui.Button`, func() interface{} {
 		return NewButton()
 	})
+	factory.Register(`gui.CheckBox`, func() interface{} {
+		return NewCheckBox()
+	})
+	factory.Register(`gui.Dialog`, func() interface{} {
+		return NewDialog()
+	})
 	factory.Register(`gui.Elem`, func() interface{} {
 		return NewElem()
 	})
+	factory.Register(`gui.Icon`, func() interface{} {
+		return NewIcon()
+	})
+	factory.Register(`gui.Image`, func() interface{} {
+		return NewImage()
+	})
+	factory.Register(`gui.Label`, func() interface{} {
+		return NewLabel()
+	})
+	factory.Register(`gui.LineEdit`, func() interface{} {
+		return NewLineEdit()
+	})
+	factory.Register(`gui.ListBox`, func() interface{} {
+		return NewListBox()
+	})
 	factory.Register(`gui.Pane`, func() interface{} {
 		return NewPane()
 	})
 	factory.Register(`gui.Pane3D`, func() interface{} {
 		return NewPane3D()
 	})
+	factory.Register(`gui.RadioButton`, func() interface{} {
+		return NewRadioButton()
+	})
+	factory.Register(`gui.ScrollView`, func() interface{} {
+		return NewScrollView()
+	})
 	factory.Register(`gui.TestPane`, func() interface{} {
 		return NewTestPane()
 	})
 	factory.Register(`gui.TestPane3D`, func() interface{} {
 		return NewTestPane3D()
 	})
+	factory.Register(`gui.TextEdit`, func() interface{} {
+		return NewTextEdit()
+	})
 	factory.Register(`gui.Widget`, func() interface{} {
 		return NewWidget()
 	})
@@ -59,14 +91,94 @@ func (p *Button) Class() string {
 // IButton is interface of class Button
 type IButton interface {
 	IWidget
-	// Font returns current font
+	// Checkable reports whether the button toggles checked state by click
+	Checkable() bool
+	// Checked reports whether the button is checked
+	Checked() bool
+	// Click the button as by user, does nothing if it's disabled
+	Click()
+	// Font returns current font, the font of skin if not set
 	Font() glman.Font
-	// SetFont set the font
+	// Icon reports name of icon shown before text, "" for none
+	Icon() string
+	// OnClick adds f to handlers called when the button is clicked, after checked state is toggled
+	OnClick(f func())
+	// SetCheckable set whether the button toggles checked state by click
+	SetCheckable(b bool)
+	// SetChecked set checked state, without calling click handlers
+	SetChecked(b bool)
+	// SetFont set the font, "" for the font of skin
 	SetFont(f glman.Font)
+	// SetIcon set name of glman icon shown before text, "" for none
+	SetIcon(name string)
 	// SetText set the text label on the button
 	SetText(s string)
 	// Text label on the button
 	Text() string
+	// Toggle changes checked state as it's clicked
+	Toggle()
+}
+
+// NewCheckBox create and init new CheckBox object.
+func NewCheckBox() *CheckBox {
+	p := new(CheckBox)
+	p.Button.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *CheckBox) Class() string {
+	return (`gui.CheckBox`)
+}
+
+// ICheckBox is interface of class CheckBox
+type ICheckBox interface {
+	IButton
+}
+
+// NewDialog create and init new Dialog object.
+func NewDialog() *Dialog {
+	p := new(Dialog)
+	p.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *Dialog) Class() string {
+	return (`gui.Dialog`)
+}
+
+// IDialog is interface of class Dialog
+type IDialog interface {
+	IWidget
+	// Center calc layout of dialog, in the center of window of size width and height
+	Center(width, height float32)
+	// Close the dialog as if button btn is pressed
+	Close(btn int)
+	// Input returns text of the input line
+	Input() string
+	// OnDone set the function called with index of button when dialog is closed
+	OnDone(f func(btn int))
+	// OnEnter set the function called when list entry is double-clicked or Enter is pressed, returns true if handled
+	OnEnter(f func(i int) bool)
+	// OnSelect set the function called when selection of list is changed
+	OnSelect(f func(i int))
+	// Selected returns index of selected entry of list, -1 for none
+	Selected() int
+	// SetButtons set buttons, def is activated by Enter and esc by Escape, -1 for none
+	SetButtons(def, esc int, buttons []string)
+	// SetInput show input line with initial text, it takes focus when the dialog is shown
+	SetInput(text string)
+	// SetList show a list of entries, the first one is selected
+	SetList(entries []string)
+	// SetMessage set message of the dialog, lines are separated by '\n'
+	SetMessage(s string)
+	// SetTitle set title of the dialog
+	SetTitle(s string)
+	// Title of the dialog
+	Title() string
 }
 
 // NewElem create and init new Elem object.
@@ -86,34 +198,244 @@ func (p *Elem) Class() string {
 type IElem interface {
 	// Bounds reports bounds rect of the element
 	Bounds() Rect
-	// BoundsGLCoord reports bounds rect of the element, in OpenGL (Y-UP) coordinate.
+	// BoundsGLCoord reports bounds rect of the element, in OpenGL (Y-UP) pixel coordinate.
 	BoundsGLCoord() Rect
 	// Children returns child elements
 	Children() []IElem
 	// Class name for factory
 	Class() string
+	// HitTest returns the deepest element contains x, y in coordinates of parent, nil if it's out of bounds
+	HitTest(x, y float32) IElem
 	// Index of x
 	Index(x IElem) int
 	// Init a new object
 	Init()
 	// Insert x at index i, if i < 0 then append to the end
 	Insert(i int, x IElem)
+	// Invalidate marks rc in coordinates of the element to be repainted, empty rc for the whole element
+	Invalidate(rc Rect)
+	// Layout returns the layout that arranges children, nil if they are placed by SetBounds
+	Layout() Layout
+	// Margin reports space around the element kept by layout of parent
+	Margin() Margins
+	// OnKeyDown event handler, key is delivered to focus owner, returns false to bubble it to parent
+	OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool
+	// OnKeyUp event handler, key is delivered to focus owner, returns false to bubble it to parent
+	OnKeyUp(key winl.Key, mods winl.Mod) bool
+	// OnMouseDelta event handler, dx, dy are mouse motion in pixels in relative mouse mode, returns false to bubble it to parent
+	OnMouseDelta(dx, dy float32) bool
+	// OnMouseDown event handler, x, y are local coordinates, returns false to bubble it to parent
+	OnMouseDown(btn int, x, y float32) bool
+	// OnMouseEnter event handler, called when mouse enters the element or one of its children
+	OnMouseEnter()
+	// OnMouseLeave event handler, called when mouse leaves the element and all of its children
+	OnMouseLeave()
+	// OnMouseMove event handler, x, y are local coordinates, returns false to bubble it to parent
+	OnMouseMove(x, y float32) bool
+	// OnMouseUp event handler, x, y are local coordinates, returns false to bubble it to parent
+	OnMouseUp(btn int, x, y float32) bool
+	// OnMouseWheel event handler, dz is in notches, positive for up or left, returns false to bubble it to parent
+	OnMouseWheel(vert bool, dz float32) bool
+	// OnTextInput event handler, text is delivered to focus owner, returns false to bubble it to parent
+	OnTextInput(text string) bool
+	// Padding reports space between bounds and children arranged by layout
+	Padding() Margins
 	// Parent returns parent element
 	Parent() IElem
-	// Remove child at index i
+	// Relayout arranges children by layout, it's called when children or size are changed
+	Relayout()
+	// Remove child at index i, it's detached from the window
 	Remove(i int) IElem
-	// RemoveAll remove all children
+	// RemoveAll remove all children, they are detached from the window
 	RemoveAll()
-	// Render the element
+	// Render the element, children are rendered in its coordinates and clipped by its bounds, those out of view of ScrollView are skipped
 	Render()
-	// SetBounds set the bounds rect of the element
+	// SetBounds set the bounds rect of the element, children are arranged by layout if size is changed
 	SetBounds(rect Rect)
+	// SetLayout set the layout that arranges children, nil to place them by SetBounds
+	SetLayout(l Layout)
+	// SetMargin set space around the element kept by layout of parent
+	SetMargin(m Margins)
+	// SetPadding set space between bounds and children arranged by layout
+	SetPadding(p Margins)
 	// SetParent set parent element
 	SetParent(p IElem)
-	// SetWindow set the owner window
+	// SetSizeHint overrides size range reported by SizeHint
+	SetSizeHint(h SizeHint)
+	// SetWindow set the owner window of the element and its children, nil when they are detached
 	SetWindow(w IWindow)
+	// SizeHint reports size range for layout of parent, from SetSizeHint, layout, or current size in turn
+	SizeHint() SizeHint
+	// ToLocal converts x, y in window coordinates to coordinates of the element, origin is top left of its bounds
+	ToLocal(x, y float32) (float32, float32)
 	// Window reports the owner window
 	Window() IWindow
+	// WindowBounds reports bounds rect of the element, in window coordinates
+	WindowBounds() Rect
+}
+
+// NewIcon create and init new Icon object.
+func NewIcon() *Icon {
+	p := new(Icon)
+	p.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *Icon) Class() string {
+	return (`gui.Icon`)
+}
+
+// IIcon is interface of class Icon
+type IIcon interface {
+	IWidget
+	// Color reports color of the icon, the text color of skin if not set
+	Color() Color
+	// IconSize reports preferred size of the icon
+	IconSize() float32
+	// Name reports name of the icon
+	Name() string
+	// SetColor set color of the icon
+	SetColor(c Color)
+	// SetIconSize set preferred size of the icon, 0 for height of skin font
+	SetIconSize(size float32)
+	// SetName set the icon by name, such as "check", "close", "arrow-down"
+	SetName(name string)
+}
+
+// NewImage create and init new Image object.
+func NewImage() *Image {
+	p := new(Image)
+	p.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *Image) Class() string {
+	return (`gui.Image`)
+}
+
+// IImage is interface of class Image
+type IImage interface {
+	IWidget
+	// Fit reports how the picture is placed in bounds
+	Fit() ImageFit
+	// SetFit set how the picture is placed in bounds
+	SetFit(fit ImageFit)
+	// SetSource set the picture to show, nil for none, texture space of the old one is released
+	SetSource(src image.Image)
+	// SetTint set color multiplies the picture, opaque white shows it as is
+	SetTint(c Color)
+	// Source reports the picture shown
+	Source() image.Image
+	// Tint reports color multiplies the picture
+	Tint() Color
+}
+
+// NewLabel create and init new Label object.
+func NewLabel() *Label {
+	p := new(Label)
+	p.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *Label) Class() string {
+	return (`gui.Label`)
+}
+
+// ILabel is interface of class Label
+type ILabel interface {
+	IWidget
+	// Align reports alignment, options of glman.DynDrawText
+	Align() glman.OptionDrawText
+	// Color reports color of the text, the text color of skin if not set
+	Color() Color
+	// Elide reports whether lines wider than label end with ellipsis
+	Elide() bool
+	// Font reports font of the text, the font of skin if not set
+	Font() glman.Font
+	// SetAlign set alignment by glman.DtCenter, DtRight, DtVCenter and DtBottom, it's top left by default
+	SetAlign(align glman.OptionDrawText)
+	// SetColor set color of the text
+	SetColor(c Color)
+	// SetElide set whether lines wider than label end with ellipsis
+	SetElide(elide bool)
+	// SetFont set font of the text, "" for the font of skin
+	SetFont(f glman.Font)
+	// SetText set the text of label, '\n' breaks lines
+	SetText(s string)
+	// SetWrap set whether lines are wrapped at width of label
+	SetWrap(wrap bool)
+	// Text reports the text of label
+	Text() string
+	// Wrap reports whether lines are wrapped at width of label
+	Wrap() bool
+}
+
+// NewLineEdit create and init new LineEdit object.
+func NewLineEdit() *LineEdit {
+	p := new(LineEdit)
+	p.TextEdit.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *LineEdit) Class() string {
+	return (`gui.LineEdit`)
+}
+
+// ILineEdit is interface of class LineEdit
+type ILineEdit interface {
+	ITextEdit
+	// CheckPasswd reports whether text is the password of encrypted and salt returned by Passwd
+	CheckPasswd(encrypted passwd.Passwd, salt passwd.Salt) bool
+	// OnEnter adds f to handlers called with text when Enter is pressed
+	OnEnter(f func(text string))
+	// Passwd returns hash of text with a new salt by lib/passwd, to store the password instead of text
+	Passwd() (passwd.Passwd, passwd.Salt)
+	// Password reports whether it's in password mode
+	Password() bool
+	// SetPassword set password mode, text is shown as '*' and can't be copied
+	SetPassword(b bool)
+}
+
+// NewListBox create and init new ListBox object.
+func NewListBox() *ListBox {
+	p := new(ListBox)
+	p.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *ListBox) Class() string {
+	return (`gui.ListBox`)
+}
+
+// IListBox is interface of class ListBox
+type IListBox interface {
+	IWidget
+	// Font returns current font, the font of skin if not set
+	Font() glman.Font
+	// Items reports entries of the list
+	Items() []string
+	// OnActivate adds f to handlers called when an entry is double-clicked or Enter is pressed on it
+	OnActivate(f func(i int))
+	// OnSelect adds f to handlers called when selection is changed by user
+	OnSelect(f func(i int))
+	// Selected reports index of selected entry, -1 for none
+	Selected() int
+	// SetFont set the font, "" for the font of skin
+	SetFont(f glman.Font)
+	// SetItems set entries of the list, selection is cleared
+	SetItems(items []string)
+	// SetSelected selects entry i and scrolls to it, -1 for none, without calling select handlers
+	SetSelected(i int)
 }
 
 // NewPane create and init new Pane object.
@@ -132,8 +454,16 @@ func (p *Pane) Class() string {
 // IPane is interface of class Pane
 type IPane interface {
 	IWidget
+	// AcceptDrop reports whether files can be dropped at x, y, in pane coordinates
+	AcceptDrop(x, y float32) bool
 	// Is3D reports whether pane is 3D scene
 	Is3D() bool
+	// MaxSize reports maximum size of pane, 0 for no limit
+	MaxSize() (width, height float32)
+	// MinSize reports minimum size of pane, splitters can't be dragged to make it smaller
+	MinSize() (width, height float32)
+	// OnDrop event handler, paths are local files dropped at x, y, in pane coordinates
+	OnDrop(paths []string, x, y float32)
 	// SetState from string
 	SetState(data []byte) error
 	// State to string
@@ -156,6 +486,8 @@ func (p *Pane3D) Class() string {
 // IPane3D is interface of class Pane3D
 type IPane3D interface {
 	IPane
+	// CaptureMouse keep receiving mouse events when dragging out of window, i.e. orbit the camera
+	CaptureMouse() bool
 	// Frustum set projection to special frustum
 	Frustum(left float32, right float32, bottom float32, top float32, near float32, far float32)
 	// LookAt set view matrix to look at special point
@@ -164,6 +496,56 @@ type IPane3D interface {
 	Ortho(xMin float32, xMax float32, yMin float32, yMax float32, zMin float32, zMax float32)
 	// Perspective set projection to perspective
 	Perspective(fovy float32, aspect float32, near float32, far float32)
+	// ReleaseMouse release the mouse captured by CaptureMouse
+	ReleaseMouse()
+	// SetMouseLook enable relative mouse mode for FPS-style camera, the pane receives OnMouseDelta instead of OnMouseMove
+	SetMouseLook(enable bool) bool
+}
+
+// NewRadioButton create and init new RadioButton object.
+func NewRadioButton() *RadioButton {
+	p := new(RadioButton)
+	p.Button.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *RadioButton) Class() string {
+	return (`gui.RadioButton`)
+}
+
+// IRadioButton is interface of class RadioButton
+type IRadioButton interface {
+	IButton
+}
+
+// NewScrollView create and init new ScrollView object.
+func NewScrollView() *ScrollView {
+	p := new(ScrollView)
+	p.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *ScrollView) Class() string {
+	return (`gui.ScrollView`)
+}
+
+// IScrollView is interface of class ScrollView
+type IScrollView interface {
+	IWidget
+	// Content reports the element scrolled
+	Content() IElem
+	// ScrollPos reports scroll position, the point of content shown at top left of view
+	ScrollPos() Vec2
+	// ScrollTo scrolls the least to show rc of content, top left of rc is shown if it's larger than view
+	ScrollTo(rc Rect)
+	// SetContent set the element scrolled, it's sized by its size hint, and fills the view if it's smaller
+	SetContent(el IElem)
+	// SetScrollPos scrolls to v immediately, it's limited in content
+	SetScrollPos(v Vec2)
 }
 
 // NewTestPane create and init new TestPane object.
@@ -202,6 +584,64 @@ type ITestPane3D interface {
 	IPane3D
 }
 
+// NewTextEdit create and init new TextEdit object.
+func NewTextEdit() *TextEdit {
+	p := new(TextEdit)
+	p.Widget.Elem.Self = p
+	p.Init()
+	return p
+}
+
+// Class name for factory
+func (p *TextEdit) Class() string {
+	return (`gui.TextEdit`)
+}
+
+// ITextEdit is interface of class TextEdit
+type ITextEdit interface {
+	IWidget
+	// CanRedo reports whether there are undone edits to redo
+	CanRedo() bool
+	// CanUndo reports whether there are edits to undo
+	CanUndo() bool
+	// Copy puts selected text to clipboard, masked text can't be copied
+	Copy()
+	// Cut moves selected text to clipboard, masked text can't be cut
+	Cut()
+	// Font reports font of the text, the font of skin if not set
+	Font() glman.Font
+	// OnChange adds f to handlers called after text is changed
+	OnChange(f func(text string))
+	// Paste replaces selection by text in clipboard
+	Paste()
+	// Placeholder reports text shown when it's empty
+	Placeholder() string
+	// Redo the last undone edit
+	Redo()
+	// ReplaceSelection replaces the selection by s as typed by user, returns false if it's rejected by validator
+	ReplaceSelection(s string) bool
+	// SelectAll selects the whole text
+	SelectAll()
+	// SelectedText returns the selected text
+	SelectedText() string
+	// Selection reports start and end of selected text, in runes, they are equal to caret if nothing is selected
+	Selection() (start, end int)
+	// SetFont set font of the text, "" for the font of skin
+	SetFont(f glman.Font)
+	// SetPlaceholder set text shown when it's empty, as a hint of what to input
+	SetPlaceholder(s string)
+	// SetSelection selects runes from start to end, caret is at end
+	SetSelection(start, end int)
+	// SetText replaces the text, caret is moved to the end, undo history is cleared
+	SetText(s string)
+	// SetValidator set f to check text, edits by user making text rejected by f are discarded, nil for no check
+	SetValidator(f func(text string) bool)
+	// Text reports the text
+	Text() string
+	// Undo the last edit
+	Undo()
+}
+
 // NewWidget create and init new Widget object.
 func NewWidget() *Widget {
 	p := new(Widget)
@@ -218,6 +658,24 @@ func (p *Widget) Class() string {
 // IWidget is interface of class Widget
 type IWidget interface {
 	IElem
+	// Enabled reports whether the widget accepts user input
+	Enabled() bool
+	// Focusable reports whether the widget accepts keyboard focus
+	Focusable() bool
+	// HasFocus reports whether the widget is the focus owner of its window
+	HasFocus() bool
+	// OnFocusIn event handler, called when the widget becomes focus owner
+	OnFocusIn()
+	// OnFocusOut event handler, called when the widget loses focus
+	OnFocusOut()
+	// SetEnabled enables or disables user input of the widget, a disabled widget loses focus
+	SetEnabled(b bool)
+	// SetFocus makes the widget focus owner of its window
+	SetFocus()
+	// SetTabIndex set order in Tab navigation, positive ones come first in ascending order, then 0 in tree order, negative is skipped
+	SetTabIndex(i int)
+	// TabIndex reports order of the widget in Tab navigation, see SetTabIndex
+	TabIndex() int
 }
 
 // NewWindow create and init new Window object.
@@ -236,20 +694,64 @@ func (p *Window) Class() string {
 // IWindow is interface of class Window
 type IWindow interface {
 	winl.IWindow
+	// BeginDock starts dragging pn by mouse, drop zones are shown over pane under mouse, until mouse is released or Escape is pressed
+	BeginDock(pn IPane)
+	// Capture returns the element capturing mouse, nil if none
+	Capture() IElem
+	// CloseDialog removes dialog shown by ShowDialog
+	CloseDialog(dlg IDialog)
+	// ClosePane removes pn from layout, its sibling takes the space, the last pane can't be closed
+	ClosePane(pn IPane) error
+	// Damage reports area waiting to be repainted, in UI units
+	Damage() Rect
+	// Dialog returns the top most modal dialog, nil if none
+	Dialog() IDialog
+	// DockPane moves pn to zone of target, DockCenter swaps them
+	DockPane(pn, target IPane, zone DockZone) error
+	// FocusNext moves focus to next widget in Tab order, or previous one if backward, returns false if none is focusable
+	FocusNext(backward bool) bool
+	// FocusOwner returns the widget receiving keyboard events, nil if none
+	FocusOwner() IWidget
+	// HitTest returns the deepest element at x, y in UI units, the top most dialog takes all if any
+	HitTest(x, y float32) IElem
+	// Hover returns the deepest element under mouse, nil if none
+	Hover() IElem
+	// Invalidate marks rc in UI units to be repainted by the next expose event, empty rc for the whole window
+	Invalidate(rc Rect)
 	// Layout return current split layout
 	Layout() *WndLayout
+	// Mods reports modifier keys pressed
+	Mods() winl.Mod
+	// MouseLook returns the element receiving mouse deltas in relative mouse mode, nil if none
+	MouseLook() IElem
 	// ObjID returns the object id
 	ObjID() string
+	// OnKeyDown handles key not handled by elements, Tab and Shift-Tab move focus, NumLock and CapsLock are ignored
+	OnKeyDown(key winl.Key, mods winl.Mod, repeat bool) bool
 	// OnSkin handle the skin change event
 	OnSkin()
-	// Render the scene
+	// ReleaseCapture stops routing mouse events to the element set by SetCapture
+	ReleaseCapture()
+	// Render repaints the whole window
 	Render()
+	// SetCapture routes mouse events to el regardless of mouse position, until ReleaseCapture
+	SetCapture(el IElem)
+	// SetFocusOwner makes wd the focus owner, nil to clear focus, scroll views containing wd scroll to show it
+	SetFocusOwner(wd IWidget)
 	// SetLayout set the split layout
 	SetLayout(wl *WndLayout) error
+	// SetMouseLook enable relative mouse mode, mouse deltas are routed to el, nil to disable it
+	SetMouseLook(el IElem) bool
 	// SetObjID set the object id
 	SetObjID(id string)
-	// SetState from string
+	// SetState from string, data without "width" and "height" is treated as layout only
 	SetState(data []byte) error
-	// State to string
+	// ShowDialog shows modal dialog over the content, it receives user input until closed
+	ShowDialog(dlg IDialog)
+	// SplitPane splits pn in halves, new pane of class is created at right or bottom if vert, returns the new pane
+	SplitPane(pn IPane, vert bool, class string) (IPane, error)
+	// State to string, include position, size and layout
 	State() ([]byte, error)
+	// SwapPanes exchanges places of pane a and b
+	SwapPanes(a, b IPane) error
 }
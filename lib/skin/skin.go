@@ -2,6 +2,7 @@ package skin
 
 import (
 	"tetra/internal/winl"
+	"tetra/lib/glman"
 )
 
 //go:generate go run ../../cmd/classp/classp.go .
@@ -10,9 +11,50 @@ var (
 	cur Interface = NewFallback() // the active skin
 )
 
+// Role of color in skin
+type Role int
+
+// color roles
+const (
+	RoleWindow        Role = iota // background of window and dialog
+	RoleText                      // normal text
+	RoleFrame                     // border of dialog and controls
+	RoleButton                    // background of button
+	RoleHighlight                 // selection and default button
+	RoleHighlightText             // text on highlight
+	RoleShade                     // covers window content under modal dialog
+	RoleButtonHover               // background of button under mouse
+	RoleButtonPressed             // background of pressed or checked button
+	RoleTextDisabled              // text of disabled controls
+	RoleBase                      // background of text entry
+	RoleCount
+)
+
+// State of control to draw, flags are combined
+type State int
+
+// control states
+const (
+	StateHover    State = 1 << iota // mouse is over it
+	StatePressed                    // pressed by mouse or key
+	StateFocus                      // it's the focus owner
+	StateDisabled                   // it doesn't accept user input
+	StateChecked                    // checked box, radio or toggle button
+)
+
 // Interface is skin interface for gui looks
 type Interface interface {
 	SizeSplit() float32
+	SizePadding() float32
+	SizeScrollBar() float32
+	Font() glman.Font
+	Color(role Role) glman.Color
+	TextColor(st State) glman.Color
+	DrawButton(rc glman.Rect, st State)
+	DrawCheckBox(rc glman.Rect, st State)
+	DrawRadio(rc glman.Rect, st State)
+	DrawEdit(rc glman.Rect, st State)
+	DrawScrollBar(track, thumb glman.Rect, st State)
 }
 
 // Get current skin
@@ -34,16 +76,143 @@ func Set(skin Interface) {
 
 // Common data for skin
 type Common struct {
-	Self    Interface
-	SzSplit float32
+	Self      Interface
+	SzSplit   float32
+	SzPadding float32
+	SzScroll  float32
+	FontName  string
+	FontSize  int
+	Colors    [RoleCount]glman.Color
 }
 
 // Init the object
 func (c *Common) Init() {
 	c.SzSplit = 6
+	c.SzPadding = 8
+	c.SzScroll = 12
+	c.FontName = "WQY-ZenHei"
+	c.FontSize = 16
+	c.Colors = [RoleCount]glman.Color{
+		RoleWindow:        {0.93, 0.93, 0.93, 1},
+		RoleText:          {0.1, 0.1, 0.1, 1},
+		RoleFrame:         {0.5, 0.5, 0.5, 1},
+		RoleButton:        {0.85, 0.85, 0.85, 1},
+		RoleHighlight:     {0.2, 0.45, 0.8, 1},
+		RoleHighlightText: {1, 1, 1, 1},
+		RoleShade:         {0, 0, 0, 0.4},
+		RoleButtonHover:   {0.9, 0.9, 0.9, 1},
+		RoleButtonPressed: {0.7, 0.7, 0.7, 1},
+		RoleTextDisabled:  {0.6, 0.6, 0.6, 1},
+		RoleBase:          {1, 1, 1, 1},
+	}
 }
 
 // SizeSplit reports size of splitter
 func (c Common) SizeSplit() float32 {
 	return c.SzSplit
 }
+
+// SizePadding reports space between content and border of controls
+func (c Common) SizePadding() float32 {
+	return c.SzPadding
+}
+
+// SizeScrollBar reports width of scroll bar
+func (c Common) SizeScrollBar() float32 {
+	return c.SzScroll
+}
+
+// Font reports the font of controls
+func (c Common) Font() glman.Font {
+	return glman.LoadFont(c.FontName, c.FontSize)
+}
+
+// Color reports color of the role
+func (c Common) Color(role Role) glman.Color {
+	if role < 0 || role >= RoleCount {
+		return c.Colors[RoleText]
+	}
+	return c.Colors[role]
+}
+
+// TextColor reports color of text of control in state st
+func (c Common) TextColor(st State) glman.Color {
+	if st&StateDisabled != 0 {
+		return c.Color(RoleTextDisabled)
+	}
+	return c.Color(RoleText)
+}
+
+// frameColor reports color and width of frame of control in state st, focused one is highlighted
+func (c Common) frameColor(st State) (glman.Color, float32) {
+	switch {
+	case st&StateDisabled != 0:
+		return c.Color(RoleTextDisabled), 1
+	case st&StateFocus != 0:
+		return c.Color(RoleHighlight), 2
+	}
+	return c.Color(RoleFrame), 1
+}
+
+// background reports background color of control in state st
+func (c Common) background(st State) glman.Color {
+	switch {
+	case st&StateDisabled != 0:
+		return c.Color(RoleButton)
+	case st&(StatePressed|StateChecked) != 0:
+		return c.Color(RoleButtonPressed)
+	case st&StateHover != 0:
+		return c.Color(RoleButtonHover)
+	}
+	return c.Color(RoleButton)
+}
+
+// DrawButton draws background and frame of button in rc
+func (c Common) DrawButton(rc glman.Rect, st State) {
+	glman.DynFillRect(rc, c.background(st))
+	frame, width := c.frameColor(st)
+	glman.DynDrawRect(rc, frame, width)
+}
+
+// DrawCheckBox draws the box of check box in rc, with a check mark if checked
+func (c Common) DrawCheckBox(rc glman.Rect, st State) {
+	glman.DynFillRect(rc, c.background(st&^StateChecked))
+	frame, width := c.frameColor(st)
+	glman.DynDrawRect(rc, frame, width)
+	if st&StateChecked != 0 {
+		glman.DynDrawIcon("check", rc, c.TextColor(st))
+	}
+}
+
+// DrawRadio draws the circle of radio button in rc, with a dot if checked
+func (c Common) DrawRadio(rc glman.Rect, st State) {
+	frame, _ := c.frameColor(st)
+	glman.DynDrawIcon("circle", rc, frame)
+	if st&StateChecked != 0 {
+		glman.DynDrawIcon("dot", rc, c.TextColor(st))
+	}
+}
+
+// DrawEdit draws background and frame of text entry in rc
+func (c Common) DrawEdit(rc glman.Rect, st State) {
+	bg := c.Color(RoleBase)
+	if st&StateDisabled != 0 {
+		bg = c.Color(RoleWindow)
+	}
+	glman.DynFillRect(rc, bg)
+	frame, width := c.frameColor(st)
+	glman.DynDrawRect(rc, frame, width)
+}
+
+// DrawScrollBar draws track and thumb of scroll bar, st is state of the thumb
+func (c Common) DrawScrollBar(track, thumb glman.Rect, st State) {
+	glman.DynFillRect(track, c.Color(RoleWindow))
+	color := c.Color(RoleFrame)
+	switch {
+	case st&StatePressed != 0:
+		color = c.Color(RoleText)
+	case st&StateHover == 0:
+		color[3] *= 0.6
+	}
+	glman.DynFillRect(glman.Rect{thumb[0] + 2, thumb[1] + 2, thumb[2] - 2, thumb[3] - 2}, color)
+}
@@ -0,0 +1,19 @@
+// image from pack texture, tinted by color
+
+uniform sampler2D uniTex0; // RGBA, not premultiplied
+uniform vec4 uniColors[2]; // [0] multiplies the image
+uniform vec4 uniClip2D;  // clip rect [l,t,r,b]
+
+in vec2 vryPos;
+in vec2 vryTC;
+
+// 2D clip on NDC space
+float rectClip(vec2 pt) {
+  // NDC is y-up, our 2D is y-down, so clip[3] is top, clip[1] is bottom
+  return step(uniClip2D[0], pt.x) * step(uniClip2D[3], pt.y) *
+    step(pt.x, uniClip2D[2]) * step(pt.y, uniClip2D[1]);
+}
+
+void main() {
+  gl_FragColor = texture2D(uniTex0, vryTC) * uniColors[0] * rectClip(vryPos);
+}
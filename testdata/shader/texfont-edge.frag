@@ -12,7 +12,7 @@ in vec2 vryTC;
 float rectClip(vec2 pt) {
   // NDC is y-up, our 2D is y-down, so clip[3] is top, clip[1] is bottom
   return step(uniClip2D[0], pt.x) * step(uniClip2D[3], pt.y) *
-    step(pt.x, uniClip2D[2]) * step(pt.x, uniClip2D[1]);
+    step(pt.x, uniClip2D[2]) * step(pt.y, uniClip2D[1]);
 }
 
 // max alpha of neighbour texels